@@ -15,12 +15,16 @@ import (
 type Options struct {
 	// DryRun if true, only logs actions without executing
 	DryRun bool
+	// ServerDryRun if true, dry-run actions are sent to the API server with DryRun=All
+	ServerDryRun bool
 	// ContinueOnError if true, continues executing after errors
 	ContinueOnError bool
 	// AutoConfirm if true, skips confirmation prompts
 	AutoConfirm bool
 	// Verbose if true, enables detailed logging
 	Verbose bool
+	// SkipPreflight if true, executes even when required permissions are missing
+	SkipPreflight bool
 	// Output for log messages (defaults to os.Stdout)
 	Output io.Writer
 }
@@ -29,9 +33,11 @@ type Options struct {
 type Applier struct {
 	client          *kube.Client
 	dryRun          bool
+	serverDryRun    bool
 	continueOnError bool
 	autoConfirm     bool
 	verbose         bool
+	skipPreflight   bool
 	output          io.Writer
 	executor        *Executor
 }
@@ -43,14 +49,19 @@ func NewApplier(client *kube.Client, opts Options) *Applier {
 		output = os.Stdout
 	}
 
+	executor := NewExecutor(client)
+	executor.SetServerDryRun(opts.DryRun && opts.ServerDryRun)
+
 	return &Applier{
 		client:          client,
 		dryRun:          opts.DryRun,
+		serverDryRun:    opts.DryRun && opts.ServerDryRun,
 		continueOnError: opts.ContinueOnError,
 		autoConfirm:     opts.AutoConfirm,
 		verbose:         opts.Verbose,
+		skipPreflight:   opts.SkipPreflight,
 		output:          output,
-		executor:        NewExecutor(client),
+		executor:        executor,
 	}
 }
 
@@ -60,8 +71,16 @@ func (a *Applier) Apply(ctx context.Context, plan *types.Plan) (*types.ApplyResu
 		return nil, fmt.Errorf("plan cannot be nil")
 	}
 
+	// Refuse to start if any action would be forbidden halfway through
+	if !a.dryRun && !a.skipPreflight {
+		if err := a.preflight(ctx, plan); err != nil {
+			return nil, err
+		}
+	}
+
 	result := &types.ApplyResult{
 		StartTime:    time.Now(),
+		DryRunMode:   a.dryRunMode(),
 		TotalActions: len(plan.Actions),
 		Actions:      make([]types.ActionResult, 0, len(plan.Actions)),
 	}
@@ -105,7 +124,20 @@ func (a *Applier) Apply(ctx context.Context, plan *types.Plan) (*types.ApplyResu
 		}
 
 		// Execute or simulate
-		if a.dryRun {
+		if a.serverDryRun && isMutating(action) {
+			a.logServerDryRun(i+1, result.TotalActions, action)
+			err := a.executor.Execute(ctx, action)
+			if err != nil {
+				actionResult.Error = err.Error()
+				actionResult.Success = false
+				result.Failed++
+				a.log("  Result: SERVER WOULD REJECT (%v)\n", err)
+			} else {
+				actionResult.Success = true
+				result.Succeeded++
+				a.log("  Result: SERVER WOULD ACCEPT\n")
+			}
+		} else if a.dryRun {
 			a.logDryRun(i+1, result.TotalActions, action)
 			actionResult.Success = true
 			result.Succeeded++
@@ -158,13 +190,44 @@ func (a *Applier) Apply(ctx context.Context, plan *types.Plan) (*types.ApplyResu
 	return result, nil
 }
 
+// preflight verifies that the current user holds every permission the plan requires
+func (a *Applier) preflight(ctx context.Context, plan *types.Plan) error {
+	checks, err := a.executor.CheckPermissions(ctx, plan)
+	if err != nil {
+		return fmt.Errorf("preflight permission check failed: %w", err)
+	}
+	plan.Permissions = checks
+
+	missing := plan.MissingPermissions()
+	if len(missing) == 0 {
+		return nil
+	}
+
+	a.log("PREFLIGHT: missing %d required permission(s)\n", len(missing))
+	for _, m := range missing {
+		if m.Reason != "" {
+			a.log("  • %s (%s): %s\n", m.String(), m.ActionID, m.Reason)
+			continue
+		}
+		a.log("  • %s (%s)\n", m.String(), m.ActionID)
+	}
+	a.log("\n")
+
+	return fmt.Errorf("missing %d required permission(s); use --skip-preflight to apply anyway", len(missing))
+}
+
 // confirm prompts the user to confirm a high-risk action
 func (a *Applier) confirm(action types.Action) (bool, error) {
 	fmt.Fprintf(a.output, "\n⚠️  HIGH-RISK ACTION (%s)\n", action.EscalationLevel)
 	fmt.Fprintf(a.output, "  Target:  %s/%s\n", action.Target.Kind, action.Target.Name)
 	fmt.Fprintf(a.output, "  Action:  %s\n", action.Description)
 	fmt.Fprintf(a.output, "  Command: %s\n", action.Command)
-	fmt.Fprintf(a.output, "  Risk:    %s\n\n", action.Risk)
+	fmt.Fprintf(a.output, "  Risk:    %s\n", action.Risk)
+	if c := action.CloudResource; c != nil {
+		// Whoever confirms needs the identifiers to clean up the orphaned resource later
+		fmt.Fprintf(a.output, "  Orphans: %s\n", c.Description())
+	}
+	fmt.Fprintln(a.output)
 
 	fmt.Fprint(a.output, "Proceed with this action? [y/N]: ")
 
@@ -185,6 +248,14 @@ func (a *Applier) logDryRun(num, total int, action types.Action) {
 	a.log("  Risk:    %s\n", action.Risk)
 }
 
+// logServerDryRun logs an action being validated by the API server
+func (a *Applier) logServerDryRun(num, total int, action types.Action) {
+	a.log("[%d/%d] SERVER DRY-RUN: %s\n", num, total, action.Description)
+	a.log("  Target:  %s", a.formatTarget(action.Target))
+	a.log("  Command: %s\n", action.Command)
+	a.log("  Risk:    %s\n", action.Risk)
+}
+
 // logAction logs an action being executed
 func (a *Applier) logAction(num, total int, action types.Action) {
 	a.log("[%d/%d] EXECUTING: %s\n", num, total, action.Description)
@@ -210,6 +281,28 @@ func (a *Applier) log(format string, args ...interface{}) {
 	}
 }
 
+// dryRunMode reports how actions are validated when not applied
+func (a *Applier) dryRunMode() types.DryRunMode {
+	switch {
+	case a.serverDryRun:
+		return types.DryRunServer
+	case a.dryRun:
+		return types.DryRunClient
+	default:
+		return types.DryRunNone
+	}
+}
+
+// isMutating returns true if the action sends a write request to the API server
+func isMutating(action types.Action) bool {
+	switch action.Type {
+	case types.ActionPatch, types.ActionDelete, types.ActionFinalize, types.ActionRevive:
+		return true
+	default:
+		return false
+	}
+}
+
 // calculateExitCode determines the exit code based on results
 func (a *Applier) calculateExitCode(result *types.ApplyResult) int {
 	if result.Failed == 0 && result.Skipped == 0 {
@@ -3,12 +3,19 @@ package applier
 import (
 	"bytes"
 	"context"
+	"errors"
 	"testing"
 	"time"
 
+	"github.com/sozercan/unstuck/pkg/kube"
 	"github.com/sozercan/unstuck/pkg/types"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
 )
 
 func TestNewApplier(t *testing.T) {
@@ -101,6 +108,30 @@ func TestApplier_Apply_DryRun(t *testing.T) {
 	output := buf.String()
 	assert.Contains(t, output, "DRY-RUN")
 	assert.Contains(t, output, "Inspect namespace")
+	assert.Equal(t, types.DryRunClient, result.DryRunMode)
+}
+
+func TestApplier_ConfirmListsCloudIdentifiers(t *testing.T) {
+	buf := &bytes.Buffer{}
+	app := NewApplier(nil, Options{Output: buf})
+
+	confirmed, err := app.confirm(types.Action{
+		Type:            types.ActionPatch,
+		Description:     "Remove finalizers from Bucket/assets",
+		EscalationLevel: types.EscalationFinalizer,
+		Target:          types.ResourceRef{Kind: "Bucket", Name: "assets"},
+		Risk:            types.RiskHigh,
+		RequiresForce:   true,
+		CloudResource: &types.CloudResource{
+			Family:       types.CloudCrossplane,
+			ExternalName: "acme-assets",
+			Identifiers:  []string{"arn:aws:s3:::acme-assets"},
+		},
+	})
+
+	require.NoError(t, err)
+	assert.False(t, confirmed, "no answer on stdin is a no")
+	assert.Contains(t, buf.String(), "Orphans: Crossplane resource acme-assets (arn:aws:s3:::acme-assets)")
 }
 
 func TestApplier_CalculateExitCode(t *testing.T) {
@@ -209,3 +240,54 @@ func TestApplyResult_Duration(t *testing.T) {
 	duration := result.EndTime.Sub(result.StartTime)
 	assert.True(t, duration >= 10*time.Millisecond)
 }
+
+func TestApplier_Apply_ServerDryRun(t *testing.T) {
+	ns := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Finalizers: []string{"example.com/finalizer"}},
+	}
+	clientset := fake.NewSimpleClientset(ns)
+
+	var dryRun []string
+	clientset.PrependReactor("patch", "namespaces", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		dryRun = action.(k8stesting.PatchActionImpl).GetPatchOptions().DryRun
+		return true, nil, errors.New(`admission webhook "deny.example.com" denied the request`)
+	})
+
+	buf := &bytes.Buffer{}
+	app := NewApplier(&kube.Client{Clientset: clientset}, Options{
+		DryRun:       true,
+		ServerDryRun: true,
+		Output:       buf,
+	})
+
+	plan := &types.Plan{
+		Target: types.ResourceRef{Kind: "Namespace", Name: "test"},
+		Actions: []types.Action{
+			{
+				ID:          "action-1",
+				Type:        types.ActionInspect,
+				Description: "Inspect namespace",
+				Target:      types.ResourceRef{Kind: "Namespace", Name: "test"},
+			},
+			{
+				ID:          "action-2",
+				Type:        types.ActionPatch,
+				Description: "Remove finalizers",
+				Target:      types.ResourceRef{Kind: "Namespace", APIVersion: "v1", Name: "test"},
+			},
+		},
+	}
+
+	result, err := app.Apply(context.Background(), plan)
+
+	require.NoError(t, err)
+	assert.Equal(t, types.DryRunServer, result.DryRunMode)
+	assert.Equal(t, []string{metav1.DryRunAll}, dryRun)
+	assert.Equal(t, 1, result.Succeeded)
+	assert.Equal(t, 1, result.Failed)
+	assert.Contains(t, result.Actions[1].Error, "denied the request")
+
+	output := buf.String()
+	assert.Contains(t, output, "SERVER DRY-RUN")
+	assert.Contains(t, output, "SERVER WOULD REJECT")
+}
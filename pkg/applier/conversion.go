@@ -0,0 +1,37 @@
+package applier
+
+import (
+	"context"
+	"strings"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+)
+
+// disableConversion switches a CRD to None conversion so instances can be read without its
+// conversion webhook. Objects stored at other versions are then served with only apiVersion changed.
+func (e *Executor) disableConversion(ctx context.Context, name string) error {
+	patchData := []byte(`{"spec":{"conversion":{"strategy":"None","webhook":null}}}`)
+	_, err := e.client.ApiExtensions.ApiextensionsV1().CustomResourceDefinitions().Patch(
+		ctx,
+		name,
+		k8stypes.MergePatchType,
+		patchData,
+		metav1.PatchOptions{DryRun: e.dryRun()},
+	)
+	return err
+}
+
+// verifyConversionDisabled checks that the CRD no longer calls a conversion webhook
+func (e *Executor) verifyConversionDisabled(ctx context.Context, name string) (bool, error) {
+	crd, err := e.client.ApiExtensions.ApiextensionsV1().CustomResourceDefinitions().Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		// A deleted CRD has nothing left to convert
+		if strings.Contains(err.Error(), "not found") {
+			return true, nil
+		}
+		return false, err
+	}
+	return crd.Spec.Conversion == nil || crd.Spec.Conversion.Strategy == apiextensionsv1.NoneConverter, nil
+}
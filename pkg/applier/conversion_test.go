@@ -0,0 +1,51 @@
+package applier
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apiextensionsfake "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/fake"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/sozercan/unstuck/pkg/kube"
+	"github.com/sozercan/unstuck/pkg/types"
+)
+
+func TestExecutor_DisableConversion(t *testing.T) {
+	crd := &apiextensionsv1.CustomResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: "widgets.example.com"},
+		Spec: apiextensionsv1.CustomResourceDefinitionSpec{
+			Conversion: &apiextensionsv1.CustomResourceConversion{
+				Strategy: apiextensionsv1.WebhookConverter,
+				Webhook: &apiextensionsv1.WebhookConversion{
+					ClientConfig:             &apiextensionsv1.WebhookClientConfig{Service: &apiextensionsv1.ServiceReference{Namespace: "system", Name: "converter"}},
+					ConversionReviewVersions: []string{"v1"},
+				},
+			},
+		},
+	}
+	client := &kube.Client{ApiExtensions: apiextensionsfake.NewSimpleClientset(crd)}
+	e := NewExecutor(client)
+	action := types.Action{
+		Type:      types.ActionPatch,
+		Operation: "disable-conversion-webhook",
+		Target:    types.ResourceRef{Kind: "CustomResourceDefinition", APIVersion: "apiextensions.k8s.io/v1", Name: crd.Name},
+	}
+
+	disabled, err := e.Verify(context.Background(), action)
+	require.NoError(t, err)
+	assert.False(t, disabled)
+
+	require.NoError(t, e.Execute(context.Background(), action))
+
+	disabled, err = e.Verify(context.Background(), action)
+	require.NoError(t, err)
+	assert.True(t, disabled)
+
+	updated, err := client.ApiExtensions.ApiextensionsV1().CustomResourceDefinitions().Get(context.Background(), crd.Name, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Nil(t, updated.Spec.Conversion.Webhook)
+}
@@ -5,9 +5,11 @@ import (
 	"encoding/json"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/sozercan/unstuck/pkg/kube"
 	"github.com/sozercan/unstuck/pkg/types"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -16,12 +18,29 @@ import (
 
 // Executor handles the actual execution of actions
 type Executor struct {
-	client *kube.Client
+	client       *kube.Client
+	serverDryRun bool
+	pollInterval time.Duration
+	// revived holds the objects whose finalizers a revived controller was waiting to remove
+	revived map[string]bool
 }
 
 // NewExecutor creates a new executor
 func NewExecutor(client *kube.Client) *Executor {
-	return &Executor{client: client}
+	return &Executor{client: client, pollInterval: 2 * time.Second, revived: make(map[string]bool)}
+}
+
+// SetServerDryRun makes every mutating request a server-side dry-run (DryRun=All)
+func (e *Executor) SetServerDryRun(enabled bool) {
+	e.serverDryRun = enabled
+}
+
+// dryRun returns the DryRun option for mutating requests
+func (e *Executor) dryRun() []string {
+	if e.serverDryRun {
+		return []string{metav1.DryRunAll}
+	}
+	return nil
 }
 
 // Execute performs the given action
@@ -41,6 +60,8 @@ func (e *Executor) Execute(ctx context.Context, action types.Action) error {
 		return e.executeFinalize(ctx, action)
 	case types.ActionWait:
 		return e.executeWait(ctx, action)
+	case types.ActionRevive:
+		return e.executeRevive(ctx, action)
 	default:
 		return fmt.Errorf("unknown action type: %s", action.Type)
 	}
@@ -50,6 +71,16 @@ func (e *Executor) Execute(ctx context.Context, action types.Action) error {
 func (e *Executor) executePatch(ctx context.Context, action types.Action) error {
 	target := action.Target
 
+	// Garbage collector, job tracking and conversion actions change more or less than all finalizers
+	switch action.Operation {
+	case types.OpRemoveOwnerReference:
+		return e.removeOwnerReference(ctx, action)
+	case types.OpRemoveGCFinalizer, types.OpRemoveJobTrackingFinalizer:
+		return e.removeSingleFinalizer(ctx, action)
+	case types.OpDisableConversionWebhook:
+		return e.disableConversion(ctx, target.Name)
+	}
+
 	// Determine if this is a core resource or custom resource
 	switch target.Kind {
 	case "Namespace":
@@ -61,7 +92,8 @@ func (e *Executor) executePatch(ctx context.Context, action types.Action) error
 	}
 }
 
-// patchNamespace patches a namespace to remove finalizers
+// patchNamespace removes a namespace's metadata finalizers. spec.finalizers are only cleared by
+// the namespace controller or executeFinalize.
 func (e *Executor) patchNamespace(ctx context.Context, name string) error {
 	patchData := []byte(`{"metadata":{"finalizers":null}}`)
 	_, err := e.client.Clientset.CoreV1().Namespaces().Patch(
@@ -69,7 +101,7 @@ func (e *Executor) patchNamespace(ctx context.Context, name string) error {
 		name,
 		k8stypes.MergePatchType,
 		patchData,
-		metav1.PatchOptions{},
+		metav1.PatchOptions{DryRun: e.dryRun()},
 	)
 	return err
 }
@@ -82,7 +114,7 @@ func (e *Executor) patchCRD(ctx context.Context, name string) error {
 		name,
 		k8stypes.MergePatchType,
 		patchData,
-		metav1.PatchOptions{},
+		metav1.PatchOptions{DryRun: e.dryRun()},
 	)
 	return err
 }
@@ -101,8 +133,12 @@ func (e *Executor) patchDynamicResource(ctx context.Context, target types.Resour
 		target.Name,
 		k8stypes.MergePatchType,
 		patchData,
-		metav1.PatchOptions{},
+		metav1.PatchOptions{DryRun: e.dryRun()},
 	)
+	if apierrors.IsNotFound(err) && e.revived[target.String()] {
+		// Already deleted by the controller revived earlier in the plan
+		return nil
+	}
 	return err
 }
 
@@ -110,17 +146,31 @@ func (e *Executor) patchDynamicResource(ctx context.Context, target types.Resour
 func (e *Executor) executeDelete(ctx context.Context, action types.Action) error {
 	target := action.Target
 
+	if action.Operation == types.OpUnsafeDelete {
+		return e.executeUnsafeDelete(ctx, action)
+	}
+
+	// Jobs deleted through the API orphan their pods unless asked otherwise
+	if action.Operation == types.OpDeleteHelmHook {
+		gvr, err := e.resolveGVR(target)
+		if err != nil {
+			return fmt.Errorf("failed to resolve GVR: %w", err)
+		}
+		propagation := metav1.DeletePropagationBackground
+		return e.client.Dynamic.Resource(gvr).Namespace(target.Namespace).Delete(ctx, target.Name, metav1.DeleteOptions{DryRun: e.dryRun(), PropagationPolicy: &propagation})
+	}
+
 	switch target.Kind {
 	case "Namespace":
-		return e.client.Clientset.CoreV1().Namespaces().Delete(ctx, target.Name, metav1.DeleteOptions{})
+		return e.client.Clientset.CoreV1().Namespaces().Delete(ctx, target.Name, metav1.DeleteOptions{DryRun: e.dryRun()})
 	case "CustomResourceDefinition":
-		return e.client.ApiExtensions.ApiextensionsV1().CustomResourceDefinitions().Delete(ctx, target.Name, metav1.DeleteOptions{})
+		return e.client.ApiExtensions.ApiextensionsV1().CustomResourceDefinitions().Delete(ctx, target.Name, metav1.DeleteOptions{DryRun: e.dryRun()})
 	default:
 		gvr, err := e.resolveGVR(target)
 		if err != nil {
 			return fmt.Errorf("failed to resolve GVR: %w", err)
 		}
-		return e.client.Dynamic.Resource(gvr).Namespace(target.Namespace).Delete(ctx, target.Name, metav1.DeleteOptions{})
+		return e.client.Dynamic.Resource(gvr).Namespace(target.Namespace).Delete(ctx, target.Name, metav1.DeleteOptions{DryRun: e.dryRun()})
 	}
 }
 
@@ -138,11 +188,11 @@ func (e *Executor) executeFinalize(ctx context.Context, action types.Action) err
 		return fmt.Errorf("failed to get namespace: %w", err)
 	}
 
-	// Clear finalizers
+	// Clear spec finalizers; metadata finalizers are removed by a separate patch action
 	ns.Spec.Finalizers = nil
 
 	// Use the finalize subresource
-	_, err = e.client.Clientset.CoreV1().Namespaces().Finalize(ctx, ns, metav1.UpdateOptions{})
+	_, err = e.client.Clientset.CoreV1().Namespaces().Finalize(ctx, ns, metav1.UpdateOptions{DryRun: e.dryRun()})
 	return err
 }
 
@@ -160,10 +210,20 @@ func (e *Executor) Verify(ctx context.Context, action types.Action) (bool, error
 		return true, nil
 
 	case types.ActionPatch:
+		if action.Operation == types.OpRemoveOwnerReference || action.Operation == types.OpRemoveGCFinalizer || action.Operation == types.OpRemoveJobTrackingFinalizer {
+			return e.verifyListEntryRemoved(ctx, action)
+		}
+		if action.Operation == types.OpDisableConversionWebhook {
+			return e.verifyConversionDisabled(ctx, action.Target.Name)
+		}
 		// Verify finalizers are removed
 		return e.verifyNoFinalizers(ctx, action.Target)
 
 	case types.ActionDelete:
+		// Cascaded resources and hook Jobs may wait on their own finalizers
+		if action.Operation == types.OpCascadeDelete || action.Operation == types.OpDeleteHelmHook {
+			return e.verifyDeletionStarted(ctx, action.Target)
+		}
 		// Verify resource is gone (404)
 		return e.verifyDeleted(ctx, action.Target)
 
@@ -171,6 +231,10 @@ func (e *Executor) Verify(ctx context.Context, action types.Action) (bool, error
 		// Verify namespace is gone or no longer terminating
 		return e.verifyNamespaceCleared(ctx, action.Target.Name)
 
+	case types.ActionRevive:
+		// Verify the revived controller removed the finalizers
+		return e.finalizersCleared(ctx, action.WaitFor)
+
 	default:
 		return false, fmt.Errorf("unknown action type for verification: %s", action.Type)
 	}
@@ -260,6 +324,22 @@ func (e *Executor) verifyDeleted(ctx context.Context, target types.ResourceRef)
 	}
 }
 
+// verifyDeletionStarted checks that a resource is gone or has a deletion timestamp
+func (e *Executor) verifyDeletionStarted(ctx context.Context, target types.ResourceRef) (bool, error) {
+	gvr, err := e.resolveGVR(target)
+	if err != nil {
+		return true, nil
+	}
+	obj, err := e.client.Dynamic.Resource(gvr).Namespace(target.Namespace).Get(ctx, target.Name, metav1.GetOptions{})
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return true, nil
+		}
+		return false, err
+	}
+	return obj.GetDeletionTimestamp() != nil, nil
+}
+
 // verifyNamespaceCleared checks if a namespace is gone or no longer terminating
 func (e *Executor) verifyNamespaceCleared(ctx context.Context, name string) (bool, error) {
 	ns, err := e.client.Clientset.CoreV1().Namespaces().Get(ctx, name, metav1.GetOptions{})
@@ -284,6 +364,7 @@ func (e *Executor) Snapshot(ctx context.Context, target types.ResourceRef) (inte
 		}
 		return map[string]interface{}{
 			"finalizers":        ns.Finalizers,
+			"specFinalizers":    ns.Spec.Finalizers,
 			"deletionTimestamp": ns.DeletionTimestamp,
 			"phase":             ns.Status.Phase,
 		}, nil
@@ -324,14 +405,21 @@ func (e *Executor) resolveGVR(target types.ResourceRef) (schema.GroupVersionReso
 		gv = "v1" // Default to core API
 	}
 
+	if target.Kind == "" {
+		return schema.GroupVersionResource{}, fmt.Errorf("%s has no kind", target.String())
+	}
+
 	parts := strings.Split(gv, "/")
 	var group, version string
-	if len(parts) == 1 {
+	switch {
+	case len(parts) == 1:
 		group = ""
 		version = parts[0]
-	} else {
+	case len(parts) == 2 && parts[0] != "" && parts[1] != "":
 		group = parts[0]
 		version = parts[1]
+	default:
+		return schema.GroupVersionResource{}, fmt.Errorf("invalid apiVersion %q", target.APIVersion)
 	}
 
 	// Get resource name (plural, lowercase)
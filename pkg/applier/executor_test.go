@@ -1,10 +1,18 @@
 package applier
 
 import (
+	"context"
 	"testing"
 
-	"github.com/sozercan/unstuck/pkg/types"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+
+	"github.com/sozercan/unstuck/pkg/kube"
+	"github.com/sozercan/unstuck/pkg/types"
 )
 
 func TestNewExecutor(t *testing.T) {
@@ -140,3 +148,42 @@ func TestSnapshotJSON(t *testing.T) {
 		})
 	}
 }
+
+func TestExecutor_VerifyCascadeDelete(t *testing.T) {
+	now := metav1.Now()
+	terminating := &unstructured.Unstructured{}
+	terminating.SetAPIVersion("apps/v1")
+	terminating.SetKind("Deployment")
+	terminating.SetNamespace("apps")
+	terminating.SetName("web")
+	terminating.SetFinalizers([]string{"example.com/cleanup"})
+	terminating.SetDeletionTimestamp(&now)
+
+	e := NewExecutor(&kube.Client{Dynamic: dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), terminating)})
+
+	cascade := types.Action{
+		Type:      types.ActionDelete,
+		Operation: "cascade-delete",
+		Target:    types.ResourceRef{Kind: "Deployment", APIVersion: "apps/v1", Namespace: "apps", Name: "web"},
+	}
+	verified, err := e.Verify(context.Background(), cascade)
+	require.NoError(t, err)
+	assert.True(t, verified, "a resource waiting on its own finalizers has been deleted")
+
+	cascade.Target.Name = "gone"
+	verified, err = e.Verify(context.Background(), cascade)
+	require.NoError(t, err)
+	assert.True(t, verified)
+}
+
+func TestExecutor_RemoveFinalizers_NotFound(t *testing.T) {
+	e := NewExecutor(&kube.Client{Dynamic: dynamicfake.NewSimpleDynamicClient(runtime.NewScheme())})
+	widget := types.ResourceRef{Kind: "Widget", APIVersion: "example.com/v1", Namespace: "apps", Name: "w1"}
+	action := types.Action{Type: types.ActionPatch, Operation: "remove-finalizers", Target: widget}
+
+	// A missing object is an error unless a revived controller was expected to delete it
+	require.Error(t, e.Execute(context.Background(), action))
+
+	e.revived[widget.String()] = true
+	require.NoError(t, e.Execute(context.Background(), action))
+}
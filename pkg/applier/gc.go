@@ -0,0 +1,116 @@
+package applier
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+
+	"github.com/sozercan/unstuck/pkg/types"
+)
+
+// jsonPatchOp is a single RFC 6902 operation
+type jsonPatchOp struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+// removeOwnerReference drops the owner reference with the action's OwnerUID
+func (e *Executor) removeOwnerReference(ctx context.Context, action types.Action) error {
+	index := func(obj *unstructured.Unstructured) int {
+		for i, owner := range obj.GetOwnerReferences() {
+			if string(owner.UID) == action.OwnerUID {
+				return i
+			}
+		}
+		return -1
+	}
+	return e.removeListEntry(ctx, action.Target, "ownerReferences", index, "/uid", action.OwnerUID)
+}
+
+// removeSingleFinalizer removes only the action's finalizer, leaving any others in place
+func (e *Executor) removeSingleFinalizer(ctx context.Context, action types.Action) error {
+	index := func(obj *unstructured.Unstructured) int {
+		for i, f := range obj.GetFinalizers() {
+			if f == action.Finalizer {
+				return i
+			}
+		}
+		return -1
+	}
+	return e.removeListEntry(ctx, action.Target, "finalizers", index, "", action.Finalizer)
+}
+
+// removeListEntry removes one entry from a metadata list with a JSON patch that first tests
+// the entry (or its testField) still holds value, so concurrent changes make the patch fail
+// instead of removing the wrong entry. A missing object or entry counts as success.
+func (e *Executor) removeListEntry(ctx context.Context, target types.ResourceRef, list string, index func(*unstructured.Unstructured) int, testField, value string) error {
+	gvr, err := e.resolveGVR(target)
+	if err != nil {
+		return fmt.Errorf("failed to resolve GVR: %w", err)
+	}
+	resource := e.client.Dynamic.Resource(gvr).Namespace(target.Namespace)
+
+	obj, err := resource.Get(ctx, target.Name, metav1.GetOptions{})
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return nil
+		}
+		return err
+	}
+
+	i := index(obj)
+	if i < 0 {
+		return nil
+	}
+
+	path := fmt.Sprintf("/metadata/%s/%d", list, i)
+	patch, err := json.Marshal([]jsonPatchOp{
+		{Op: "test", Path: path + testField, Value: value},
+		{Op: "remove", Path: path},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to build patch: %w", err)
+	}
+
+	_, err = resource.Patch(ctx, target.Name, k8stypes.JSONPatchType, patch, metav1.PatchOptions{DryRun: e.dryRun()})
+	if err != nil && strings.Contains(err.Error(), "not found") {
+		return nil
+	}
+	return err
+}
+
+// verifyListEntryRemoved checks that the owner reference or finalizer targeted by a GC action is gone
+func (e *Executor) verifyListEntryRemoved(ctx context.Context, action types.Action) (bool, error) {
+	gvr, err := e.resolveGVR(action.Target)
+	if err != nil {
+		return false, err
+	}
+	obj, err := e.client.Dynamic.Resource(gvr).Namespace(action.Target.Namespace).Get(ctx, action.Target.Name, metav1.GetOptions{})
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return true, nil
+		}
+		return false, err
+	}
+
+	if action.Operation == types.OpRemoveOwnerReference {
+		for _, owner := range obj.GetOwnerReferences() {
+			if string(owner.UID) == action.OwnerUID {
+				return false, nil
+			}
+		}
+		return true, nil
+	}
+	for _, f := range obj.GetFinalizers() {
+		if f == action.Finalizer {
+			return false, nil
+		}
+	}
+	return true, nil
+}
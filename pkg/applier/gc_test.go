@@ -0,0 +1,55 @@
+package applier
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+
+	"github.com/sozercan/unstuck/pkg/kube"
+	"github.com/sozercan/unstuck/pkg/types"
+)
+
+func TestExecutor_GCActions(t *testing.T) {
+	pod := &unstructured.Unstructured{}
+	pod.SetAPIVersion("v1")
+	pod.SetKind("Pod")
+	pod.SetNamespace("apps")
+	pod.SetName("web-abc")
+	pod.SetFinalizers([]string{"example.com/keep", "orphan"})
+	pod.SetOwnerReferences([]metav1.OwnerReference{
+		{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: "web-live", UID: "live-uid"},
+		{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: "web-old", UID: "gone-uid"},
+	})
+
+	dynamic := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), pod)
+	e := NewExecutor(&kube.Client{Dynamic: dynamic})
+	target := types.ResourceRef{Kind: "Pod", APIVersion: "v1", Namespace: "apps", Name: "web-abc"}
+
+	dropOwner := types.Action{Type: types.ActionPatch, Operation: "remove-owner-reference", Target: target, OwnerUID: "gone-uid"}
+	require.NoError(t, e.Execute(context.Background(), dropOwner))
+	verified, err := e.Verify(context.Background(), dropOwner)
+	require.NoError(t, err)
+	assert.True(t, verified)
+
+	removeOrphan := types.Action{Type: types.ActionPatch, Operation: "remove-gc-finalizer", Target: target, Finalizer: "orphan"}
+	require.NoError(t, e.Execute(context.Background(), removeOrphan))
+	verified, err = e.Verify(context.Background(), removeOrphan)
+	require.NoError(t, err)
+	assert.True(t, verified)
+
+	obj, err := dynamic.Resource(schema.GroupVersionResource{Version: "v1", Resource: "pods"}).Namespace("apps").Get(context.Background(), "web-abc", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"example.com/keep"}, obj.GetFinalizers())
+	require.Len(t, obj.GetOwnerReferences(), 1)
+	assert.Equal(t, "live-uid", string(obj.GetOwnerReferences()[0].UID))
+
+	// Running again is a no-op once the entries are gone
+	assert.NoError(t, e.Execute(context.Background(), dropOwner))
+}
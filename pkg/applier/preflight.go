@@ -0,0 +1,139 @@
+package applier
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/sozercan/unstuck/pkg/types"
+)
+
+// primaryVerbs is the main API verb each mutating action type needs
+var primaryVerbs = map[types.ActionType]string{
+	types.ActionPatch:    "patch",
+	types.ActionDelete:   "delete",
+	types.ActionFinalize: "update",
+	types.ActionRevive:   "patch",
+}
+
+// unresolvedReason prefixes the reason of checks for targets whose resource could not be resolved
+const unresolvedReason = "cannot resolve resource"
+
+// RequiredPermissions returns the API permissions needed to execute an action
+// Read-only actions return no permissions since diagnosis already succeeded
+func (e *Executor) RequiredPermissions(action types.Action) []types.PermissionCheck {
+	target := action.Target
+	verb, ok := primaryVerbs[action.Type]
+	if !ok {
+		return nil
+	}
+
+	gvr, err := e.resolveGVR(target)
+	if err != nil {
+		// Report the action as not allowed rather than skipping a check that cannot be made
+		return []types.PermissionCheck{{
+			ActionID:  action.ID,
+			Verb:      verb,
+			Resource:  strings.ToLower(target.Kind),
+			Namespace: target.Namespace,
+			Name:      target.Name,
+			Reason:    fmt.Sprintf("%s: %v", unresolvedReason, err),
+		}}
+	}
+
+	check := types.PermissionCheck{
+		ActionID:  action.ID,
+		Group:     gvr.Group,
+		Resource:  gvr.Resource,
+		Namespace: target.Namespace,
+		Name:      target.Name,
+	}
+
+	switch action.Type {
+	case types.ActionPatch:
+		check.Verb = "patch"
+		if action.Operation == types.OpRemoveOwnerReference || action.Operation == types.OpRemoveGCFinalizer || action.Operation == types.OpRemoveJobTrackingFinalizer {
+			// The current list is read first to find the entry to remove
+			get := check
+			get.Verb = "get"
+			return []types.PermissionCheck{get, check}
+		}
+		return []types.PermissionCheck{check}
+
+	case types.ActionDelete:
+		check.Verb = "delete"
+		if action.Operation == types.OpUnsafeDelete {
+			// The API server authorizes unsafe deletes with an extra verb
+			unsafe := check
+			unsafe.Verb = "unsafe-delete-ignore-read-errors"
+			return []types.PermissionCheck{check, unsafe}
+		}
+		return []types.PermissionCheck{check}
+
+	case types.ActionFinalize:
+		// executeFinalize reads the namespace before replacing the finalize subresource
+		get := check
+		get.Verb = "get"
+		finalize := check
+		finalize.Verb = "update"
+		finalize.Subresource = "finalize"
+		return []types.PermissionCheck{get, finalize}
+
+	case types.ActionRevive:
+		// executeRevive patches the workload and then polls it until ready
+		patch := check
+		patch.Verb = "patch"
+		get := check
+		get.Verb = "get"
+		return []types.PermissionCheck{patch, get}
+
+	default:
+		return nil
+	}
+}
+
+// CheckPermissions issues a SelfSubjectAccessReview for every permission required by the plan
+func (e *Executor) CheckPermissions(ctx context.Context, plan *types.Plan) ([]types.PermissionCheck, error) {
+	if plan == nil {
+		return nil, fmt.Errorf("plan cannot be nil")
+	}
+
+	var checks []types.PermissionCheck
+	for _, action := range plan.Actions {
+		for _, check := range e.RequiredPermissions(action) {
+			if strings.HasPrefix(check.Reason, unresolvedReason) {
+				checks = append(checks, check)
+				continue
+			}
+			review := &authorizationv1.SelfSubjectAccessReview{
+				Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+					ResourceAttributes: &authorizationv1.ResourceAttributes{
+						Namespace:   check.Namespace,
+						Verb:        check.Verb,
+						Group:       check.Group,
+						Resource:    check.Resource,
+						Subresource: check.Subresource,
+						Name:        check.Name,
+					},
+				},
+			}
+
+			result, err := e.client.Clientset.AuthorizationV1().SelfSubjectAccessReviews().Create(ctx, review, metav1.CreateOptions{})
+			if err != nil {
+				return nil, fmt.Errorf("failed to check %q: %w", check.String(), err)
+			}
+
+			check.Allowed = result.Status.Allowed
+			check.Reason = result.Status.Reason
+			if result.Status.EvaluationError != "" && check.Reason == "" {
+				check.Reason = result.Status.EvaluationError
+			}
+			checks = append(checks, check)
+		}
+	}
+
+	return checks, nil
+}
@@ -0,0 +1,150 @@
+package applier
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/sozercan/unstuck/pkg/kube"
+	"github.com/sozercan/unstuck/pkg/types"
+)
+
+// newSSARClient returns a client whose SelfSubjectAccessReviews deny the given verbs
+func newSSARClient(deniedVerbs ...string) *kube.Client {
+	clientset := fake.NewSimpleClientset()
+	clientset.PrependReactor("create", "selfsubjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SelfSubjectAccessReview)
+		review.Status.Allowed = true
+		for _, verb := range deniedVerbs {
+			if review.Spec.ResourceAttributes.Verb == verb {
+				review.Status.Allowed = false
+				review.Status.Reason = "forbidden by test"
+			}
+		}
+		return true, review, nil
+	})
+	return &kube.Client{Clientset: clientset}
+}
+
+func TestExecutor_RequiredPermissions(t *testing.T) {
+	exec := NewExecutor(nil)
+
+	tests := []struct {
+		name   string
+		action types.Action
+		want   []string
+	}{
+		{
+			name:   "inspect needs nothing",
+			action: types.Action{Type: types.ActionInspect, Target: types.ResourceRef{Kind: "Namespace", APIVersion: "v1", Name: "test"}},
+			want:   nil,
+		},
+		{
+			name:   "patch custom resource",
+			action: types.Action{Type: types.ActionPatch, Target: types.ResourceRef{Kind: "Certificate", APIVersion: "cert-manager.io/v1", Namespace: "test", Name: "my-cert"}},
+			want:   []string{"patch certificates.cert-manager.io my-cert -n test"},
+		},
+		{
+			name:   "patch CRD",
+			action: types.Action{Type: types.ActionPatch, Target: types.ResourceRef{Kind: "CustomResourceDefinition", APIVersion: "apiextensions.k8s.io/v1", Name: "certificates.cert-manager.io"}},
+			want:   []string{"patch customresourcedefinitions.apiextensions.k8s.io certificates.cert-manager.io"},
+		},
+		{
+			name:   "remove job tracking finalizer",
+			action: types.Action{Type: types.ActionPatch, Operation: "remove-job-tracking-finalizer", Target: types.ResourceRef{Kind: "Pod", APIVersion: "v1", Namespace: "test", Name: "job-abc"}},
+			want:   []string{"get pods job-abc -n test", "patch pods job-abc -n test"},
+		},
+		{
+			name:   "delete pod",
+			action: types.Action{Type: types.ActionDelete, Target: types.ResourceRef{Kind: "Pod", APIVersion: "v1", Namespace: "test", Name: "my-pod"}},
+			want:   []string{"delete pods my-pod -n test"},
+		},
+		{
+			name:   "unsafe delete corrupt object",
+			action: types.Action{Type: types.ActionDelete, Operation: "unsafe-delete", Target: types.ResourceRef{Kind: "Widget", APIVersion: "example.com/v1", Namespace: "test", Name: "w1"}},
+			want:   []string{"delete widgets.example.com w1 -n test", "unsafe-delete-ignore-read-errors widgets.example.com w1 -n test"},
+		},
+		{
+			name:   "finalize namespace",
+			action: types.Action{Type: types.ActionFinalize, Target: types.ResourceRef{Kind: "Namespace", APIVersion: "v1", Name: "test"}},
+			want:   []string{"get namespaces test", "update namespaces/finalize test"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, check := range exec.RequiredPermissions(tt.action) {
+				got = append(got, check.String())
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestExecutor_CheckPermissions(t *testing.T) {
+	exec := NewExecutor(newSSARClient("update"))
+
+	plan := &types.Plan{
+		Actions: []types.Action{
+			{ID: "action-001", Type: types.ActionInspect, Target: types.ResourceRef{Kind: "Namespace", APIVersion: "v1", Name: "test"}},
+			{ID: "action-002", Type: types.ActionPatch, Target: types.ResourceRef{Kind: "Pod", APIVersion: "v1", Namespace: "test", Name: "my-pod"}},
+			{ID: "action-003", Type: types.ActionFinalize, Target: types.ResourceRef{Kind: "Namespace", APIVersion: "v1", Name: "test"}},
+		},
+	}
+
+	checks, err := exec.CheckPermissions(context.Background(), plan)
+	require.NoError(t, err)
+	require.Len(t, checks, 3)
+
+	assert.True(t, checks[0].Allowed)
+	assert.Equal(t, "action-002", checks[0].ActionID)
+	assert.True(t, checks[1].Allowed)
+	assert.False(t, checks[2].Allowed)
+	assert.Equal(t, "finalize", checks[2].Subresource)
+	assert.Equal(t, "forbidden by test", checks[2].Reason)
+}
+
+func TestExecutor_CheckPermissions_UnresolvedTarget(t *testing.T) {
+	exec := NewExecutor(newSSARClient())
+
+	plan := &types.Plan{
+		Actions: []types.Action{
+			{ID: "action-001", Type: types.ActionPatch, Target: types.ResourceRef{Kind: "Widget", APIVersion: "example.com/v1/extra", Namespace: "test", Name: "w1"}},
+		},
+	}
+
+	checks, err := exec.CheckPermissions(context.Background(), plan)
+	require.NoError(t, err)
+	require.Len(t, checks, 1)
+	assert.False(t, checks[0].Allowed, "a check that cannot be made is not granted")
+	assert.Equal(t, "patch", checks[0].Verb)
+	assert.Contains(t, checks[0].Reason, `invalid apiVersion "example.com/v1/extra"`)
+	assert.Len(t, (&types.Plan{Permissions: checks}).MissingPermissions(), 1)
+}
+
+func TestApplier_Apply_PreflightRefusesMissingPermissions(t *testing.T) {
+	buf := &bytes.Buffer{}
+	app := NewApplier(newSSARClient("patch"), Options{Output: buf})
+
+	plan := &types.Plan{
+		Actions: []types.Action{
+			{ID: "action-001", Type: types.ActionPatch, Target: types.ResourceRef{Kind: "Pod", APIVersion: "v1", Namespace: "test", Name: "my-pod"}},
+		},
+	}
+
+	result, err := app.Apply(context.Background(), plan)
+
+	assert.Error(t, err)
+	assert.Nil(t, result)
+	assert.Contains(t, err.Error(), "--skip-preflight")
+	assert.Len(t, plan.MissingPermissions(), 1)
+	assert.Contains(t, buf.String(), "patch pods my-pod -n test")
+}
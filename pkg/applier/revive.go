@@ -0,0 +1,137 @@
+package applier
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	"github.com/sozercan/unstuck/pkg/types"
+)
+
+// defaultReviveTimeout bounds how long a revive action waits for the controller and its finalizers
+const defaultReviveTimeout = 5 * time.Minute
+
+// executeRevive scales up or restarts a controller workload, waits for it to become ready,
+// and then waits for the finalizers it owns to be removed from the action's WaitFor objects
+func (e *Executor) executeRevive(ctx context.Context, action types.Action) error {
+	if err := e.patchWorkload(ctx, action); err != nil {
+		return err
+	}
+	for _, ref := range action.WaitFor {
+		e.revived[ref.String()] = true
+	}
+
+	// Nothing actually changed, so there is nothing to wait for
+	if e.serverDryRun {
+		return nil
+	}
+
+	timeout := action.Timeout
+	if timeout == 0 {
+		timeout = defaultReviveTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	if err := wait.PollUntilContextCancel(ctx, e.pollInterval, true, func(ctx context.Context) (bool, error) {
+		return e.workloadReady(ctx, action.Target)
+	}); err != nil {
+		return fmt.Errorf("controller %s did not become ready: %w", action.Target.String(), err)
+	}
+
+	if err := wait.PollUntilContextCancel(ctx, e.pollInterval, true, func(ctx context.Context) (bool, error) {
+		return e.finalizersCleared(ctx, action.WaitFor)
+	}); err != nil {
+		return fmt.Errorf("controller is ready but finalizers were not removed: %w", err)
+	}
+
+	return nil
+}
+
+// patchWorkload applies the scale-up or rollout-restart patch to a Deployment or StatefulSet
+func (e *Executor) patchWorkload(ctx context.Context, action types.Action) error {
+	var patchData []byte
+	switch action.Operation {
+	case types.OpScaleUp:
+		patchData = []byte(`{"spec":{"replicas":1}}`)
+	case types.OpRolloutRestart:
+		// Same annotation kubectl rollout restart sets
+		patchData = []byte(fmt.Sprintf(
+			`{"spec":{"template":{"metadata":{"annotations":{"kubectl.kubernetes.io/restartedAt":%q}}}}}`,
+			time.Now().Format(time.RFC3339),
+		))
+	default:
+		return fmt.Errorf("unknown revive operation: %s", action.Operation)
+	}
+
+	target := action.Target
+	opts := metav1.PatchOptions{DryRun: e.dryRun()}
+	var err error
+	switch target.Kind {
+	case "Deployment":
+		_, err = e.client.Clientset.AppsV1().Deployments(target.Namespace).Patch(ctx, target.Name, k8stypes.StrategicMergePatchType, patchData, opts)
+	case "StatefulSet":
+		_, err = e.client.Clientset.AppsV1().StatefulSets(target.Namespace).Patch(ctx, target.Name, k8stypes.StrategicMergePatchType, patchData, opts)
+	default:
+		return fmt.Errorf("revive action only supports Deployment and StatefulSet, got: %s", target.Kind)
+	}
+	return err
+}
+
+// workloadReady returns true once the workload's current generation is fully rolled out and ready
+func (e *Executor) workloadReady(ctx context.Context, target types.ResourceRef) (bool, error) {
+	switch target.Kind {
+	case "Deployment":
+		deploy, err := e.client.Clientset.AppsV1().Deployments(target.Namespace).Get(ctx, target.Name, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		return deploymentRolledOut(deploy), nil
+	case "StatefulSet":
+		sts, err := e.client.Clientset.AppsV1().StatefulSets(target.Namespace).Get(ctx, target.Name, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		return statefulSetRolledOut(sts), nil
+	default:
+		return false, fmt.Errorf("revive action only supports Deployment and StatefulSet, got: %s", target.Kind)
+	}
+}
+
+// finalizersCleared returns true when none of the objects has finalizers left
+func (e *Executor) finalizersCleared(ctx context.Context, refs []types.ResourceRef) (bool, error) {
+	for _, ref := range refs {
+		cleared, err := e.verifyNoFinalizers(ctx, ref)
+		if err != nil || !cleared {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+func deploymentRolledOut(deploy *appsv1.Deployment) bool {
+	replicas := int32(1)
+	if deploy.Spec.Replicas != nil {
+		replicas = *deploy.Spec.Replicas
+	}
+	return replicas > 0 &&
+		deploy.Status.ObservedGeneration >= deploy.Generation &&
+		deploy.Status.UpdatedReplicas >= replicas &&
+		deploy.Status.AvailableReplicas >= replicas
+}
+
+func statefulSetRolledOut(sts *appsv1.StatefulSet) bool {
+	replicas := int32(1)
+	if sts.Spec.Replicas != nil {
+		replicas = *sts.Spec.Replicas
+	}
+	return replicas > 0 &&
+		sts.Status.ObservedGeneration >= sts.Generation &&
+		sts.Status.UpdatedReplicas >= replicas &&
+		sts.Status.ReadyReplicas >= replicas
+}
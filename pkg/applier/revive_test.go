@@ -0,0 +1,89 @@
+package applier
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/sozercan/unstuck/pkg/kube"
+	"github.com/sozercan/unstuck/pkg/types"
+)
+
+func reviveAction(operation string, waitFor ...types.ResourceRef) types.Action {
+	return types.Action{
+		Type:      types.ActionRevive,
+		Operation: operation,
+		Target:    types.ResourceRef{Kind: "Deployment", APIVersion: "apps/v1", Namespace: "operators", Name: "widget-operator"},
+		Timeout:   time.Second,
+		WaitFor:   waitFor,
+	}
+}
+
+func TestExecutor_Revive_ScaleUp(t *testing.T) {
+	zero := int32(0)
+	clientset := fake.NewSimpleClientset(
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "widget-operator", Namespace: "operators"},
+			Spec:       appsv1.DeploymentSpec{Replicas: &zero},
+			// The fake clientset does not run controllers, so report the post-scale status up front
+			Status: appsv1.DeploymentStatus{UpdatedReplicas: 1, AvailableReplicas: 1},
+		},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "apps"}},
+	)
+	e := NewExecutor(&kube.Client{Clientset: clientset})
+	e.pollInterval = 10 * time.Millisecond
+	action := reviveAction("scale-up", types.ResourceRef{Kind: "Namespace", Name: "apps"})
+
+	require.NoError(t, e.Execute(context.Background(), action))
+
+	deploy, err := clientset.AppsV1().Deployments("operators").Get(context.Background(), "widget-operator", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), *deploy.Spec.Replicas)
+
+	verified, err := e.Verify(context.Background(), action)
+	require.NoError(t, err)
+	assert.True(t, verified)
+}
+
+func TestExecutor_Revive_TimesOutWaitingForFinalizers(t *testing.T) {
+	one := int32(1)
+	clientset := fake.NewSimpleClientset(
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "widget-operator", Namespace: "operators"},
+			Spec:       appsv1.DeploymentSpec{Replicas: &one},
+			Status:     appsv1.DeploymentStatus{UpdatedReplicas: 1, AvailableReplicas: 1},
+		},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "apps", Finalizers: []string{"example.com/cleanup"}}},
+	)
+	e := NewExecutor(&kube.Client{Clientset: clientset})
+	e.pollInterval = 10 * time.Millisecond
+	action := reviveAction("rollout-restart", types.ResourceRef{Kind: "Namespace", Name: "apps"})
+	action.Timeout = 50 * time.Millisecond
+
+	err := e.Execute(context.Background(), action)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "finalizers were not removed")
+
+	deploy, getErr := clientset.AppsV1().Deployments("operators").Get(context.Background(), "widget-operator", metav1.GetOptions{})
+	require.NoError(t, getErr)
+	assert.Contains(t, deploy.Spec.Template.Annotations, "kubectl.kubernetes.io/restartedAt")
+}
+
+func TestExecutor_Revive_UnsupportedKind(t *testing.T) {
+	e := NewExecutor(&kube.Client{Clientset: fake.NewSimpleClientset()})
+	action := reviveAction("scale-up")
+	action.Target.Kind = "DaemonSet"
+
+	err := e.Execute(context.Background(), action)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "only supports Deployment and StatefulSet")
+}
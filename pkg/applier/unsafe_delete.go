@@ -0,0 +1,55 @@
+package applier
+
+import (
+	"context"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/version"
+
+	"github.com/sozercan/unstuck/pkg/types"
+)
+
+// minUnsafeDeleteVersion is the first Kubernetes release that understands
+// ignoreStoreReadErrorWithClusterBreakingPotential
+var minUnsafeDeleteVersion = version.MustParseGeneric("v1.32.0")
+
+// executeUnsafeDelete deletes an object that cannot be read from storage. The API server skips
+// finalizers and preconditions for such deletes, so this is only ever planned at L4.
+func (e *Executor) executeUnsafeDelete(ctx context.Context, action types.Action) error {
+	if err := e.checkUnsafeDeleteSupported(); err != nil {
+		return err
+	}
+
+	gvr, err := e.resolveGVR(action.Target)
+	if err != nil {
+		return fmt.Errorf("failed to resolve GVR: %w", err)
+	}
+
+	ignoreReadError := true
+	return e.client.Dynamic.Resource(gvr).Namespace(action.Target.Namespace).Delete(ctx, action.Target.Name, metav1.DeleteOptions{
+		DryRun: e.dryRun(),
+		IgnoreStoreReadErrorWithClusterBreakingPotential: &ignoreReadError,
+	})
+}
+
+// checkUnsafeDeleteSupported refuses unsafe deletes on servers that would silently ignore the option
+func (e *Executor) checkUnsafeDeleteSupported() error {
+	info, err := e.client.Discovery.ServerVersion()
+	if err != nil {
+		return fmt.Errorf("failed to get server version: %w", err)
+	}
+
+	serverVersion, err := version.ParseGeneric(info.GitVersion)
+	if err != nil {
+		return fmt.Errorf("failed to parse server version %q: %w", info.GitVersion, err)
+	}
+
+	if !serverVersion.AtLeast(minUnsafeDeleteVersion) {
+		return fmt.Errorf(
+			"server %s does not support unsafe deletion of corrupt objects (requires %s+ with the AllowUnsafeMalformedObjectDeletion feature gate)",
+			info.GitVersion, minUnsafeDeleteVersion,
+		)
+	}
+	return nil
+}
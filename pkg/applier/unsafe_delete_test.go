@@ -0,0 +1,62 @@
+package applier
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/version"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/sozercan/unstuck/pkg/kube"
+	"github.com/sozercan/unstuck/pkg/types"
+)
+
+func unsafeDeleteAction() types.Action {
+	return types.Action{
+		Type:      types.ActionDelete,
+		Operation: "unsafe-delete",
+		Target:    types.ResourceRef{Kind: "Widget", APIVersion: "example.com/v1", Namespace: "apps", Name: "w1"},
+	}
+}
+
+func clientWithServerVersion(gitVersion string, objects ...runtime.Object) *kube.Client {
+	clientset := fake.NewSimpleClientset()
+	clientset.Discovery().(*fakediscovery.FakeDiscovery).FakedServerVersion = &version.Info{GitVersion: gitVersion}
+	return &kube.Client{
+		Clientset: clientset,
+		Discovery: clientset.Discovery(),
+		Dynamic:   dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), objects...),
+	}
+}
+
+func TestExecutor_UnsafeDelete(t *testing.T) {
+	widget := &unstructured.Unstructured{}
+	widget.SetAPIVersion("example.com/v1")
+	widget.SetKind("Widget")
+	widget.SetNamespace("apps")
+	widget.SetName("w1")
+
+	e := NewExecutor(clientWithServerVersion("v1.33.2", widget))
+	action := unsafeDeleteAction()
+
+	require.NoError(t, e.Execute(context.Background(), action))
+
+	deleted, err := e.Verify(context.Background(), action)
+	require.NoError(t, err)
+	assert.True(t, deleted)
+}
+
+func TestExecutor_UnsafeDelete_OldServer(t *testing.T) {
+	e := NewExecutor(clientWithServerVersion("v1.31.4"))
+
+	err := e.Execute(context.Background(), unsafeDeleteAction())
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "does not support unsafe deletion")
+}
@@ -21,9 +21,12 @@ type applyFlags struct {
 	scope           string
 	maxEscalation   int
 	allowForce      bool
-	dryRun          bool
+	dryRun          string
 	yes             bool
 	continueOnError bool
+	skipPreflight   bool
+	stuckAfter      time.Duration
+	sample          time.Duration
 }
 
 var appFlags applyFlags
@@ -48,14 +51,20 @@ Escalation Levels:
   L4 (Force Finalize) - Force-finalize namespace (requires --allow-force)
 
 Safety Features:
-  - Use --dry-run to preview actions without applying
+  - Use --dry-run to validate actions with the API server without applying
+    (--dry-run=client only prints them)
   - High-risk actions (L3+) require confirmation unless --yes is used
   - Use --max-escalation to limit the risk level
+  - Refuses to start if RBAC permissions for any action are missing
+    (override with --skip-preflight)
 
 Examples:
-  # Preview remediation actions (dry-run)
+  # Validate remediation actions with the API server (server-side dry-run)
   unstuck apply namespace cert-manager --dry-run
 
+  # Print remediation actions without contacting the API server
+  unstuck apply namespace cert-manager --dry-run=client
+
   # Apply with default settings (max L2, prompts for confirmation)
   unstuck apply namespace cert-manager
 
@@ -70,11 +79,15 @@ Examples:
 
 	cmd.Flags().StringVarP(&appFlags.namespace, "namespace", "n", "", "Namespace for resource targets")
 	cmd.Flags().StringVar(&appFlags.scope, "scope", "", "Label selector to limit scope")
+	cmd.Flags().DurationVar(&appFlags.stuckAfter, "stuck-after", detector.DefaultStuckAfter, "How long a blocker may be terminating before it is considered stuck")
+	cmd.Flags().DurationVar(&appFlags.sample, "sample", 0, "Sample deletion progress over this duration to tell slow from stuck (e.g. 30s)")
 	cmd.Flags().IntVar(&appFlags.maxEscalation, "max-escalation", 2, "Maximum escalation level (0-4)")
 	cmd.Flags().BoolVar(&appFlags.allowForce, "allow-force", false, "Allow Level 3-4 actions (CRD/namespace force)")
-	cmd.Flags().BoolVar(&appFlags.dryRun, "dry-run", false, "Preview actions without applying")
+	cmd.Flags().StringVar(&appFlags.dryRun, "dry-run", "none", "Preview actions without applying: none, client, or server (true and false are accepted as server and none)")
+	cmd.Flags().Lookup("dry-run").NoOptDefVal = "server"
 	cmd.Flags().BoolVarP(&appFlags.yes, "yes", "y", false, "Skip confirmation prompts")
 	cmd.Flags().BoolVar(&appFlags.continueOnError, "continue-on-error", false, "Continue executing after errors")
+	cmd.Flags().BoolVar(&appFlags.skipPreflight, "skip-preflight", false, "Apply even if RBAC preflight finds missing permissions")
 
 	return cmd
 }
@@ -90,6 +103,18 @@ func runApply(cmd *cobra.Command, args []string) error {
 	if appFlags.maxEscalation >= 3 && !appFlags.allowForce {
 		return fmt.Errorf("--allow-force is required for escalation level %d or higher", appFlags.maxEscalation)
 	}
+	// --dry-run used to be a bool, keep accepting its values
+	switch appFlags.dryRun {
+	case "true":
+		appFlags.dryRun = "server"
+	case "false":
+		appFlags.dryRun = "none"
+	}
+	switch appFlags.dryRun {
+	case "none", "client", "server":
+	default:
+		return fmt.Errorf("dry-run must be one of: none, client, server")
+	}
 
 	// Parse arguments
 	targetType := args[0]
@@ -114,6 +139,7 @@ func runApply(cmd *cobra.Command, args []string) error {
 			return fmt.Errorf("namespace name is required")
 		}
 		det := detector.NewNamespaceDetector(client)
+		det.SetStuckAfter(appFlags.stuckAfter)
 		report, err = det.Detect(ctx, targetName)
 
 	case "crd", "customresourcedefinition":
@@ -121,6 +147,7 @@ func runApply(cmd *cobra.Command, args []string) error {
 			return fmt.Errorf("CRD name is required")
 		}
 		det := detector.NewCRDDetector(client)
+		det.SetStuckAfter(appFlags.stuckAfter)
 		report, err = det.Detect(ctx, targetName)
 
 	default:
@@ -129,6 +156,7 @@ func runApply(cmd *cobra.Command, args []string) error {
 			return fmt.Errorf("resource name is required")
 		}
 		det := detector.NewResourceDetector(client)
+		det.SetStuckAfter(appFlags.stuckAfter)
 		report, err = det.Detect(ctx, targetType, targetName, appFlags.namespace)
 	}
 
@@ -136,6 +164,10 @@ func runApply(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	if err := sampleProgress(ctx, client, report, targetType, targetName, appFlags.namespace, appFlags.sample); err != nil {
+		return err
+	}
+
 	// Print diagnosis summary if verbose
 	if flags.Verbose {
 		outputFormat := GetOutputFormat()
@@ -168,10 +200,12 @@ func runApply(cmd *cobra.Command, args []string) error {
 
 	// Apply the plan
 	app := applier.NewApplier(client, applier.Options{
-		DryRun:          appFlags.dryRun,
+		DryRun:          appFlags.dryRun != "none",
+		ServerDryRun:    appFlags.dryRun == "server",
 		ContinueOnError: appFlags.continueOnError,
 		AutoConfirm:     appFlags.yes,
 		Verbose:         flags.Verbose,
+		SkipPreflight:   appFlags.skipPreflight,
 		Output:          os.Stdout,
 	})
 
@@ -3,6 +3,7 @@ package cli
 import (
 	"context"
 	"fmt"
+	"os"
 	"time"
 
 	"github.com/spf13/cobra"
@@ -14,8 +15,11 @@ import (
 )
 
 type diagnoseFlags struct {
-	namespace string
-	scope     string
+	namespace  string
+	scope      string
+	withLogs   bool
+	stuckAfter time.Duration
+	sample     time.Duration
 }
 
 var diagFlags diagnoseFlags
@@ -40,13 +44,19 @@ Examples:
   unstuck diagnose crd certificates.cert-manager.io
 
   # Diagnose a specific resource
-  unstuck diagnose certificate my-cert -n cert-manager`,
+  unstuck diagnose certificate my-cert -n cert-manager
+
+  # Include controller log lines that mention the blockers
+  unstuck diagnose namespace cert-manager --with-logs`,
 		Args: cobra.MinimumNArgs(1),
 		RunE: runDiagnose,
 	}
 
 	cmd.Flags().StringVarP(&diagFlags.namespace, "namespace", "n", "", "Namespace for resource targets")
 	cmd.Flags().StringVar(&diagFlags.scope, "scope", "", "Label selector to limit scope")
+	cmd.Flags().DurationVar(&diagFlags.stuckAfter, "stuck-after", detector.DefaultStuckAfter, "How long a blocker may be terminating before it is considered stuck")
+	cmd.Flags().DurationVar(&diagFlags.sample, "sample", 0, "Sample deletion progress over this duration to tell slow from stuck (e.g. 30s)")
+	cmd.Flags().BoolVar(&diagFlags.withLogs, "with-logs", false, "Fetch controller pod logs and show lines mentioning blockers")
 
 	return cmd
 }
@@ -78,6 +88,7 @@ func runDiagnose(cmd *cobra.Command, args []string) error {
 			return fmt.Errorf("namespace name is required")
 		}
 		det := detector.NewNamespaceDetector(client)
+		det.SetStuckAfter(diagFlags.stuckAfter)
 		report, err = det.Detect(ctx, targetName)
 
 	case "crd", "customresourcedefinition":
@@ -85,6 +96,7 @@ func runDiagnose(cmd *cobra.Command, args []string) error {
 			return fmt.Errorf("CRD name is required")
 		}
 		det := detector.NewCRDDetector(client)
+		det.SetStuckAfter(diagFlags.stuckAfter)
 		report, err = det.Detect(ctx, targetName)
 
 	default:
@@ -93,6 +105,7 @@ func runDiagnose(cmd *cobra.Command, args []string) error {
 			return fmt.Errorf("resource name is required")
 		}
 		det := detector.NewResourceDetector(client)
+		det.SetStuckAfter(diagFlags.stuckAfter)
 		report, err = det.Detect(ctx, targetType, targetName, diagFlags.namespace)
 	}
 
@@ -100,6 +113,18 @@ func runDiagnose(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	if err := sampleProgress(ctx, client, report, targetType, targetName, diagFlags.namespace, diagFlags.sample); err != nil {
+		return err
+	}
+
+	if diagFlags.withLogs && len(report.Controllers) > 0 {
+		excerpts, err := detector.NewLogDetector(client).ExcerptsFor(ctx, report.Controllers, report.Blockers)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: unable to read some controller logs: %v\n", err)
+		}
+		report.ControllerLogs = excerpts
+	}
+
 	// Output the report
 	outputFormat := GetOutputFormat()
 	printer := output.NewPrinter(outputFormat, flags.Verbose)
@@ -3,10 +3,12 @@ package cli
 import (
 	"context"
 	"fmt"
+	"os"
 	"time"
 
 	"github.com/spf13/cobra"
 
+	"github.com/sozercan/unstuck/pkg/applier"
 	"github.com/sozercan/unstuck/pkg/detector"
 	"github.com/sozercan/unstuck/pkg/kube"
 	"github.com/sozercan/unstuck/pkg/output"
@@ -19,6 +21,8 @@ type planFlags struct {
 	scope         string
 	maxEscalation int
 	allowForce    bool
+	stuckAfter    time.Duration
+	sample        time.Duration
 }
 
 var plFlags planFlags
@@ -58,6 +62,8 @@ Examples:
 
 	cmd.Flags().StringVarP(&plFlags.namespace, "namespace", "n", "", "Namespace for resource targets")
 	cmd.Flags().StringVar(&plFlags.scope, "scope", "", "Label selector to limit scope")
+	cmd.Flags().DurationVar(&plFlags.stuckAfter, "stuck-after", detector.DefaultStuckAfter, "How long a blocker may be terminating before it is considered stuck")
+	cmd.Flags().DurationVar(&plFlags.sample, "sample", 0, "Sample deletion progress over this duration to tell slow from stuck (e.g. 30s)")
 	cmd.Flags().IntVar(&plFlags.maxEscalation, "max-escalation", 2, "Maximum escalation level (0-4)")
 	cmd.Flags().BoolVar(&plFlags.allowForce, "allow-force", false, "Allow Level 3-4 actions (CRD/namespace force)")
 
@@ -99,6 +105,7 @@ func runPlan(cmd *cobra.Command, args []string) error {
 			return fmt.Errorf("namespace name is required")
 		}
 		det := detector.NewNamespaceDetector(client)
+		det.SetStuckAfter(plFlags.stuckAfter)
 		report, err = det.Detect(ctx, targetName)
 
 	case "crd", "customresourcedefinition":
@@ -106,6 +113,7 @@ func runPlan(cmd *cobra.Command, args []string) error {
 			return fmt.Errorf("CRD name is required")
 		}
 		det := detector.NewCRDDetector(client)
+		det.SetStuckAfter(plFlags.stuckAfter)
 		report, err = det.Detect(ctx, targetName)
 
 	default:
@@ -114,6 +122,7 @@ func runPlan(cmd *cobra.Command, args []string) error {
 			return fmt.Errorf("resource name is required")
 		}
 		det := detector.NewResourceDetector(client)
+		det.SetStuckAfter(plFlags.stuckAfter)
 		report, err = det.Detect(ctx, targetType, targetName, plFlags.namespace)
 	}
 
@@ -121,6 +130,10 @@ func runPlan(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	if err := sampleProgress(ctx, client, report, targetType, targetName, plFlags.namespace, plFlags.sample); err != nil {
+		return err
+	}
+
 	// Generate the plan
 	p := planner.NewPlanner(planner.Options{
 		MaxEscalation: types.EscalationLevel(plFlags.maxEscalation),
@@ -132,6 +145,14 @@ func runPlan(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to generate plan: %w", err)
 	}
 
+	// Check RBAC for every action so missing permissions show up before apply
+	checks, err := applier.NewExecutor(client).CheckPermissions(ctx, plan)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: unable to verify permissions: %v\n", err)
+	} else {
+		plan.Permissions = checks
+	}
+
 	// Output the plan
 	outputFormat := GetOutputFormat()
 	printer := output.NewPrinter(outputFormat, flags.Verbose)
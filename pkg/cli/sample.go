@@ -0,0 +1,43 @@
+package cli
+
+import (
+	"context"
+	"time"
+
+	"github.com/sozercan/unstuck/pkg/detector"
+	"github.com/sozercan/unstuck/pkg/kube"
+	"github.com/sozercan/unstuck/pkg/types"
+)
+
+// sampleProgress measures how fast the target is being deleted and attaches the result to the report
+func sampleProgress(ctx context.Context, client *kube.Client, report *types.DiagnosisReport, targetType, targetName, namespace string, duration time.Duration) error {
+	if duration <= 0 || !report.IsTerminating() {
+		return nil
+	}
+
+	var snapshot detector.SnapshotFunc
+	switch targetType {
+	case "namespace", "ns":
+		det := detector.NewNamespaceDetector(client)
+		snapshot = func(ctx context.Context) (types.ProgressSample, error) {
+			return det.Snapshot(ctx, targetName)
+		}
+	case "crd", "customresourcedefinition":
+		det := detector.NewCRDDetector(client)
+		snapshot = func(ctx context.Context) (types.ProgressSample, error) {
+			return det.Snapshot(ctx, targetName)
+		}
+	default:
+		det := detector.NewResourceDetector(client)
+		snapshot = func(ctx context.Context) (types.ProgressSample, error) {
+			return det.Snapshot(ctx, targetType, targetName, namespace)
+		}
+	}
+
+	progress, err := detector.SampleProgress(ctx, snapshot, duration)
+	if err != nil {
+		return err
+	}
+	detector.ApplyProgress(report, progress)
+	return nil
+}
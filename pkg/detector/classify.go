@@ -0,0 +1,120 @@
+package detector
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sozercan/unstuck/pkg/types"
+)
+
+// DefaultStuckAfter is how long a blocker may be terminating before it is classified Stuck
+const DefaultStuckAfter = 5 * time.Minute
+
+// classifyBlockers sets the status and root cause of every blocker in the report.
+// It must run after controllers, GC issues and events have been attached.
+func classifyBlockers(report *types.DiagnosisReport, stuckAfter time.Duration) {
+	for i := range report.Blockers {
+		b := &report.Blockers[i]
+		b.Status, b.RootCause = classifyBlocker(*b, report.Controllers, report.GCIssuesFor(b.ResourceRef), report.Events, stuckAfter)
+	}
+}
+
+func classifyBlocker(b types.Blocker, controllers []types.ControllerStatus, gcIssues []types.GCIssue, events []types.EventSummary, stuckAfter time.Duration) (types.BlockerStatus, string) {
+	if !b.IsTerminating() {
+		return types.BlockerStatusPending, "Not deleted yet; deletion will reach it with its parent"
+	}
+
+	ctrl := controllerForBlocker(b, controllers)
+	if b.Age < stuckAfter {
+		if ctrl != nil && ctrl.IsReconciling() {
+			return types.BlockerStatusHealthy, fmt.Sprintf("Being cleaned up by %s/%s/%s", ctrl.Kind, ctrl.Namespace, ctrl.Name)
+		}
+		return types.BlockerStatusPending, fmt.Sprintf("Deleted %s ago, within the %s stuck threshold", formatDuration(b.Age), stuckAfter)
+	}
+
+	// No operator handles GC finalizers, so a missing controller is not the cause
+	if len(gcIssues) > 0 {
+		return types.BlockerStatusStuck, gcIssues[0].Message
+	}
+
+	if cause := eventRootCause(eventsRegarding(events, b.ResourceRef)); cause != "" {
+		return types.BlockerStatusStuck, cause
+	}
+
+	finalizers := strings.Join(b.Finalizers, ", ")
+	switch {
+	case len(b.Finalizers) == 0:
+		return types.BlockerStatusStuck, "Terminating without finalizers; likely waiting for dependents"
+	case ctrl == nil:
+		return types.BlockerStatusStuck, fmt.Sprintf("No controller found for %s", finalizers)
+	case ctrl.IsReconciling():
+		return types.BlockerStatusStuck, fmt.Sprintf("%s/%s/%s is running but has not removed %s", ctrl.Kind, ctrl.Namespace, ctrl.Name, finalizers)
+	default:
+		return types.BlockerStatusStuck, fmt.Sprintf("%s/%s/%s is not reconciling: %s", ctrl.Kind, ctrl.Namespace, ctrl.Name, notReconcilingReason(*ctrl))
+	}
+}
+
+// controllerForBlocker returns the controller handling one of the blocker's finalizers,
+// preferring one that is reconciling
+func controllerForBlocker(b types.Blocker, controllers []types.ControllerStatus) *types.ControllerStatus {
+	var match *types.ControllerStatus
+	for i := range controllers {
+		c := &controllers[i]
+		handles := false
+		for _, f := range b.Finalizers {
+			if containsString(c.Finalizers, f) {
+				handles = true
+				break
+			}
+		}
+		if !handles {
+			continue
+		}
+		if c.IsReconciling() {
+			return c
+		}
+		if match == nil {
+			match = c
+		}
+	}
+	return match
+}
+
+func eventsRegarding(events []types.EventSummary, ref types.ResourceRef) []types.EventSummary {
+	var matched []types.EventSummary
+	for _, e := range events {
+		if e.Regarding.Kind == ref.Kind && e.Regarding.Namespace == ref.Namespace && e.Regarding.Name == ref.Name {
+			matched = append(matched, e)
+		}
+	}
+	return matched
+}
+
+func notReconcilingReason(c types.ControllerStatus) string {
+	switch {
+	case c.Message != "":
+		return c.Message
+	case c.Liveness == types.ControllerStale:
+		return "leader-election lease is stale"
+	default:
+		return "not ready"
+	}
+}
+
+// stuckThresholdRecommendations explains blockers that were left out because they are not stuck yet
+func stuckThresholdRecommendations(blockers []types.Blocker, stuckAfter time.Duration) []string {
+	notStuck := 0
+	for _, b := range blockers {
+		if !b.IsStuck() {
+			notStuck++
+		}
+	}
+	if notStuck == 0 {
+		return nil
+	}
+	return []string{fmt.Sprintf(
+		"%d blocker(s) are not stuck yet (not deleted, or deleted less than %s ago). Plans only remove finalizers from Stuck blockers; adjust with --stuck-after.",
+		notStuck, stuckAfter,
+	)}
+}
@@ -0,0 +1,122 @@
+package detector
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/sozercan/unstuck/pkg/types"
+)
+
+func TestClassifyBlocker(t *testing.T) {
+	deleted := metav1.NewTime(time.Now())
+	ref := types.ResourceRef{Kind: "Widget", Namespace: "apps", Name: "w1"}
+	blocker := func(age time.Duration) types.Blocker {
+		return types.Blocker{
+			ResourceRef:       ref,
+			Finalizers:        []string{"example.com/cleanup"},
+			DeletionTimestamp: &deleted,
+			Age:               age,
+		}
+	}
+	running := types.ControllerStatus{
+		Kind: "Deployment", Namespace: "ops", Name: "widget-operator",
+		Finalizers: []string{"example.com/cleanup"}, Available: true, Ready: true,
+	}
+	scaledDown := types.ControllerStatus{
+		Kind: "Deployment", Namespace: "ops", Name: "widget-operator",
+		Finalizers: []string{"example.com/cleanup"}, Message: "scaled to zero replicas",
+	}
+
+	tests := []struct {
+		name        string
+		blocker     types.Blocker
+		controllers []types.ControllerStatus
+		gcIssues    []types.GCIssue
+		events      []types.EventSummary
+		status      types.BlockerStatus
+		rootCause   string
+	}{
+		{
+			name:      "not deleted",
+			blocker:   types.Blocker{ResourceRef: ref, Finalizers: []string{"example.com/cleanup"}},
+			status:    types.BlockerStatusPending,
+			rootCause: "Not deleted yet; deletion will reach it with its parent",
+		},
+		{
+			name:        "recent with reconciling controller",
+			blocker:     blocker(time.Minute),
+			controllers: []types.ControllerStatus{running},
+			status:      types.BlockerStatusHealthy,
+			rootCause:   "Being cleaned up by Deployment/ops/widget-operator",
+		},
+		{
+			name:      "recent without controller",
+			blocker:   blocker(30 * time.Second),
+			status:    types.BlockerStatusPending,
+			rootCause: "Deleted 30s ago, within the 5m0s stuck threshold",
+		},
+		{
+			name:      "old without controller",
+			blocker:   blocker(time.Hour),
+			status:    types.BlockerStatusStuck,
+			rootCause: "No controller found for example.com/cleanup",
+		},
+		{
+			name:        "old with running controller",
+			blocker:     blocker(time.Hour),
+			controllers: []types.ControllerStatus{running},
+			status:      types.BlockerStatusStuck,
+			rootCause:   "Deployment/ops/widget-operator is running but has not removed example.com/cleanup",
+		},
+		{
+			name:        "old with scaled down controller",
+			blocker:     blocker(time.Hour),
+			controllers: []types.ControllerStatus{scaledDown},
+			status:      types.BlockerStatusStuck,
+			rootCause:   "Deployment/ops/widget-operator is not reconciling: scaled to zero replicas",
+		},
+		{
+			name:      "old with GC finalizer",
+			blocker:   blocker(time.Hour),
+			gcIssues:  []types.GCIssue{{Type: types.GCIssueOrphanFinalizer, Object: ref, Finalizer: "orphan", Message: "Garbage collector has not orphaned the dependents"}},
+			status:    types.BlockerStatusStuck,
+			rootCause: "Garbage collector has not orphaned the dependents",
+		},
+		{
+			name:        "old with classified event",
+			blocker:     blocker(time.Hour),
+			controllers: []types.ControllerStatus{running},
+			events: []types.EventSummary{
+				{Regarding: ref, Reason: "CleanupFailed", Note: "AccessDenied", Classification: "cloud_provider_error"},
+			},
+			status:    types.BlockerStatusStuck,
+			rootCause: "Cloud provider failure on Widget/apps/w1 - CleanupFailed: AccessDenied",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, rootCause := classifyBlocker(tt.blocker, tt.controllers, tt.gcIssues, tt.events, DefaultStuckAfter)
+			assert.Equal(t, tt.status, status)
+			assert.Equal(t, tt.rootCause, rootCause)
+		})
+	}
+}
+
+func TestStuckThresholdRecommendations(t *testing.T) {
+	blockers := []types.Blocker{
+		{Status: types.BlockerStatusStuck},
+		{Status: types.BlockerStatusPending},
+		{Status: types.BlockerStatusHealthy},
+	}
+
+	recs := stuckThresholdRecommendations(blockers, 10*time.Minute)
+
+	assert.Len(t, recs, 1)
+	assert.Contains(t, recs[0], "2 blocker(s) are not stuck yet")
+	assert.Contains(t, recs[0], "10m0s")
+	assert.Empty(t, stuckThresholdRecommendations(blockers[:1], time.Minute))
+}
@@ -0,0 +1,203 @@
+package detector
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/sozercan/unstuck/pkg/kube"
+	"github.com/sozercan/unstuck/pkg/types"
+)
+
+const (
+	// crossplaneFinalizer is set on every Crossplane managed resource, whatever its API group
+	crossplaneFinalizer          = "finalizer.managedresource.crossplane.io"
+	crossplaneExternalAnnotation = "crossplane.io/external-name"
+	// crossplanePackageLabel names the Provider a ProviderRevision belongs to
+	crossplanePackageLabel = "pkg.crossplane.io/package"
+
+	ackGroupSuffix = ".services.k8s.aws"
+	// ackDeletionPolicyAnnotation set to retain keeps the AWS resource when the object is deleted
+	ackDeletionPolicyAnnotation = "services.k8s.aws/deletion-policy"
+
+	configConnectorGroupSuffix = ".cnrm.cloud.google.com"
+	// configConnectorDeletionPolicyAnnotation set to abandon keeps the GCP resource when the object is deleted
+	configConnectorDeletionPolicyAnnotation = "cnrm.cloud.google.com/deletion-policy"
+)
+
+// crossplaneProviderRevisionGVR is the cluster-scoped revision of a Crossplane Provider package
+var crossplaneProviderRevisionGVR = schema.GroupVersionResource{Group: "pkg.crossplane.io", Version: "v1", Resource: "providerrevisions"}
+
+// CloudResourceDetector reads the cloud resource behind Crossplane, ACK and Config Connector
+// objects and whether the provider that deletes it is healthy
+type CloudResourceDetector struct {
+	client *kube.Client
+	mapper *resourceMapper
+}
+
+// NewCloudResourceDetector creates a new cloud resource detector
+func NewCloudResourceDetector(client *kube.Client) *CloudResourceDetector {
+	return &CloudResourceDetector{client: client, mapper: newResourceMapper(client)}
+}
+
+// Analyze sets CloudResource on blockers that belong to a cloud resource family. Objects that
+// cannot be read are recorded from their API group and finalizers alone.
+func (d *CloudResourceDetector) Analyze(ctx context.Context, blockers []types.Blocker, controllers []types.ControllerStatus) {
+	for i := range blockers {
+		b := &blockers[i]
+		family, finalizer := cloudResourceFamily(b)
+		if family == "" {
+			continue
+		}
+
+		cloud := &types.CloudResource{Family: family, Finalizer: finalizer, ProviderState: types.ExternalControllerUnknown}
+		gvr, _, err := d.mapper.resourceFor(b.APIVersion, b.Kind)
+		if err == nil && !gvr.Empty() {
+			if obj, err := d.client.Dynamic.Resource(gvr).Namespace(b.Namespace).Get(ctx, b.Name, metav1.GetOptions{}); err == nil {
+				readCloudResource(cloud, obj)
+			}
+			if family == types.CloudCrossplane {
+				d.crossplaneProvider(ctx, cloud, gvr)
+			}
+		}
+
+		// Controllers found through RBAC are used for ACK and Config Connector, and for Crossplane
+		// providers whose package could not be found
+		if cloud.Provider == "" {
+			for _, c := range controllers {
+				if !containsString(c.Finalizers, finalizer) {
+					continue
+				}
+				cloud.Provider = c.Namespace + "/" + c.Name
+				cloud.ProviderState = types.ExternalControllerNotRunning
+				if c.IsReconciling() {
+					cloud.ProviderState = types.ExternalControllerRunning
+				}
+				break
+			}
+		}
+
+		switch {
+		case cloud.Retained:
+			cloud.Message = "deletion policy keeps the cloud resource, so removing the finalizer orphans nothing"
+		case cloud.ProviderState == types.ExternalControllerRunning:
+			cloud.Message = fmt.Sprintf("%s is running; check the object's conditions for the cloud API error", cloud.Provider)
+		case cloud.ProviderState == types.ExternalControllerNotRunning:
+			cloud.Message = fmt.Sprintf("%s is not healthy, so nothing deletes the cloud resource", cloud.Provider)
+		default:
+			cloud.Message = "no provider found for the resource"
+		}
+		b.CloudResource = cloud
+	}
+}
+
+// cloudResourceFamily returns the family of a blocker from its API group, and the finalizer that
+// deletes its cloud resource
+func cloudResourceFamily(b *types.Blocker) (types.CloudResourceFamily, string) {
+	gv, _ := schema.ParseGroupVersion(b.APIVersion)
+	switch {
+	case strings.HasSuffix(gv.Group, ackGroupSuffix):
+		for _, f := range b.Finalizers {
+			if strings.Contains(f, ackGroupSuffix) {
+				return types.CloudACK, f
+			}
+		}
+	case strings.HasSuffix(gv.Group, configConnectorGroupSuffix):
+		for _, f := range b.Finalizers {
+			if strings.HasPrefix(f, "cnrm.cloud.google.com/") {
+				return types.CloudConfigConnector, f
+			}
+		}
+	case containsString(b.Finalizers, crossplaneFinalizer):
+		// Provider groups vary (s3.aws.upbound.io, storage.gcp.upbound.io, ...), the finalizer does not
+		return types.CloudCrossplane, crossplaneFinalizer
+	}
+	return "", ""
+}
+
+// readCloudResource reads the external name, identifiers and deletion policy of an object
+func readCloudResource(cloud *types.CloudResource, obj *unstructured.Unstructured) {
+	annotations := obj.GetAnnotations()
+	var paths [][]string
+	switch cloud.Family {
+	case types.CloudCrossplane:
+		cloud.ExternalName = annotations[crossplaneExternalAnnotation]
+		paths = [][]string{{"status", "atProvider", "arn"}, {"status", "atProvider", "id"}, {"status", "atProvider", "selfLink"}}
+		policy, _, _ := unstructured.NestedString(obj.Object, "spec", "deletionPolicy")
+		cloud.Retained = policy == "Orphan"
+		// Management policies without Delete never delete the external resource
+		if policies, found, _ := unstructured.NestedStringSlice(obj.Object, "spec", "managementPolicies"); found && len(policies) > 0 {
+			if !containsString(policies, "*") && !containsString(policies, "Delete") {
+				cloud.Retained = true
+			}
+		}
+	case types.CloudACK:
+		paths = [][]string{{"status", "ackResourceMetadata", "arn"}}
+		cloud.Retained = strings.EqualFold(annotations[ackDeletionPolicyAnnotation], "retain")
+	case types.CloudConfigConnector:
+		cloud.ExternalName, _, _ = unstructured.NestedString(obj.Object, "spec", "resourceID")
+		paths = [][]string{{"status", "selfLink"}, {"status", "externalRef"}}
+		cloud.Retained = strings.EqualFold(annotations[configConnectorDeletionPolicyAnnotation], "abandon")
+	}
+
+	for _, path := range paths {
+		if id, _, _ := unstructured.NestedString(obj.Object, path...); id != "" && id != cloud.ExternalName && !containsString(cloud.Identifiers, id) {
+			cloud.Identifiers = append(cloud.Identifiers, id)
+		}
+	}
+}
+
+// crossplaneProvider finds the Provider of a managed resource through the ProviderRevision that
+// owns its CRD, and reads the revision's Healthy condition
+func (d *CloudResourceDetector) crossplaneProvider(ctx context.Context, cloud *types.CloudResource, gvr schema.GroupVersionResource) {
+	crd, err := d.client.ApiExtensions.ApiextensionsV1().CustomResourceDefinitions().Get(ctx, gvr.GroupResource().String(), metav1.GetOptions{})
+	if err != nil {
+		return
+	}
+	for _, owner := range crd.OwnerReferences {
+		if owner.Kind != "ProviderRevision" {
+			continue
+		}
+		revision, err := d.client.Dynamic.Resource(crossplaneProviderRevisionGVR).Get(ctx, owner.Name, metav1.GetOptions{})
+		if err != nil {
+			return
+		}
+		cloud.Provider = "Provider/" + revision.GetLabels()[crossplanePackageLabel]
+		cloud.ProviderState = types.ExternalControllerNotRunning
+		conditions, _, _ := unstructured.NestedSlice(revision.Object, "status", "conditions")
+		for _, c := range conditions {
+			if m, ok := c.(map[string]interface{}); ok && m["type"] == "Healthy" && m["status"] == string(metav1.ConditionTrue) {
+				cloud.ProviderState = types.ExternalControllerRunning
+			}
+		}
+		return
+	}
+}
+
+// analyzeCloudResources attaches the cloud resources managed through Crossplane, ACK and Config
+// Connector objects to the blockers
+func analyzeCloudResources(ctx context.Context, client *kube.Client, report *types.DiagnosisReport) {
+	NewCloudResourceDetector(client).Analyze(ctx, report.Blockers, report.Controllers)
+}
+
+// cloudResourceRecommendations warns that removing the finalizers of cloud-backed objects orphans
+// the infrastructure behind them
+func cloudResourceRecommendations(report *types.DiagnosisReport) []string {
+	var leaking []string
+	for _, b := range report.CloudResourceBlockers() {
+		if b.CloudResource.Leaks() {
+			leaking = append(leaking, fmt.Sprintf("%s (%s)", b.ResourceRef.String(), b.CloudResource.Description()))
+		}
+	}
+	if len(leaking) == 0 {
+		return nil
+	}
+	return []string{fmt.Sprintf(
+		"%d blocker(s) manage cloud resources that are orphaned if their finalizers are removed: %s. Restore the provider so it can delete them; removing the finalizers requires --allow-force.",
+		len(leaking), strings.Join(leaking, "; "),
+	)}
+}
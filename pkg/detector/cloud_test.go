@@ -0,0 +1,128 @@
+package detector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apiextensionsfake "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/fake"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/sozercan/unstuck/pkg/kube"
+	"github.com/sozercan/unstuck/pkg/types"
+)
+
+func TestCloudResourceDetector_Analyze(t *testing.T) {
+	bucket := gitOpsObject("s3.aws.upbound.io/v1beta1", "Bucket", "", "assets", map[string]interface{}{
+		"deletionPolicy": "Delete",
+	}, map[string]interface{}{
+		"atProvider": map[string]interface{}{"arn": "arn:aws:s3:::acme-assets", "id": "acme-assets"},
+	})
+	bucket.SetAnnotations(map[string]string{crossplaneExternalAnnotation: "acme-assets"})
+	table := gitOpsObject("dynamodb.services.k8s.aws/v1alpha1", "Table", "apps", "orders", map[string]interface{}{}, map[string]interface{}{
+		"ackResourceMetadata": map[string]interface{}{"arn": "arn:aws:dynamodb:us-east-1:123456789012:table/orders"},
+	})
+	table.SetAnnotations(map[string]string{ackDeletionPolicyAnnotation: "retain"})
+	sqlInstance := gitOpsObject("sql.cnrm.cloud.google.com/v1beta1", "SQLInstance", "apps", "db", map[string]interface{}{
+		"resourceID": "acme-db",
+	}, map[string]interface{}{
+		"selfLink": "https://sqladmin.googleapis.com/sql/v1beta4/projects/acme/instances/acme-db",
+	})
+	revision := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "pkg.crossplane.io/v1",
+		"kind":       "ProviderRevision",
+		"metadata": map[string]interface{}{
+			"name":   "provider-aws-s3-abc123",
+			"labels": map[string]interface{}{crossplanePackageLabel: "provider-aws-s3"},
+		},
+		"status": map[string]interface{}{"conditions": []interface{}{
+			map[string]interface{}{"type": "Healthy", "status": "False", "reason": "UnhealthyPackageRevision"},
+		}},
+	}}
+
+	dynamic := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		{Group: "s3.aws.upbound.io", Version: "v1beta1", Resource: "buckets"}:              "BucketList",
+		{Group: "dynamodb.services.k8s.aws", Version: "v1alpha1", Resource: "tables"}:      "TableList",
+		{Group: "sql.cnrm.cloud.google.com", Version: "v1beta1", Resource: "sqlinstances"}: "SQLInstanceList",
+		{Group: "pkg.crossplane.io", Version: "v1", Resource: "providerrevisions"}:         "ProviderRevisionList",
+	}, bucket, table, sqlInstance, revision)
+
+	clientset := fake.NewSimpleClientset()
+	clientset.Discovery().(*fakediscovery.FakeDiscovery).Resources = []*metav1.APIResourceList{
+		{GroupVersion: "s3.aws.upbound.io/v1beta1", APIResources: []metav1.APIResource{{Name: "buckets", Kind: "Bucket"}}},
+		{GroupVersion: "dynamodb.services.k8s.aws/v1alpha1", APIResources: []metav1.APIResource{{Name: "tables", Kind: "Table", Namespaced: true}}},
+		{GroupVersion: "sql.cnrm.cloud.google.com/v1beta1", APIResources: []metav1.APIResource{{Name: "sqlinstances", Kind: "SQLInstance", Namespaced: true}}},
+	}
+	crd := &apiextensionsv1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{
+		Name:            "buckets.s3.aws.upbound.io",
+		OwnerReferences: []metav1.OwnerReference{{APIVersion: "pkg.crossplane.io/v1", Kind: "ProviderRevision", Name: "provider-aws-s3-abc123"}},
+	}}
+	client := &kube.Client{
+		Clientset:     clientset,
+		Discovery:     clientset.Discovery(),
+		Dynamic:       dynamic,
+		ApiExtensions: apiextensionsfake.NewSimpleClientset(crd),
+	}
+
+	blocker := func(apiVersion, kind, namespace, name, finalizer string) types.Blocker {
+		return types.Blocker{
+			ResourceRef: types.ResourceRef{APIVersion: apiVersion, Kind: kind, Namespace: namespace, Name: name},
+			Finalizers:  []string{finalizer},
+		}
+	}
+	blockers := []types.Blocker{
+		blocker("s3.aws.upbound.io/v1beta1", "Bucket", "", "assets", crossplaneFinalizer),
+		blocker("dynamodb.services.k8s.aws/v1alpha1", "Table", "apps", "orders", "finalizers.dynamodb.services.k8s.aws/Table"),
+		blocker("sql.cnrm.cloud.google.com/v1beta1", "SQLInstance", "apps", "db", "cnrm.cloud.google.com/finalizer"),
+		blocker("example.com/v1", "Widget", "apps", "w", "example.com/cleanup"),
+	}
+	controllers := []types.ControllerStatus{{
+		Name:       "cnrm-controller-manager",
+		Namespace:  "cnrm-system",
+		Kind:       "StatefulSet",
+		Finalizers: []string{"cnrm.cloud.google.com/finalizer"},
+		Available:  true,
+		Ready:      true,
+	}}
+
+	NewCloudResourceDetector(client).Analyze(context.Background(), blockers, controllers)
+
+	b := blockers[0].CloudResource
+	require.NotNil(t, b)
+	assert.Equal(t, types.CloudCrossplane, b.Family)
+	assert.Equal(t, "acme-assets", b.ExternalName)
+	assert.Equal(t, []string{"arn:aws:s3:::acme-assets"}, b.Identifiers, "the id repeats the external name")
+	assert.Equal(t, "Provider/provider-aws-s3", b.Provider)
+	assert.Equal(t, types.ExternalControllerNotRunning, b.ProviderState)
+	assert.True(t, b.Leaks())
+
+	tbl := blockers[1].CloudResource
+	require.NotNil(t, tbl)
+	assert.Equal(t, types.CloudACK, tbl.Family)
+	assert.Equal(t, "finalizers.dynamodb.services.k8s.aws/Table", tbl.Finalizer)
+	assert.True(t, tbl.Retained)
+	assert.False(t, tbl.Leaks())
+
+	sql := blockers[2].CloudResource
+	require.NotNil(t, sql)
+	assert.Equal(t, types.CloudConfigConnector, sql.Family)
+	assert.Equal(t, "acme-db", sql.ExternalName)
+	assert.Equal(t, "cnrm-system/cnrm-controller-manager", sql.Provider)
+	assert.Equal(t, types.ExternalControllerRunning, sql.ProviderState)
+
+	assert.Nil(t, blockers[3].CloudResource)
+
+	recs := cloudResourceRecommendations(&types.DiagnosisReport{Blockers: blockers})
+	require.Len(t, recs, 1)
+	assert.Contains(t, recs[0], "2 blocker(s)")
+	assert.Contains(t, recs[0], "arn:aws:s3:::acme-assets")
+	assert.NotContains(t, recs[0], "orders")
+}
@@ -0,0 +1,115 @@
+package detector
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/sozercan/unstuck/pkg/types"
+)
+
+var (
+	// "Some resources are remaining: certificates.cert-manager.io has 3 resource instances, pods has 1 resource instances"
+	remainingResourcePattern = regexp.MustCompile(`([a-zA-Z0-9.-]+) has (\d+) resource instances`)
+	// "Some content in the namespace has finalizers remaining: cert-manager.io/finalizer in 3 resource instances"
+	remainingFinalizerPattern = regexp.MustCompile(`([a-zA-Z0-9./_-]+) in (\d+) resource instances`)
+	// "Failed to delete all resource types, 1 remaining: <aggregated errors>"
+	contentFailurePrefix = regexp.MustCompile(`^Failed to delete all resource types, \d+ remaining: `)
+)
+
+// parseRemainingContent extracts per-resource and per-finalizer counts and deletion errors
+// from the namespace deletion conditions. Returns nil if no condition carries content.
+func parseRemainingContent(conditions []corev1.NamespaceCondition) *types.RemainingContent {
+	content := &types.RemainingContent{}
+
+	for _, c := range conditions {
+		if c.Status != corev1.ConditionTrue {
+			continue
+		}
+
+		switch c.Type {
+		case corev1.NamespaceContentRemaining:
+			content.Resources = parseRemainingCounts(remainingResourcePattern, c.Message)
+
+		case corev1.NamespaceFinalizersRemaining:
+			content.Finalizers = parseRemainingCounts(remainingFinalizerPattern, c.Message)
+
+		case corev1.NamespaceDeletionContentFailure:
+			msg := contentFailurePrefix.ReplaceAllString(c.Message, "")
+			msg = strings.TrimSuffix(strings.TrimPrefix(msg, "["), "]")
+			content.DeletionErrors = append(content.DeletionErrors, msg)
+
+		case corev1.NamespaceDeletionGVParsingFailure:
+			content.ParsingErrors = append(content.ParsingErrors, c.Message)
+		}
+	}
+
+	if len(content.Resources) == 0 && len(content.Finalizers) == 0 &&
+		len(content.DeletionErrors) == 0 && len(content.ParsingErrors) == 0 {
+		return nil
+	}
+	return content
+}
+
+func parseRemainingCounts(pattern *regexp.Regexp, message string) []types.RemainingCount {
+	var counts []types.RemainingCount
+	for _, m := range pattern.FindAllStringSubmatch(message, -1) {
+		n, err := strconv.Atoi(m[2])
+		if err != nil {
+			continue
+		}
+		counts = append(counts, types.RemainingCount{Name: m[1], Reported: n})
+	}
+	return counts
+}
+
+// crossCheckContent fills in how many of the reported objects unstuck can see and flags the rest.
+// visible maps group-resources that were listed successfully to their object counts.
+func crossCheckContent(content *types.RemainingContent, visible map[string]int, blockers []types.Blocker) {
+	if content == nil {
+		return
+	}
+
+	for i := range content.Resources {
+		r := &content.Resources[i]
+		found, listed := visible[r.Name]
+		r.Visible = found
+		switch {
+		case !listed:
+			content.Mismatches = append(content.Mismatches, fmt.Sprintf(
+				"%s: namespace controller reports %d instance(s) but the resource could not be listed (missing RBAC or unavailable API)",
+				r.Name, r.Reported))
+		case found < r.Reported:
+			content.Mismatches = append(content.Mismatches, fmt.Sprintf(
+				"%s: namespace controller reports %d instance(s) but only %d are visible",
+				r.Name, r.Reported, found))
+		}
+	}
+
+	finalizerCounts := make(map[string]int)
+	for _, b := range blockers {
+		for _, f := range b.Finalizers {
+			finalizerCounts[f]++
+		}
+	}
+	for i := range content.Finalizers {
+		f := &content.Finalizers[i]
+		f.Visible = finalizerCounts[f.Name]
+		if f.Visible < f.Reported {
+			content.Mismatches = append(content.Mismatches, fmt.Sprintf(
+				"%s: namespace controller reports %d object(s) with this finalizer but only %d are visible",
+				f.Name, f.Reported, f.Visible))
+		}
+	}
+}
+
+// groupResourceString formats a group-resource the way the namespace controller does
+func groupResourceString(group, resource string) string {
+	if group == "" {
+		return resource
+	}
+	return resource + "." + group
+}
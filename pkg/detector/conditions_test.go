@@ -0,0 +1,102 @@
+package detector
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/sozercan/unstuck/pkg/types"
+)
+
+func TestParseRemainingContent(t *testing.T) {
+	conditions := []corev1.NamespaceCondition{
+		{
+			Type:    corev1.NamespaceDeletionDiscoveryFailure,
+			Status:  corev1.ConditionFalse,
+			Message: "All resources successfully discovered",
+		},
+		{
+			Type:    corev1.NamespaceDeletionGVParsingFailure,
+			Status:  corev1.ConditionTrue,
+			Message: "unexpected GroupVersion string: /v1/extra",
+		},
+		{
+			Type:    corev1.NamespaceDeletionContentFailure,
+			Status:  corev1.ConditionTrue,
+			Message: `Failed to delete all resource types, 1 remaining: Internal error occurred: failed calling webhook "validate.example.com"`,
+		},
+		{
+			Type:    corev1.NamespaceContentRemaining,
+			Status:  corev1.ConditionTrue,
+			Message: "Some resources are remaining: certificates.cert-manager.io has 3 resource instances, pods has 1 resource instances",
+		},
+		{
+			Type:    corev1.NamespaceFinalizersRemaining,
+			Status:  corev1.ConditionTrue,
+			Message: "Some content in the namespace has finalizers remaining: finalizer.cert-manager.io/cleanup in 3 resource instances",
+		},
+	}
+
+	content := parseRemainingContent(conditions)
+
+	require.NotNil(t, content)
+	assert.Equal(t, []types.RemainingCount{
+		{Name: "certificates.cert-manager.io", Reported: 3},
+		{Name: "pods", Reported: 1},
+	}, content.Resources)
+	assert.Equal(t, []types.RemainingCount{
+		{Name: "finalizer.cert-manager.io/cleanup", Reported: 3},
+	}, content.Finalizers)
+	assert.Equal(t, []string{`Internal error occurred: failed calling webhook "validate.example.com"`}, content.DeletionErrors)
+	assert.Equal(t, []string{"unexpected GroupVersion string: /v1/extra"}, content.ParsingErrors)
+}
+
+func TestParseRemainingContent_NoContent(t *testing.T) {
+	conditions := []corev1.NamespaceCondition{
+		{Type: corev1.NamespaceContentRemaining, Status: corev1.ConditionFalse, Message: "All content successfully removed"},
+	}
+
+	assert.Nil(t, parseRemainingContent(conditions))
+}
+
+func TestCrossCheckContent(t *testing.T) {
+	content := &types.RemainingContent{
+		Resources: []types.RemainingCount{
+			{Name: "certificates.cert-manager.io", Reported: 3},
+			{Name: "pods", Reported: 1},
+			{Name: "podmetrics.metrics.k8s.io", Reported: 2},
+		},
+		Finalizers: []types.RemainingCount{
+			{Name: "finalizer.cert-manager.io/cleanup", Reported: 3},
+		},
+	}
+	visible := map[string]int{
+		"certificates.cert-manager.io": 1,
+		"pods":                         1,
+	}
+	blockers := []types.Blocker{
+		{Finalizers: []string{"finalizer.cert-manager.io/cleanup"}},
+	}
+
+	crossCheckContent(content, visible, blockers)
+
+	assert.Equal(t, 1, content.Resources[0].Visible)
+	assert.Equal(t, 1, content.Finalizers[0].Visible)
+	require.Len(t, content.Mismatches, 3)
+	assert.Equal(t, "certificates.cert-manager.io: namespace controller reports 3 instance(s) but only 1 are visible", content.Mismatches[0])
+	assert.Contains(t, content.Mismatches[1], "podmetrics.metrics.k8s.io")
+	assert.Contains(t, content.Mismatches[1], "could not be listed")
+	assert.Contains(t, content.Mismatches[2], "finalizer.cert-manager.io/cleanup")
+}
+
+func TestAnalyzeConditions_ContentFailure(t *testing.T) {
+	rootCause, failures := analyzeConditions([]corev1.NamespaceCondition{
+		{Type: corev1.NamespaceDeletionContentFailure, Status: corev1.ConditionTrue, Message: "Failed to delete all resource types, 1 remaining: boom"},
+		{Type: corev1.NamespaceContentRemaining, Status: corev1.ConditionTrue, Message: "Some resources are remaining: pods has 1 resource instances"},
+	})
+
+	assert.Equal(t, "Namespace controller failed to delete some content", rootCause)
+	assert.Empty(t, failures)
+}
@@ -0,0 +1,461 @@
+package detector
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/sozercan/unstuck/pkg/kube"
+	"github.com/sozercan/unstuck/pkg/types"
+)
+
+// defaultLeaseDuration is used when a Lease does not declare leaseDurationSeconds
+const defaultLeaseDuration = 15 * time.Second
+
+// ControllerDetector finds the controllers responsible for blocker finalizers
+type ControllerDetector struct {
+	client *kube.Client
+	now    func() time.Time
+}
+
+// NewControllerDetector creates a new controller detector
+func NewControllerDetector(client *kube.Client) *ControllerDetector {
+	return &ControllerDetector{client: client, now: time.Now}
+}
+
+// DetectForBlockers finds controllers for the blockers' finalizers and checks their liveness.
+// A controller is associated with a blocker's API group either through RBAC (a ServiceAccount
+// that may update that group) or, as a fallback, by a workload in the blocker's namespace
+// named after the group.
+func (d *ControllerDetector) DetectForBlockers(ctx context.Context, blockers []types.Blocker) ([]types.ControllerStatus, error) {
+	finalizersByGroup := finalizersByAPIGroup(blockers)
+	if len(finalizersByGroup) == 0 {
+		return nil, nil
+	}
+
+	groupsBySA, err := d.serviceAccountGroups(ctx, finalizersByGroup)
+	if err != nil {
+		return nil, err
+	}
+
+	// Search namespaces holding a matched ServiceAccount, plus the blockers' own namespaces
+	namespaceSet := make(map[string]bool)
+	for key := range groupsBySA {
+		namespaceSet[strings.SplitN(key, "/", 2)[0]] = true
+	}
+	for _, b := range blockers {
+		if b.Namespace != "" {
+			namespaceSet[b.Namespace] = true
+		}
+	}
+	namespaces := make([]string, 0, len(namespaceSet))
+	for ns := range namespaceSet {
+		namespaces = append(namespaces, ns)
+	}
+	sort.Strings(namespaces)
+
+	var controllers []types.ControllerStatus
+	seenSA := make(map[string]bool)
+
+	for _, ns := range namespaces {
+		workloads, err := d.namespaceControllers(ctx, ns, groupsBySA, finalizersByGroup)
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range workloads {
+			seenSA[c.Namespace+"/"+c.ServiceAccount] = true
+		}
+		controllers = append(controllers, workloads...)
+	}
+
+	// RBAC still references the ServiceAccount but no workload uses it anymore
+	saKeys := make([]string, 0, len(groupsBySA))
+	for key := range groupsBySA {
+		saKeys = append(saKeys, key)
+	}
+	sort.Strings(saKeys)
+	for _, key := range saKeys {
+		if seenSA[key] {
+			continue
+		}
+		parts := strings.SplitN(key, "/", 2)
+		controllers = append(controllers, types.ControllerStatus{
+			Name:           parts[1],
+			Namespace:      parts[0],
+			Kind:           "ServiceAccount",
+			ServiceAccount: parts[1],
+			Finalizers:     collectFinalizers(groupsBySA[key], finalizersByGroup),
+			Message:        "no workload found; the controller was likely already deleted",
+		})
+	}
+
+	for i := range controllers {
+		d.checkLiveness(ctx, &controllers[i], finalizersByGroup)
+	}
+
+	return controllers, nil
+}
+
+// namespaceControllers returns the Deployments and StatefulSets in a namespace that own blocker finalizers
+func (d *ControllerDetector) namespaceControllers(ctx context.Context, namespace string, groupsBySA map[string][]string, finalizersByGroup map[string][]string) ([]types.ControllerStatus, error) {
+	var controllers []types.ControllerStatus
+
+	deployments, err := d.client.Clientset.AppsV1().Deployments(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list deployments: %w", err)
+	}
+	for _, deploy := range deployments.Items {
+		sa := serviceAccountName(deploy.Spec.Template.Spec.ServiceAccountName)
+		groups := matchWorkloadGroups(deploy.Name, groupsBySA[namespace+"/"+sa], finalizersByGroup)
+		if len(groups) == 0 {
+			continue
+		}
+		controllers = append(controllers, types.ControllerStatus{
+			Name:           deploy.Name,
+			Namespace:      namespace,
+			Kind:           "Deployment",
+			ServiceAccount: sa,
+			Finalizers:     collectFinalizers(groups, finalizersByGroup),
+			Replicas:       desiredReplicas(deploy.Spec.Replicas),
+			Terminating:    deploy.DeletionTimestamp != nil,
+			Available:      deploymentAvailable(&deploy),
+			Ready:          deploy.DeletionTimestamp == nil && deploy.Status.ReadyReplicas > 0,
+			Message:        workloadMessage(deploy.DeletionTimestamp, desiredReplicas(deploy.Spec.Replicas), deploy.Status.ReadyReplicas),
+		})
+	}
+
+	statefulSets, err := d.client.Clientset.AppsV1().StatefulSets(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list statefulsets: %w", err)
+	}
+	for _, sts := range statefulSets.Items {
+		sa := serviceAccountName(sts.Spec.Template.Spec.ServiceAccountName)
+		groups := matchWorkloadGroups(sts.Name, groupsBySA[namespace+"/"+sa], finalizersByGroup)
+		if len(groups) == 0 {
+			continue
+		}
+		controllers = append(controllers, types.ControllerStatus{
+			Name:           sts.Name,
+			Namespace:      namespace,
+			Kind:           "StatefulSet",
+			ServiceAccount: sa,
+			Finalizers:     collectFinalizers(groups, finalizersByGroup),
+			Replicas:       desiredReplicas(sts.Spec.Replicas),
+			Terminating:    sts.DeletionTimestamp != nil,
+			Available:      sts.Status.AvailableReplicas > 0,
+			Ready:          sts.DeletionTimestamp == nil && sts.Status.ReadyReplicas > 0,
+			Message:        workloadMessage(sts.DeletionTimestamp, desiredReplicas(sts.Spec.Replicas), sts.Status.ReadyReplicas),
+		})
+	}
+
+	return controllers, nil
+}
+
+// serviceAccountGroups maps "namespace/name" ServiceAccounts to the blocker API groups they may update
+func (d *ControllerDetector) serviceAccountGroups(ctx context.Context, finalizersByGroup map[string][]string) (map[string][]string, error) {
+	result := make(map[string][]string)
+	clusterRoles := make(map[string][]rbacv1.PolicyRule)
+
+	rulesFor := func(namespace string, roleRef rbacv1.RoleRef) []rbacv1.PolicyRule {
+		if roleRef.Kind == "Role" {
+			role, err := d.client.Clientset.RbacV1().Roles(namespace).Get(ctx, roleRef.Name, metav1.GetOptions{})
+			if err != nil {
+				return nil
+			}
+			return role.Rules
+		}
+		if rules, ok := clusterRoles[roleRef.Name]; ok {
+			return rules
+		}
+		role, err := d.client.Clientset.RbacV1().ClusterRoles().Get(ctx, roleRef.Name, metav1.GetOptions{})
+		if err != nil {
+			clusterRoles[roleRef.Name] = nil
+			return nil
+		}
+		clusterRoles[roleRef.Name] = role.Rules
+		return role.Rules
+	}
+
+	addSubjects := func(bindingNamespace string, subjects []rbacv1.Subject, roleRef rbacv1.RoleRef) {
+		for _, subject := range subjects {
+			if subject.Kind != rbacv1.ServiceAccountKind {
+				continue
+			}
+			saNamespace := subject.Namespace
+			if saNamespace == "" {
+				saNamespace = bindingNamespace
+			}
+			key := saNamespace + "/" + subject.Name
+			rules := rulesFor(bindingNamespace, roleRef)
+			for group := range finalizersByGroup {
+				if rulesGrantUpdate(rules, group) && !containsString(result[key], group) {
+					result[key] = append(result[key], group)
+				}
+			}
+		}
+	}
+
+	crbs, err := d.client.Clientset.RbacV1().ClusterRoleBindings().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list clusterrolebindings: %w", err)
+	}
+	for _, crb := range crbs.Items {
+		addSubjects("", crb.Subjects, crb.RoleRef)
+	}
+
+	rbs, err := d.client.Clientset.RbacV1().RoleBindings("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list rolebindings: %w", err)
+	}
+	for _, rb := range rbs.Items {
+		addSubjects(rb.Namespace, rb.Subjects, rb.RoleRef)
+	}
+
+	for key := range result {
+		sort.Strings(result[key])
+	}
+	return result, nil
+}
+
+// checkLiveness finds the controller's leader-election Lease and classifies it as alive, stale or absent
+func (d *ControllerDetector) checkLiveness(ctx context.Context, controller *types.ControllerStatus, finalizersByGroup map[string][]string) {
+	leases, err := d.client.Clientset.CoordinationV1().Leases(controller.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		// Leave liveness unknown rather than guessing
+		return
+	}
+
+	var best *coordinationv1.Lease
+	for i := range leases.Items {
+		lease := &leases.Items[i]
+		if !leaseMatchesController(lease, controller, finalizersByGroup) {
+			continue
+		}
+		if best == nil || leaseRenewedAfter(lease, best) {
+			best = lease
+		}
+	}
+
+	if best == nil {
+		controller.Liveness = types.ControllerAbsent
+		return
+	}
+
+	controller.LeaseName = best.Name
+	if best.Spec.HolderIdentity != nil {
+		controller.LeaseHolder = *best.Spec.HolderIdentity
+	}
+	if best.Spec.RenewTime != nil {
+		renewTime := metav1.NewTime(best.Spec.RenewTime.Time)
+		controller.LeaseRenewTime = &renewTime
+	}
+	controller.Liveness = classifyLease(best, d.now())
+}
+
+// leaseMatchesController returns true if the Lease belongs to the controller. Leader-election
+// holder identities usually start with the pod name, which starts with the workload name.
+func leaseMatchesController(lease *coordinationv1.Lease, controller *types.ControllerStatus, finalizersByGroup map[string][]string) bool {
+	if controller.Kind != "ServiceAccount" {
+		if lease.Spec.HolderIdentity != nil && strings.HasPrefix(*lease.Spec.HolderIdentity, controller.Name+"-") {
+			return true
+		}
+		if strings.Contains(lease.Name, controller.Name) {
+			return true
+		}
+	}
+	for group := range finalizersByGroup {
+		if isBuiltinGroup(group) || !containsFinalizerOfGroup(controller.Finalizers, finalizersByGroup[group]) {
+			continue
+		}
+		if strings.Contains(lease.Name, group) || strings.Contains(lease.Name, strings.SplitN(group, ".", 2)[0]) {
+			return true
+		}
+	}
+	return false
+}
+
+// classifyLease classifies a Lease, allowing one missed renewal before calling it stale
+func classifyLease(lease *coordinationv1.Lease, now time.Time) types.ControllerLiveness {
+	if lease.Spec.HolderIdentity == nil || *lease.Spec.HolderIdentity == "" {
+		return types.ControllerAbsent
+	}
+	if lease.Spec.RenewTime == nil {
+		return types.ControllerStale
+	}
+
+	duration := defaultLeaseDuration
+	if lease.Spec.LeaseDurationSeconds != nil && *lease.Spec.LeaseDurationSeconds > 0 {
+		duration = time.Duration(*lease.Spec.LeaseDurationSeconds) * time.Second
+	}
+	if now.Sub(lease.Spec.RenewTime.Time) > 2*duration {
+		return types.ControllerStale
+	}
+	return types.ControllerAlive
+}
+
+func leaseRenewedAfter(a, b *coordinationv1.Lease) bool {
+	if a.Spec.RenewTime == nil {
+		return false
+	}
+	if b.Spec.RenewTime == nil {
+		return true
+	}
+	return a.Spec.RenewTime.After(b.Spec.RenewTime.Time)
+}
+
+func containsFinalizerOfGroup(finalizers, groupFinalizers []string) bool {
+	for _, f := range groupFinalizers {
+		if containsString(finalizers, f) {
+			return true
+		}
+	}
+	return false
+}
+
+// finalizersByAPIGroup groups blocker finalizers by the blocker's API group
+// Core group blockers are skipped since nearly every workload may update them
+func finalizersByAPIGroup(blockers []types.Blocker) map[string][]string {
+	result := make(map[string][]string)
+	for _, b := range blockers {
+		if len(b.Finalizers) == 0 {
+			continue
+		}
+		gv, err := schema.ParseGroupVersion(b.APIVersion)
+		if err != nil || gv.Group == "" {
+			continue
+		}
+		for _, f := range b.Finalizers {
+			if !containsString(result[gv.Group], f) {
+				result[gv.Group] = append(result[gv.Group], f)
+			}
+		}
+	}
+	return result
+}
+
+// rulesGrantUpdate returns true if the rules allow updating resources in the group
+// Wildcard groups are ignored so that cluster-admin bindings do not match everything
+func rulesGrantUpdate(rules []rbacv1.PolicyRule, group string) bool {
+	for _, rule := range rules {
+		if !containsString(rule.APIGroups, group) {
+			continue
+		}
+		if containsVerb(rule.Verbs, "update") || containsVerb(rule.Verbs, "patch") || containsVerb(rule.Verbs, "*") {
+			return true
+		}
+	}
+	return false
+}
+
+// matchWorkloadGroups returns the groups a workload is responsible for, either via
+// its ServiceAccount's RBAC or because it is named after the group (e.g. cert-manager)
+func matchWorkloadGroups(name string, rbacGroups []string, finalizersByGroup map[string][]string) []string {
+	groups := append([]string{}, rbacGroups...)
+	for group := range finalizersByGroup {
+		if isBuiltinGroup(group) {
+			continue
+		}
+		prefix := strings.SplitN(group, ".", 2)[0]
+		if strings.Contains(name, prefix) && !containsString(groups, group) {
+			groups = append(groups, group)
+		}
+	}
+	sort.Strings(groups)
+	return groups
+}
+
+// isBuiltinGroup returns true for API groups served by Kubernetes itself (apps, batch,
+// networking.k8s.io, ...), whose names are too generic to match controllers by
+func isBuiltinGroup(group string) bool {
+	return !strings.Contains(group, ".") || strings.HasSuffix(group, ".k8s.io")
+}
+
+func collectFinalizers(groups []string, finalizersByGroup map[string][]string) []string {
+	var finalizers []string
+	for _, g := range groups {
+		for _, f := range finalizersByGroup[g] {
+			if !containsString(finalizers, f) {
+				finalizers = append(finalizers, f)
+			}
+		}
+	}
+	sort.Strings(finalizers)
+	return finalizers
+}
+
+func deploymentAvailable(deploy *appsv1.Deployment) bool {
+	for _, c := range deploy.Status.Conditions {
+		if c.Type == appsv1.DeploymentAvailable {
+			return c.Status == "True"
+		}
+	}
+	return false
+}
+
+// desiredReplicas returns the workload's desired replicas, which default to 1 when unset
+func desiredReplicas(replicas *int32) int32 {
+	if replicas == nil {
+		return 1
+	}
+	return *replicas
+}
+
+func workloadMessage(deletionTimestamp *metav1.Time, replicas, readyReplicas int32) string {
+	if deletionTimestamp != nil {
+		return "workload is being deleted"
+	}
+	if replicas == 0 {
+		return "scaled to zero replicas"
+	}
+	if readyReplicas == 0 {
+		return "no ready replicas"
+	}
+	return ""
+}
+
+func serviceAccountName(name string) string {
+	if name == "" {
+		return "default"
+	}
+	return name
+}
+
+func containsString(slice []string, val string) bool {
+	for _, s := range slice {
+		if s == val {
+			return true
+		}
+	}
+	return false
+}
+
+// detectControllers attaches the controllers of the blockers' finalizers to the report, or records
+// why they could not be looked up so that "no controller" is not mistaken for a finding
+func detectControllers(ctx context.Context, client *kube.Client, report *types.DiagnosisReport, blockers []types.Blocker) {
+	controllers, err := NewControllerDetector(client).DetectForBlockers(ctx, blockers)
+	if err != nil {
+		report.ControllerError = err.Error()
+		return
+	}
+	report.Controllers = controllers
+}
+
+// controllerRecommendations explains a failed controller lookup
+func controllerRecommendations(report *types.DiagnosisReport) []string {
+	if report.ControllerError == "" {
+		return nil
+	}
+	rec := fmt.Sprintf("Could not look up the controllers of the blockers' finalizers: %s. Controller status is unknown", report.ControllerError)
+	if report.TargetType == types.TargetTypeNamespace {
+		rec += " and self-deadlock detection was skipped"
+	}
+	return []string{rec + "; check that you can list RBAC bindings, ServiceAccounts and workloads."}
+}
@@ -0,0 +1,244 @@
+package detector
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/sozercan/unstuck/pkg/kube"
+	"github.com/sozercan/unstuck/pkg/types"
+)
+
+func TestControllerDetector_DetectForBlockers(t *testing.T) {
+	now := metav1.Now()
+	clientset := fake.NewSimpleClientset(
+		&rbacv1.ClusterRole{
+			ObjectMeta: metav1.ObjectMeta{Name: "operator"},
+			Rules: []rbacv1.PolicyRule{
+				{APIGroups: []string{"example.com"}, Resources: []string{"widgets"}, Verbs: []string{"get", "update"}},
+			},
+		},
+		&rbacv1.ClusterRoleBinding{
+			ObjectMeta: metav1.ObjectMeta{Name: "operator"},
+			RoleRef:    rbacv1.RoleRef{Kind: "ClusterRole", Name: "operator"},
+			Subjects:   []rbacv1.Subject{{Kind: rbacv1.ServiceAccountKind, Name: "operator-sa", Namespace: "apps"}},
+		},
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "widget-operator", Namespace: "apps", DeletionTimestamp: &now},
+			Spec: appsv1.DeploymentSpec{
+				Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{ServiceAccountName: "operator-sa"}},
+			},
+		},
+	)
+	d := NewControllerDetector(&kube.Client{Clientset: clientset})
+
+	blockers := []types.Blocker{
+		{
+			ResourceRef: types.ResourceRef{Kind: "Widget", APIVersion: "example.com/v1", Namespace: "apps", Name: "w1"},
+			Finalizers:  []string{"example.com/cleanup"},
+		},
+		{
+			ResourceRef: types.ResourceRef{Kind: "ConfigMap", APIVersion: "v1", Namespace: "apps", Name: "cm"},
+			Finalizers:  []string{"kubernetes.io/custom"},
+		},
+	}
+
+	controllers, err := d.DetectForBlockers(context.Background(), blockers)
+	require.NoError(t, err)
+	require.Len(t, controllers, 1)
+	assert.Equal(t, "Deployment", controllers[0].Kind)
+	assert.Equal(t, "widget-operator", controllers[0].Name)
+	assert.Equal(t, []string{"example.com/cleanup"}, controllers[0].Finalizers)
+	assert.False(t, controllers[0].Ready)
+
+	assert.Equal(t, types.ControllerAbsent, controllers[0].Liveness)
+
+	deadlock := buildSelfDeadlock("apps", controllers, blockers)
+	require.NotNil(t, deadlock)
+	assert.Equal(t, []string{"example.com/cleanup"}, deadlock.Finalizers)
+	assert.Equal(t, []types.ResourceRef{blockers[0].ResourceRef}, deadlock.Blockers)
+}
+
+func TestControllerDetector_DetectForBlockers_WorkloadGone(t *testing.T) {
+	clientset := fake.NewSimpleClientset(
+		&rbacv1.Role{
+			ObjectMeta: metav1.ObjectMeta{Name: "operator", Namespace: "apps"},
+			Rules: []rbacv1.PolicyRule{
+				{APIGroups: []string{"example.com"}, Resources: []string{"widgets"}, Verbs: []string{"*"}},
+			},
+		},
+		&rbacv1.RoleBinding{
+			ObjectMeta: metav1.ObjectMeta{Name: "operator", Namespace: "apps"},
+			RoleRef:    rbacv1.RoleRef{Kind: "Role", Name: "operator"},
+			Subjects:   []rbacv1.Subject{{Kind: rbacv1.ServiceAccountKind, Name: "operator-sa", Namespace: "apps"}},
+		},
+	)
+	d := NewControllerDetector(&kube.Client{Clientset: clientset})
+
+	blockers := []types.Blocker{
+		{
+			ResourceRef: types.ResourceRef{Kind: "Widget", APIVersion: "example.com/v1", Namespace: "apps", Name: "w1"},
+			Finalizers:  []string{"example.com/cleanup"},
+		},
+	}
+
+	controllers, err := d.DetectForBlockers(context.Background(), blockers)
+	require.NoError(t, err)
+	require.Len(t, controllers, 1)
+	assert.Equal(t, "ServiceAccount", controllers[0].Kind)
+	assert.Equal(t, "operator-sa", controllers[0].Name)
+}
+
+func TestDetectControllers_Error(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	clientset.PrependReactor("list", "clusterrolebindings", func(k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewForbidden(schema.GroupResource{Group: "rbac.authorization.k8s.io", Resource: "clusterrolebindings"}, "", nil)
+	})
+
+	report := &types.DiagnosisReport{
+		Target:     types.ResourceRef{Kind: "Namespace", Name: "apps"},
+		TargetType: types.TargetTypeNamespace,
+	}
+	blockers := []types.Blocker{{
+		ResourceRef: types.ResourceRef{Kind: "Widget", APIVersion: "example.com/v1", Namespace: "apps", Name: "w1"},
+		Finalizers:  []string{"example.com/cleanup"},
+	}}
+	detectControllers(context.Background(), &kube.Client{Clientset: clientset}, report, blockers)
+
+	assert.Empty(t, report.Controllers)
+	assert.Contains(t, report.ControllerError, "forbidden")
+	recs := controllerRecommendations(report)
+	require.Len(t, recs, 1)
+	assert.Contains(t, recs[0], "self-deadlock detection was skipped")
+}
+
+func TestRulesGrantUpdate(t *testing.T) {
+	tests := []struct {
+		name     string
+		rules    []rbacv1.PolicyRule
+		expected bool
+	}{
+		{"update verb", []rbacv1.PolicyRule{{APIGroups: []string{"example.com"}, Verbs: []string{"update"}}}, true},
+		{"read only", []rbacv1.PolicyRule{{APIGroups: []string{"example.com"}, Verbs: []string{"get", "list"}}}, false},
+		{"other group", []rbacv1.PolicyRule{{APIGroups: []string{"other.io"}, Verbs: []string{"*"}}}, false},
+		{"wildcard group ignored", []rbacv1.PolicyRule{{APIGroups: []string{"*"}, Verbs: []string{"*"}}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, rulesGrantUpdate(tt.rules, "example.com"))
+		})
+	}
+}
+
+func TestControllerDetector_Liveness(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	holder := "example-operator-7d9f8-abcde_3f2a"
+	duration := int32(15)
+
+	tests := []struct {
+		name     string
+		renewAgo time.Duration
+		expected types.ControllerLiveness
+	}{
+		{"recently renewed", 5 * time.Second, types.ControllerAlive},
+		{"not renewed", 10 * time.Minute, types.ControllerStale},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			renew := metav1.NewMicroTime(now.Add(-tt.renewAgo))
+			clientset := fake.NewSimpleClientset(
+				&appsv1.Deployment{
+					ObjectMeta: metav1.ObjectMeta{Name: "example-operator", Namespace: "operators"},
+					Status:     appsv1.DeploymentStatus{ReadyReplicas: 1},
+				},
+				&coordinationv1.Lease{
+					ObjectMeta: metav1.ObjectMeta{Name: "leader.example.com", Namespace: "operators"},
+					Spec: coordinationv1.LeaseSpec{
+						HolderIdentity:       &holder,
+						LeaseDurationSeconds: &duration,
+						RenewTime:            &renew,
+					},
+				},
+			)
+			d := NewControllerDetector(&kube.Client{Clientset: clientset})
+			d.now = func() time.Time { return now }
+
+			blockers := []types.Blocker{
+				{
+					ResourceRef: types.ResourceRef{Kind: "Widget", APIVersion: "example.com/v1", Namespace: "operators", Name: "w1"},
+					Finalizers:  []string{"example.com/cleanup"},
+				},
+			}
+
+			controllers, err := d.DetectForBlockers(context.Background(), blockers)
+			require.NoError(t, err)
+			require.Len(t, controllers, 1)
+			assert.Equal(t, tt.expected, controllers[0].Liveness)
+			assert.Equal(t, "leader.example.com", controllers[0].LeaseName)
+			assert.Equal(t, holder, controllers[0].LeaseHolder)
+		})
+	}
+}
+
+func TestClassifyLease(t *testing.T) {
+	now := time.Now()
+	empty := ""
+	holder := "pod-1"
+	renew := metav1.NewMicroTime(now)
+
+	tests := []struct {
+		name     string
+		spec     coordinationv1.LeaseSpec
+		expected types.ControllerLiveness
+	}{
+		{"no holder", coordinationv1.LeaseSpec{RenewTime: &renew}, types.ControllerAbsent},
+		{"empty holder", coordinationv1.LeaseSpec{HolderIdentity: &empty, RenewTime: &renew}, types.ControllerAbsent},
+		{"never renewed", coordinationv1.LeaseSpec{HolderIdentity: &holder}, types.ControllerStale},
+		{"fresh", coordinationv1.LeaseSpec{HolderIdentity: &holder, RenewTime: &renew}, types.ControllerAlive},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lease := &coordinationv1.Lease{Spec: tt.spec}
+			assert.Equal(t, tt.expected, classifyLease(lease, now))
+		})
+	}
+}
+
+func TestMatchWorkloadGroups(t *testing.T) {
+	finalizersByGroup := map[string][]string{
+		"cert-manager.io":   {"cert-manager.io/cleanup"},
+		"apps":              {"example.com/protect"},
+		"networking.k8s.io": {"example.com/lb"},
+	}
+
+	tests := []struct {
+		name     string
+		workload string
+		expected []string
+	}{
+		{"named after the group", "cert-manager-webhook", []string{"cert-manager.io"}},
+		{"built-in group names are not matched", "apps-networking-proxy", []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, matchWorkloadGroups(tt.workload, nil, finalizersByGroup))
+		})
+	}
+}
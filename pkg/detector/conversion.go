@@ -0,0 +1,157 @@
+package detector
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	admissionv1 "k8s.io/api/admissionregistration/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/sozercan/unstuck/pkg/types"
+)
+
+// conversionErrorPattern matches list and get errors caused by a failing conversion webhook
+var conversionErrorPattern = regexp.MustCompile(`conversion webhook for \S+`)
+
+// servedVersions returns the versions instances can be listed at, storage version first. A CRD
+// that serves no version is listed at its storage version.
+func servedVersions(crd *apiextensionsv1.CustomResourceDefinition) []string {
+	storage := getStoredVersion(crd)
+	versions := []string{}
+	for _, v := range crd.Spec.Versions {
+		if v.Served && v.Name == storage {
+			versions = append(versions, v.Name)
+		}
+	}
+	for _, v := range crd.Spec.Versions {
+		if v.Served && v.Name != storage {
+			versions = append(versions, v.Name)
+		}
+	}
+	if len(versions) == 0 {
+		versions = append(versions, storage)
+	}
+	return versions
+}
+
+// listInstances lists the CRD's instances at the first served version that can be read. With
+// webhook conversion, objects stored at another version are converted on every read, so a broken
+// webhook can make some or all versions unreadable.
+func (d *CRDDetector) listInstances(ctx context.Context, crd *apiextensionsv1.CustomResourceDefinition) (int, []types.Blocker, map[string]int, *types.ConversionInfo, error) {
+	conversion := &types.ConversionInfo{
+		Strategy:       string(apiextensionsv1.NoneConverter),
+		StoredVersions: crd.Status.StoredVersions,
+	}
+	if crd.Spec.Conversion != nil && crd.Spec.Conversion.Strategy != "" {
+		conversion.Strategy = string(crd.Spec.Conversion.Strategy)
+	}
+	if conversion.Strategy == string(apiextensionsv1.WebhookConverter) {
+		conversion.Webhook = d.conversionWebhookHealth(ctx, crd)
+	}
+
+	var firstErr error
+	for _, version := range servedVersions(crd) {
+		gvr := schema.GroupVersionResource{Group: crd.Spec.Group, Version: version, Resource: crd.Spec.Names.Plural}
+		count, blockers, instancesByNS, err := d.countInstances(ctx, crd, gvr)
+		if err == nil {
+			conversion.ReadVersion = version
+			return count, blockers, instancesByNS, reportedConversion(conversion), nil
+		}
+		conversion.VersionErrors = append(conversion.VersionErrors, types.VersionError{Version: version, Error: err.Error()})
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	if conversion.Webhook != nil {
+		for _, ve := range conversion.VersionErrors {
+			if conversionErrorPattern.MatchString(ve.Error) {
+				conversion.InstancesUnreadable = true
+				break
+			}
+		}
+	}
+	return 0, nil, nil, reportedConversion(conversion), firstErr
+}
+
+// reportedConversion drops conversion details that carry no information
+func reportedConversion(conversion *types.ConversionInfo) *types.ConversionInfo {
+	if conversion.Webhook == nil && len(conversion.VersionErrors) == 0 {
+		return nil
+	}
+	return conversion
+}
+
+// conversionWebhookHealth runs the webhook service and TLS checks on the conversion webhook
+func (d *CRDDetector) conversionWebhookHealth(ctx context.Context, crd *apiextensionsv1.CustomResourceDefinition) *types.WebhookInfo {
+	var clientConfig admissionv1.WebhookClientConfig
+	if crd.Spec.Conversion.Webhook != nil && crd.Spec.Conversion.Webhook.ClientConfig != nil {
+		cc := crd.Spec.Conversion.Webhook.ClientConfig
+		clientConfig.URL = cc.URL
+		clientConfig.CABundle = cc.CABundle
+		if cc.Service != nil {
+			clientConfig.Service = &admissionv1.ServiceReference{
+				Namespace: cc.Service.Namespace,
+				Name:      cc.Service.Name,
+				Path:      cc.Service.Path,
+				Port:      cc.Service.Port,
+			}
+		}
+	}
+
+	info := NewWebhookDetector(d.client).buildWebhookInfoWithHealth(ctx, crd.ObjectMeta, crd.Name, "conversion", clientConfig, nil)
+	info = withURL(info, clientConfig)
+	if clientConfig.Service == nil && clientConfig.URL == nil {
+		info.Healthy = false
+		info.Error = "conversion strategy is Webhook but no client config is set"
+	}
+	return &info
+}
+
+// conversionRootCause explains why instances cannot be read through the conversion webhook
+func conversionRootCause(conversion *types.ConversionInfo) string {
+	reason := ""
+	if conversion.Webhook != nil && !conversion.Webhook.Healthy && conversion.Webhook.Error != "" {
+		reason = conversion.Webhook.Error
+	} else if len(conversion.VersionErrors) > 0 {
+		reason = truncateNote(conversion.VersionErrors[0].Error)
+	}
+	return "Instances unreadable due to conversion webhook - " + reason
+}
+
+// conversionRecommendations explains how conversion problems affect cleanup of a CRD's instances
+func conversionRecommendations(report *types.DiagnosisReport, crdName string) []string {
+	conversion := report.Conversion
+	if conversion == nil {
+		return nil
+	}
+
+	var recs []string
+	switch {
+	case conversion.InstancesUnreadable:
+		recs = append(recs, fmt.Sprintf(
+			"Instances of %s cannot be listed because its conversion webhook fails, so neither the CRD cleanup nor unstuck can remove their finalizers. Restore the webhook, or use `unstuck plan crd %s --max-escalation=3 --allow-force` to switch conversion to None and re-run the plan.",
+			crdName, crdName,
+		))
+	case conversion.Webhook != nil && !conversion.Webhook.Healthy:
+		recs = append(recs, fmt.Sprintf(
+			"Conversion webhook of %s is unhealthy: %s. Finalizer patches on objects stored at another version will fail until it is restored.",
+			crdName, conversion.Webhook.Error,
+		))
+	}
+
+	if conversion.ReadVersion != "" && len(conversion.VersionErrors) > 0 {
+		var failed []string
+		for _, ve := range conversion.VersionErrors {
+			failed = append(failed, ve.Version)
+		}
+		recs = append(recs, fmt.Sprintf(
+			"Listing at version(s) %s failed; instances were read at %s.",
+			strings.Join(failed, ", "), conversion.ReadVersion,
+		))
+	}
+	return recs
+}
@@ -0,0 +1,134 @@
+package detector
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/sozercan/unstuck/pkg/kube"
+	"github.com/sozercan/unstuck/pkg/types"
+)
+
+func conversionCRD() *apiextensionsv1.CustomResourceDefinition {
+	return &apiextensionsv1.CustomResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: "widgets.example.com"},
+		Spec: apiextensionsv1.CustomResourceDefinitionSpec{
+			Group: "example.com",
+			Names: apiextensionsv1.CustomResourceDefinitionNames{Plural: "widgets", Kind: "Widget"},
+			Scope: apiextensionsv1.NamespaceScoped,
+			Versions: []apiextensionsv1.CustomResourceDefinitionVersion{
+				{Name: "v1alpha1", Served: false},
+				{Name: "v1", Served: true},
+				{Name: "v2", Served: true, Storage: true},
+			},
+			Conversion: &apiextensionsv1.CustomResourceConversion{
+				Strategy: apiextensionsv1.WebhookConverter,
+				Webhook: &apiextensionsv1.WebhookConversion{
+					ClientConfig: &apiextensionsv1.WebhookClientConfig{
+						Service: &apiextensionsv1.ServiceReference{Namespace: "system", Name: "converter"},
+					},
+				},
+			},
+		},
+		Status: apiextensionsv1.CustomResourceDefinitionStatus{StoredVersions: []string{"v1", "v2"}},
+	}
+}
+
+func TestServedVersions(t *testing.T) {
+	assert.Equal(t, []string{"v2", "v1"}, servedVersions(conversionCRD()))
+
+	unserved := conversionCRD()
+	unserved.Spec.Versions = []apiextensionsv1.CustomResourceDefinitionVersion{{Name: "v1", Storage: true}}
+	assert.Equal(t, []string{"v1"}, servedVersions(unserved))
+}
+
+func TestCRDDetector_ListInstances_Conversion(t *testing.T) {
+	conversionErr := errors.New(`conversion webhook for example.com/v1, Kind=Widget failed: Post "https://converter.system.svc:443/convert": service "converter" not found`)
+
+	tests := []struct {
+		name           string
+		failVersions   map[string]bool
+		wantCount      int
+		wantRead       string
+		wantUnreadable bool
+		wantErr        bool
+	}{
+		{
+			name:         "falls back to another served version",
+			failVersions: map[string]bool{"v2": true},
+			wantCount:    1,
+			wantRead:     "v1",
+		},
+		{
+			name:           "no version readable",
+			failVersions:   map[string]bool{"v1": true, "v2": true},
+			wantUnreadable: true,
+			wantErr:        true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			widget := &unstructured.Unstructured{}
+			widget.SetAPIVersion("example.com/v1")
+			widget.SetKind("Widget")
+			widget.SetNamespace("apps")
+			widget.SetName("w1")
+			widget.SetFinalizers([]string{"example.com/cleanup"})
+
+			dynamic := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+				{Group: "example.com", Version: "v1", Resource: "widgets"}: "WidgetList",
+				{Group: "example.com", Version: "v2", Resource: "widgets"}: "WidgetList",
+			}, widget)
+			dynamic.PrependReactor("list", "widgets", func(action k8stesting.Action) (bool, runtime.Object, error) {
+				if tt.failVersions[action.GetResource().Version] {
+					return true, nil, conversionErr
+				}
+				return false, nil, nil
+			})
+			client := &kube.Client{Clientset: fake.NewSimpleClientset(), Dynamic: dynamic}
+
+			count, blockers, _, conversion, err := NewCRDDetector(client).listInstances(context.Background(), conversionCRD())
+			if tt.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, tt.wantCount, count)
+			assert.Len(t, blockers, tt.wantCount)
+
+			require.NotNil(t, conversion)
+			assert.Equal(t, "Webhook", conversion.Strategy)
+			assert.Equal(t, tt.wantRead, conversion.ReadVersion)
+			assert.Equal(t, tt.wantUnreadable, conversion.InstancesUnreadable)
+			assert.Len(t, conversion.VersionErrors, len(tt.failVersions))
+			require.NotNil(t, conversion.Webhook)
+			assert.False(t, conversion.Webhook.Healthy)
+			assert.Equal(t, "service system/converter not found", conversion.Webhook.Error)
+
+			if tt.wantUnreadable {
+				assert.Equal(t, "Instances unreadable due to conversion webhook - service system/converter not found", conversionRootCause(conversion))
+			}
+		})
+	}
+}
+
+func TestConversionRecommendations(t *testing.T) {
+	report := &types.DiagnosisReport{Conversion: &types.ConversionInfo{Strategy: "Webhook", InstancesUnreadable: true}}
+	recs := conversionRecommendations(report, "widgets.example.com")
+	require.Len(t, recs, 1)
+	assert.Contains(t, recs[0], "--max-escalation=3 --allow-force")
+
+	assert.Nil(t, conversionRecommendations(&types.DiagnosisReport{}, "widgets.example.com"))
+}
@@ -0,0 +1,124 @@
+package detector
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/sozercan/unstuck/pkg/types"
+)
+
+// storageKeyPattern extracts storage keys from errors returned by servers that predate StorageReadError
+var storageKeyPattern = regexp.MustCompile(`key "(/[^"]+)"`)
+
+// corruptObjects returns the objects a list error reports as unreadable from storage,
+// or nil if the list failed for another reason
+func corruptObjects(err error, gvr schema.GroupVersionResource, kind string, namespaced bool) []types.CorruptObject {
+	var statusErr *apierrors.StatusError
+	if !errors.As(err, &statusErr) {
+		return nil
+	}
+	status := statusErr.Status()
+
+	// A failing conversion webhook also surfaces as a decode error, but the stored objects are fine
+	if conversionErrorPattern.MatchString(status.Message) {
+		return nil
+	}
+
+	// Storage key -> error reported for that key
+	var keys []string
+	messages := make(map[string]string)
+	switch {
+	case apierrors.IsStoreReadError(err):
+		// Kubernetes v1.32+ lists every unreadable key as a cause
+		if status.Details != nil {
+			for _, cause := range status.Details.Causes {
+				if cause.Field == "" {
+					continue
+				}
+				keys = append(keys, cause.Field)
+				messages[cause.Field] = cause.Message
+			}
+		}
+	case apierrors.IsInternalError(err) && isUndecodableMessage(status.Message):
+		for _, match := range storageKeyPattern.FindAllStringSubmatch(status.Message, -1) {
+			keys = append(keys, match[1])
+		}
+	default:
+		return nil
+	}
+
+	template := types.CorruptObject{
+		ResourceRef: types.ResourceRef{Kind: kind, APIVersion: gvr.GroupVersion().String()},
+		Resource:    groupResourceString(gvr.Group, gvr.Resource),
+		Error:       status.Message,
+	}
+
+	// The server did not say which object failed; still report the resource type
+	if len(keys) == 0 {
+		return []types.CorruptObject{template}
+	}
+
+	var objects []types.CorruptObject
+	for _, key := range keys {
+		obj := template
+		obj.Key = key
+		obj.Namespace, obj.Name = objectFromStorageKey(key, namespaced)
+		if msg := messages[key]; msg != "" {
+			obj.Error = msg
+		}
+		objects = append(objects, obj)
+	}
+	return objects
+}
+
+// isUndecodableMessage returns true if an internal error was caused by data that could not be
+// decrypted or decoded
+func isUndecodableMessage(message string) bool {
+	lower := strings.ToLower(message)
+	return strings.Contains(lower, "unable to transform key") ||
+		strings.Contains(lower, "decode") ||
+		strings.Contains(lower, "illegal base64")
+}
+
+// objectFromStorageKey returns the namespace and name encoded in a storage key,
+// e.g. /registry/example.com/widgets/apps/w1
+func objectFromStorageKey(key string, namespaced bool) (string, string) {
+	parts := strings.Split(strings.Trim(key, "/"), "/")
+	name := parts[len(parts)-1]
+	if namespaced && len(parts) >= 2 {
+		return parts[len(parts)-2], name
+	}
+	return "", name
+}
+
+// corruptObjectRecommendations explains how to deal with objects that cannot be read from storage
+func corruptObjectRecommendations(report *types.DiagnosisReport, planCommand string) []string {
+	if !report.HasCorruptObjects() {
+		return nil
+	}
+
+	resources := make(map[string]bool)
+	var names []string
+	for _, obj := range report.CorruptObjects {
+		if !resources[obj.Resource] {
+			resources[obj.Resource] = true
+			names = append(names, obj.Resource)
+		}
+	}
+
+	return []string{
+		fmt.Sprintf(
+			"%d object(s) of %s cannot be read from storage (decode or decryption failure) and block deletion. Restore the storage version or encryption key if you can.",
+			len(report.CorruptObjects), strings.Join(names, ", "),
+		),
+		fmt.Sprintf(
+			"As a last resort, `%s --max-escalation=4 --allow-force` deletes them with ignoreStoreReadErrorWithClusterBreakingPotential (Kubernetes v1.32+ with the AllowUnsafeMalformedObjectDeletion feature gate). This skips finalizers and may break workloads that depend on them.",
+			planCommand,
+		),
+	}
+}
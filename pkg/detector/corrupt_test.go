@@ -0,0 +1,100 @@
+package detector
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/sozercan/unstuck/pkg/types"
+)
+
+func TestCorruptObjects(t *testing.T) {
+	gvr := schema.GroupVersionResource{Group: "example.com", Version: "v1", Resource: "widgets"}
+
+	storeReadError := &apierrors.StatusError{ErrStatus: metav1.Status{
+		Status:  metav1.StatusFailure,
+		Code:    500,
+		Reason:  metav1.StatusReasonStoreReadError,
+		Message: "failed to read one or more widgets.example.com from the storage",
+		Details: &metav1.StatusDetails{Causes: []metav1.StatusCause{
+			{Type: metav1.CauseTypeUnexpectedServerResponse, Field: "/registry/example.com/widgets/apps/w1", Message: "failed to decode: unknown field"},
+		}},
+	}}
+
+	tests := []struct {
+		name     string
+		err      error
+		expected []types.CorruptObject
+	}{
+		{
+			name: "storage read error with causes",
+			err:  storeReadError,
+			expected: []types.CorruptObject{{
+				ResourceRef: types.ResourceRef{Kind: "Widget", APIVersion: "example.com/v1", Namespace: "apps", Name: "w1"},
+				Resource:    "widgets.example.com",
+				Key:         "/registry/example.com/widgets/apps/w1",
+				Error:       "failed to decode: unknown field",
+			}},
+		},
+		{
+			name: "internal error from older server",
+			err:  apierrors.NewInternalError(errors.New(`unable to transform key "/registry/example.com/widgets/apps/w2": no matching key`)),
+			expected: []types.CorruptObject{{
+				ResourceRef: types.ResourceRef{Kind: "Widget", APIVersion: "example.com/v1", Namespace: "apps", Name: "w2"},
+				Resource:    "widgets.example.com",
+				Key:         "/registry/example.com/widgets/apps/w2",
+				Error:       `Internal error occurred: unable to transform key "/registry/example.com/widgets/apps/w2": no matching key`,
+			}},
+		},
+		{
+			name:     "conversion webhook failure is not corruption",
+			err:      apierrors.NewInternalError(errors.New(`conversion webhook for example.com/v2, Kind=Widget failed: unable to decode "/registry/example.com/widgets/apps/w3"`)),
+			expected: nil,
+		},
+		{
+			name:     "forbidden is not corruption",
+			err:      apierrors.NewForbidden(gvr.GroupResource(), "", errors.New("denied")),
+			expected: nil,
+		},
+		{
+			name:     "plain error",
+			err:      errors.New("connection refused"),
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, corruptObjects(tt.err, gvr, "Widget", true))
+		})
+	}
+}
+
+func TestCorruptObjects_Unidentified(t *testing.T) {
+	gvr := schema.GroupVersionResource{Group: "example.com", Version: "v1", Resource: "widgets"}
+	err := &apierrors.StatusError{ErrStatus: metav1.Status{
+		Reason:  metav1.StatusReasonStoreReadError,
+		Message: "failed to read one or more widgets.example.com from the storage",
+	}}
+
+	objects := corruptObjects(err, gvr, "Widget", true)
+
+	require.Len(t, objects, 1)
+	assert.False(t, objects[0].IsIdentified())
+	assert.Equal(t, "widgets.example.com", objects[0].Resource)
+}
+
+func TestObjectFromStorageKey(t *testing.T) {
+	ns, name := objectFromStorageKey("/registry/secrets/default/token", true)
+	assert.Equal(t, "default", ns)
+	assert.Equal(t, "token", name)
+
+	ns, name = objectFromStorageKey("/registry/example.com/clusterwidgets/big", false)
+	assert.Empty(t, ns)
+	assert.Equal(t, "big", name)
+}
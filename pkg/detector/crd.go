@@ -16,12 +16,18 @@ import (
 
 // CRDDetector detects issues with stuck CRDs
 type CRDDetector struct {
-	client *kube.Client
+	client     *kube.Client
+	stuckAfter time.Duration
 }
 
 // NewCRDDetector creates a new CRD detector
 func NewCRDDetector(client *kube.Client) *CRDDetector {
-	return &CRDDetector{client: client}
+	return &CRDDetector{client: client, stuckAfter: DefaultStuckAfter}
+}
+
+// SetStuckAfter sets how long a blocker may be terminating before it is classified Stuck
+func (d *CRDDetector) SetStuckAfter(stuckAfter time.Duration) {
+	d.stuckAfter = stuckAfter
 }
 
 // Detect analyzes a CRD and returns a diagnosis report
@@ -60,30 +66,39 @@ func (d *CRDDetector) Detect(ctx context.Context, name string) (*types.Diagnosis
 
 	report.RootCause = d.analyzeRootCause(crd)
 
-	instanceCount, blockers, instancesByNS, err := d.countInstances(ctx, crd)
+	instanceCount, blockers, instancesByNS, conversion, err := d.listInstances(ctx, crd)
+	report.Conversion = conversion
 	if err != nil {
-		report.DiscoveryFailures = append(report.DiscoveryFailures, types.DiscoveryFailure{
-			GroupVersion: crd.Spec.Group + "/" + getStoredVersion(crd),
-			Resource:     crd.Spec.Names.Plural,
-			Error:        err.Error(),
-		})
+		// Instances that cannot be read from storage make the whole list fail
+		report.CorruptObjects = corruptObjects(err, storedGVR(crd), crd.Spec.Names.Kind, crd.Spec.Scope == apiextensionsv1.NamespaceScoped)
+		switch {
+		case report.HasConversionFailure():
+			report.RootCause = conversionRootCause(conversion)
+		case report.HasCorruptObjects():
+			report.RootCause = fmt.Sprintf("Corrupt objects - %d instance(s) cannot be read from storage", len(report.CorruptObjects))
+		default:
+			report.DiscoveryFailures = append(report.DiscoveryFailures, types.DiscoveryFailure{
+				GroupVersion: crd.Spec.Group + "/" + servedVersions(crd)[0],
+				Resource:     crd.Spec.Names.Plural,
+				Error:        err.Error(),
+			})
+		}
 	}
 	report.InstanceCount = instanceCount
 	report.InstancesByNS = instancesByNS
 	report.Blockers = blockers
 
+	detectControllers(ctx, d.client, report, blockers)
+
+	analyzeBlockers(ctx, d.client, report, d.stuckAfter)
+
 	report.Recommendations = d.buildRecommendations(report, crd)
 
 	return report, nil
 }
 
-func (d *CRDDetector) countInstances(ctx context.Context, crd *apiextensionsv1.CustomResourceDefinition) (int, []types.Blocker, map[string]int, error) {
-	gvr := schema.GroupVersionResource{
-		Group:    crd.Spec.Group,
-		Version:  getStoredVersion(crd),
-		Resource: crd.Spec.Names.Plural,
-	}
-
+// countInstances lists the CRD's instances at one version
+func (d *CRDDetector) countInstances(ctx context.Context, crd *apiextensionsv1.CustomResourceDefinition, gvr schema.GroupVersionResource) (int, []types.Blocker, map[string]int, error) {
 	var blockers []types.Blocker
 	instancesByNS := make(map[string]int)
 
@@ -104,9 +119,11 @@ func (d *CRDDetector) countInstances(ctx context.Context, crd *apiextensionsv1.C
 					Namespace:  ns,
 					Name:       item.GetName(),
 				},
-				Finalizers:        item.GetFinalizers(),
-				DeletionTimestamp: item.GetDeletionTimestamp(),
-				OwnerReferences:   item.GetOwnerReferences(),
+				UID:                 string(item.GetUID()),
+				Finalizers:          item.GetFinalizers(),
+				DeletionTimestamp:   item.GetDeletionTimestamp(),
+				OwnerReferences:     item.GetOwnerReferences(),
+				FinalizerProvenance: finalizerProvenance(item.GetManagedFields()),
 			}
 			if item.GetDeletionTimestamp() != nil {
 				blocker.Age = time.Since(item.GetDeletionTimestamp().Time)
@@ -129,9 +146,11 @@ func (d *CRDDetector) countInstances(ctx context.Context, crd *apiextensionsv1.C
 				APIVersion: item.GetAPIVersion(),
 				Name:       item.GetName(),
 			},
-			Finalizers:        item.GetFinalizers(),
-			DeletionTimestamp: item.GetDeletionTimestamp(),
-			OwnerReferences:   item.GetOwnerReferences(),
+			UID:                 string(item.GetUID()),
+			Finalizers:          item.GetFinalizers(),
+			DeletionTimestamp:   item.GetDeletionTimestamp(),
+			OwnerReferences:     item.GetOwnerReferences(),
+			FinalizerProvenance: finalizerProvenance(item.GetManagedFields()),
 		}
 		if item.GetDeletionTimestamp() != nil {
 			blocker.Age = time.Since(item.GetDeletionTimestamp().Time)
@@ -175,6 +194,10 @@ func (d *CRDDetector) buildRecommendations(report *types.DiagnosisReport, crd *a
 		))
 	}
 
+	recs = append(recs, corruptObjectRecommendations(report, "unstuck plan crd "+crd.Name)...)
+	recs = append(recs, conversionRecommendations(report, crd.Name)...)
+	recs = append(recs, blockerRecommendations(report, d.stuckAfter)...)
+
 	return recs
 }
 
@@ -190,6 +213,15 @@ func getStoredVersion(crd *apiextensionsv1.CustomResourceDefinition) string {
 	return "v1"
 }
 
+// storedGVR returns the resource of the CRD's storage version
+func storedGVR(crd *apiextensionsv1.CustomResourceDefinition) schema.GroupVersionResource {
+	return schema.GroupVersionResource{
+		Group:    crd.Spec.Group,
+		Version:  getStoredVersion(crd),
+		Resource: crd.Spec.Names.Plural,
+	}
+}
+
 func hasCleanupFinalizer(crd *apiextensionsv1.CustomResourceDefinition) bool {
 	for _, f := range crd.Finalizers {
 		if f == "customresourcecleanup.apiextensions.k8s.io" {
@@ -0,0 +1,53 @@
+package detector
+
+import (
+	"sort"
+
+	"github.com/sozercan/unstuck/pkg/types"
+)
+
+// buildSelfDeadlock returns a SelfDeadlock if the controllers for the blockers' finalizers
+// live in the terminating namespace and none of them, anywhere, can still reconcile
+func buildSelfDeadlock(namespace string, controllers []types.ControllerStatus, blockers []types.Blocker) *types.SelfDeadlock {
+	var inNamespace []types.ControllerStatus
+	owned := make(map[string]bool)
+	for _, c := range controllers {
+		if c.Namespace != namespace {
+			continue
+		}
+		inNamespace = append(inNamespace, c)
+		for _, f := range c.Finalizers {
+			owned[f] = true
+		}
+	}
+	if len(inNamespace) == 0 {
+		return nil
+	}
+
+	for _, c := range controllers {
+		if !c.IsReconciling() {
+			continue
+		}
+		for _, f := range c.Finalizers {
+			if owned[f] {
+				return nil
+			}
+		}
+	}
+
+	deadlock := &types.SelfDeadlock{Controllers: inNamespace}
+	for _, b := range blockers {
+		for _, f := range b.Finalizers {
+			if owned[f] {
+				deadlock.Blockers = append(deadlock.Blockers, b.ResourceRef)
+				break
+			}
+		}
+	}
+	for f := range owned {
+		deadlock.Finalizers = append(deadlock.Finalizers, f)
+	}
+	sort.Strings(deadlock.Finalizers)
+
+	return deadlock
+}
@@ -0,0 +1,34 @@
+package detector
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sozercan/unstuck/pkg/types"
+)
+
+func TestBuildSelfDeadlock(t *testing.T) {
+	blockers := []types.Blocker{
+		{ResourceRef: types.ResourceRef{Namespace: "apps", Name: "w1"}, Finalizers: []string{"example.com/cleanup"}},
+	}
+
+	tests := []struct {
+		name        string
+		controllers []types.ControllerStatus
+		expectNil   bool
+	}{
+		{"no controllers", nil, true},
+		{"controller outside namespace", []types.ControllerStatus{{Name: "op", Namespace: "operators", Finalizers: []string{"example.com/cleanup"}}}, true},
+		{"controller still reconciling", []types.ControllerStatus{{Name: "op", Namespace: "apps", Available: true, Ready: true, Finalizers: []string{"example.com/cleanup"}}}, true},
+		{"controller ready but lease stale", []types.ControllerStatus{{Name: "op", Namespace: "apps", Available: true, Ready: true, Liveness: types.ControllerStale, Finalizers: []string{"example.com/cleanup"}}}, false},
+		{"controller gone", []types.ControllerStatus{{Name: "op", Namespace: "apps", Finalizers: []string{"example.com/cleanup"}}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := buildSelfDeadlock("apps", tt.controllers, blockers)
+			assert.Equal(t, tt.expectNil, result == nil)
+		})
+	}
+}
@@ -2,6 +2,7 @@ package detector
 
 import (
 	"context"
+	"time"
 
 	"github.com/sozercan/unstuck/pkg/kube"
 	"github.com/sozercan/unstuck/pkg/types"
@@ -43,7 +44,62 @@ func (f *DetectorFactory) GetResourceDetector() *ResourceDetector {
 	return NewResourceDetector(f.client)
 }
 
+// GetControllerDetector returns a controller detector
+func (f *DetectorFactory) GetControllerDetector() *ControllerDetector {
+	return NewControllerDetector(f.client)
+}
+
 // GetWebhookDetector returns a webhook detector
 func (f *DetectorFactory) GetWebhookDetector() *WebhookDetector {
 	return NewWebhookDetector(f.client)
 }
+
+// analyzeBlockers runs the analyzers shared by every target type once its blockers and
+// controllers are known, then classifies the blockers.
+func analyzeBlockers(ctx context.Context, client *kube.Client, report *types.DiagnosisReport, stuckAfter time.Duration) {
+	// GC finalizers and dangling owners are the garbage collector's job, not a controller's
+	analyzeGarbageCollection(ctx, client, report)
+
+	// Networking finalizers delete cloud load balancers that outlive the objects if removed
+	analyzeNetworking(ctx, client, report)
+
+	// Pods of deleted Jobs keep the job tracking finalizer forever
+	analyzeJobTracking(ctx, client, report)
+
+	// Argo CD and Flux finalizers wait for the resources they deployed to be deleted
+	analyzeGitOps(ctx, client, report)
+
+	// Helm uninstalls wait on pre-delete hook Jobs and leave their release in uninstalling
+	analyzeHelm(ctx, client, report)
+
+	// Crossplane, ACK and Config Connector finalizers delete the cloud resources behind the objects
+	analyzeCloudResources(ctx, client, report)
+
+	// In-tree CEL policies can deny the update that removes finalizers
+	correlateAdmissionPolicies(ctx, client, report)
+
+	// A dry-run update shows whether webhooks admit the patches that remove finalizers
+	correlateWebhooks(ctx, client, report)
+
+	// Recent warnings usually contain the actual failure (webhook, cloud provider)
+	correlateEvents(ctx, client, report)
+
+	classifyBlockers(report, stuckAfter)
+}
+
+// blockerRecommendations returns the recommendations of the analyzers run by analyzeBlockers
+func blockerRecommendations(report *types.DiagnosisReport, stuckAfter time.Duration) []string {
+	var recs []string
+	recs = append(recs, controllerRecommendations(report)...)
+	recs = append(recs, gcRecommendations(report)...)
+	recs = append(recs, networkingRecommendations(report)...)
+	recs = append(recs, jobTrackingRecommendations(report)...)
+	recs = append(recs, gitOpsRecommendations(report)...)
+	recs = append(recs, helmRecommendations(report)...)
+	recs = append(recs, cloudResourceRecommendations(report)...)
+	recs = append(recs, admissionPolicyRecommendations(report)...)
+	recs = append(recs, webhookRecommendations(report)...)
+	recs = append(recs, stuckThresholdRecommendations(report.Blockers, stuckAfter)...)
+	recs = append(recs, provenanceRecommendations(report.Blockers)...)
+	return recs
+}
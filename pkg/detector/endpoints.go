@@ -0,0 +1,204 @@
+package detector
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+
+	admissionv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/sozercan/unstuck/pkg/types"
+)
+
+// defaultWebhookPort is used when a webhook's service reference does not set a port
+const defaultWebhookPort int32 = 443
+
+// webhookServicePort returns the service port the API server calls for the webhook. Services
+// without ports cannot be matched and are treated as exposing the webhook port.
+func webhookServicePort(svc *corev1.Service, ref *admissionv1.ServiceReference) (corev1.ServicePort, bool) {
+	port := defaultWebhookPort
+	if ref.Port != nil {
+		port = *ref.Port
+	}
+	if len(svc.Spec.Ports) == 0 {
+		return corev1.ServicePort{Port: port}, true
+	}
+	for _, p := range svc.Spec.Ports {
+		if p.Port == port {
+			return p, true
+		}
+	}
+	return corev1.ServicePort{Port: port}, false
+}
+
+// endpointSliceHealth counts the endpoints behind the service port per address family. Slices
+// that do not carry the port are skipped.
+func endpointSliceHealth(slices []discoveryv1.EndpointSlice, port corev1.ServicePort) []types.EndpointHealth {
+	byFamily := make(map[string]*types.EndpointHealth)
+	for _, slice := range slices {
+		if !slicePortMatches(slice.Ports, port.Name) {
+			continue
+		}
+		family := string(slice.AddressType)
+		h, ok := byFamily[family]
+		if !ok {
+			h = &types.EndpointHealth{AddressType: family, Port: port.Port, PortName: port.Name}
+			byFamily[family] = h
+		}
+		for _, ep := range slice.Endpoints {
+			// Unset conditions mean ready and serving
+			ready := ep.Conditions.Ready == nil || *ep.Conditions.Ready
+			serving := ready
+			if ep.Conditions.Serving != nil {
+				serving = *ep.Conditions.Serving
+			}
+			h.Total++
+			if ready {
+				h.Ready++
+			}
+			if serving {
+				h.Serving++
+			}
+			if ep.Conditions.Terminating != nil && *ep.Conditions.Terminating {
+				h.Terminating++
+			}
+		}
+	}
+	return sortedHealth(byFamily)
+}
+
+// slicePortMatches returns true if the slice carries the service port. Ports are matched by name,
+// which is empty for single-port services.
+func slicePortMatches(ports []discoveryv1.EndpointPort, name string) bool {
+	if len(ports) == 0 {
+		return true
+	}
+	for _, p := range ports {
+		if (p.Name == nil && name == "") || (p.Name != nil && *p.Name == name) {
+			return true
+		}
+	}
+	return false
+}
+
+// endpointsHealth counts the addresses of core/v1 Endpoints behind the service port per address
+// family, for clusters that do not serve EndpointSlices
+func endpointsHealth(endpoints *corev1.Endpoints, port corev1.ServicePort) []types.EndpointHealth {
+	byFamily := make(map[string]*types.EndpointHealth)
+	add := func(addresses []corev1.EndpointAddress, ready bool) {
+		for _, a := range addresses {
+			family := addressFamily(a.IP)
+			h, ok := byFamily[family]
+			if !ok {
+				h = &types.EndpointHealth{AddressType: family, Port: port.Port, PortName: port.Name}
+				byFamily[family] = h
+			}
+			h.Total++
+			if ready {
+				h.Ready++
+				h.Serving++
+			}
+		}
+	}
+
+	for _, subset := range endpoints.Subsets {
+		if !subsetPortMatches(subset.Ports, port.Name) {
+			continue
+		}
+		add(subset.Addresses, true)
+		add(subset.NotReadyAddresses, false)
+	}
+	return sortedHealth(byFamily)
+}
+
+func subsetPortMatches(ports []corev1.EndpointPort, name string) bool {
+	if len(ports) == 0 {
+		return true
+	}
+	for _, p := range ports {
+		if p.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func addressFamily(ip string) string {
+	if parsed := net.ParseIP(ip); parsed != nil && parsed.To4() == nil {
+		return string(discoveryv1.AddressTypeIPv6)
+	}
+	return string(discoveryv1.AddressTypeIPv4)
+}
+
+func sortedHealth(byFamily map[string]*types.EndpointHealth) []types.EndpointHealth {
+	result := make([]types.EndpointHealth, 0, len(byFamily))
+	for _, h := range byFamily {
+		result = append(result, *h)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].AddressType < result[j].AddressType })
+	return result
+}
+
+// unhealthyEndpointsMessage returns why the service cannot serve the webhook, or "" if it can.
+// The API server calls the service's primary IP family, so only that family needs ready endpoints;
+// services without families only need one ready endpoint of any family.
+func unhealthyEndpointsMessage(svc *corev1.Service, port corev1.ServicePort, health []types.EndpointHealth) string {
+	primary := ""
+	if len(svc.Spec.IPFamilies) > 0 {
+		primary = string(svc.Spec.IPFamilies[0])
+	}
+
+	var unhealthy *types.EndpointHealth
+	for i := range health {
+		h := &health[i]
+		if primary != "" && h.AddressType != primary {
+			continue
+		}
+		// kube-proxy falls back to serving terminating endpoints when none are ready
+		if h.Serving > 0 {
+			return ""
+		}
+		if unhealthy == nil {
+			unhealthy = h
+		}
+	}
+
+	msg := "no ready endpoints available"
+	if primary != "" {
+		msg = fmt.Sprintf("no ready %s endpoints available", primary)
+	}
+	if len(svc.Spec.Ports) > 0 {
+		msg += " on port " + types.EndpointHealth{Port: port.Port, PortName: port.Name}.PortString()
+	}
+	if unhealthy == nil {
+		return msg
+	}
+
+	var details []string
+	if unhealthy.Terminating > 0 {
+		details = append(details, fmt.Sprintf("%d terminating", unhealthy.Terminating))
+	}
+	if notReady := unhealthy.Total - unhealthy.Terminating; notReady > 0 {
+		details = append(details, fmt.Sprintf("%d not ready", notReady))
+	}
+	if len(details) > 0 {
+		msg += " (" + strings.Join(details, ", ") + ")"
+	}
+	return msg
+}
+
+// listEndpointSlices returns the EndpointSlices of a service, or an error if they cannot be listed
+func (d *WebhookDetector) listEndpointSlices(ctx context.Context, namespace, name string) ([]discoveryv1.EndpointSlice, error) {
+	slices, err := d.client.Clientset.DiscoveryV1().EndpointSlices(namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: discoveryv1.LabelServiceName + "=" + name,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return slices.Items, nil
+}
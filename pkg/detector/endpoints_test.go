@@ -0,0 +1,153 @@
+package detector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	admissionv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/sozercan/unstuck/pkg/kube"
+	"github.com/sozercan/unstuck/pkg/types"
+)
+
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+func int32Ptr(i int32) *int32 {
+	return &i
+}
+
+func webhookSlice(name string, family discoveryv1.AddressType, port string, conditions ...discoveryv1.EndpointConditions) *discoveryv1.EndpointSlice {
+	slice := &discoveryv1.EndpointSlice{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "system",
+			Name:      name,
+			Labels:    map[string]string{discoveryv1.LabelServiceName: "webhook"},
+		},
+		AddressType: family,
+		Ports:       []discoveryv1.EndpointPort{{Name: &port}},
+	}
+	for _, c := range conditions {
+		slice.Endpoints = append(slice.Endpoints, discoveryv1.Endpoint{Conditions: c})
+	}
+	return slice
+}
+
+func TestWebhookDetector_CheckServiceHealth(t *testing.T) {
+	ready := discoveryv1.EndpointConditions{Ready: boolPtr(true)}
+	notReady := discoveryv1.EndpointConditions{Ready: boolPtr(false), Serving: boolPtr(false)}
+	terminating := discoveryv1.EndpointConditions{Ready: boolPtr(false), Serving: boolPtr(false), Terminating: boolPtr(true)}
+	servingTerminating := discoveryv1.EndpointConditions{Ready: boolPtr(false), Serving: boolPtr(true), Terminating: boolPtr(true)}
+
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "system", Name: "webhook"},
+		Spec: corev1.ServiceSpec{
+			IPFamilies: []corev1.IPFamily{corev1.IPv6Protocol, corev1.IPv4Protocol},
+			Ports: []corev1.ServicePort{
+				{Name: "metrics", Port: 8080},
+				{Name: "https", Port: 443},
+			},
+		},
+	}
+
+	tests := []struct {
+		name        string
+		objects     []runtime.Object
+		port        *int32
+		wantHealthy bool
+		wantError   string
+		wantHealth  []types.EndpointHealth
+	}{
+		{
+			name: "ready on primary family",
+			objects: []runtime.Object{
+				webhookSlice("webhook-v6", discoveryv1.AddressTypeIPv6, "https", ready, notReady),
+				webhookSlice("webhook-v4", discoveryv1.AddressTypeIPv4, "https", notReady),
+			},
+			wantHealthy: true,
+			wantHealth: []types.EndpointHealth{
+				{AddressType: "IPv4", Port: 443, PortName: "https", Total: 1},
+				{AddressType: "IPv6", Port: 443, PortName: "https", Ready: 1, Serving: 1, Total: 2},
+			},
+		},
+		{
+			name: "only secondary family ready",
+			objects: []runtime.Object{
+				webhookSlice("webhook-v6", discoveryv1.AddressTypeIPv6, "https", terminating, notReady),
+				webhookSlice("webhook-v4", discoveryv1.AddressTypeIPv4, "https", ready),
+			},
+			wantError: "no ready IPv6 endpoints available on port https/443 (1 terminating, 1 not ready)",
+			wantHealth: []types.EndpointHealth{
+				{AddressType: "IPv4", Port: 443, PortName: "https", Ready: 1, Serving: 1, Total: 1},
+				{AddressType: "IPv6", Port: 443, PortName: "https", Terminating: 1, Total: 2},
+			},
+		},
+		{
+			name: "serving terminating endpoints still answer",
+			objects: []runtime.Object{
+				webhookSlice("webhook-v6", discoveryv1.AddressTypeIPv6, "https", servingTerminating),
+			},
+			wantHealthy: true,
+			wantHealth: []types.EndpointHealth{
+				{AddressType: "IPv6", Port: 443, PortName: "https", Serving: 1, Terminating: 1, Total: 1},
+			},
+		},
+		{
+			name: "ready only on another port",
+			objects: []runtime.Object{
+				webhookSlice("webhook-metrics", discoveryv1.AddressTypeIPv6, "metrics", ready),
+			},
+			wantError:  "no ready IPv6 endpoints available on port https/443",
+			wantHealth: []types.EndpointHealth{},
+		},
+		{
+			name:      "webhook port not exposed",
+			port:      int32Ptr(9443),
+			wantError: "service system/webhook has no port 9443",
+		},
+		{
+			name: "falls back to endpoints",
+			objects: []runtime.Object{
+				&corev1.Endpoints{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "system", Name: "webhook"},
+					Subsets: []corev1.EndpointSubset{{
+						Addresses:         []corev1.EndpointAddress{{IP: "fd00::1"}},
+						NotReadyAddresses: []corev1.EndpointAddress{{IP: "10.0.0.1"}},
+						Ports:             []corev1.EndpointPort{{Name: "https", Port: 8443}},
+					}},
+				},
+			},
+			wantHealthy: true,
+			wantHealth: []types.EndpointHealth{
+				{AddressType: "IPv4", Port: 443, PortName: "https", Total: 1},
+				{AddressType: "IPv6", Port: 443, PortName: "https", Ready: 1, Serving: 1, Total: 1},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clientset := fake.NewSimpleClientset(svc)
+			for _, obj := range tt.objects {
+				assert.NoError(t, clientset.Tracker().Add(obj))
+			}
+			d := NewWebhookDetector(&kube.Client{Clientset: clientset})
+
+			healthy, errMsg, health := d.checkServiceHealth(context.Background(), &admissionv1.ServiceReference{
+				Namespace: "system",
+				Name:      "webhook",
+				Port:      tt.port,
+			})
+			assert.Equal(t, tt.wantHealthy, healthy)
+			assert.Equal(t, tt.wantError, errMsg)
+			assert.Equal(t, tt.wantHealth, health)
+		})
+	}
+}
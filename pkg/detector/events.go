@@ -0,0 +1,186 @@
+package detector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+
+	eventsv1 "k8s.io/api/events/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/sozercan/unstuck/pkg/kube"
+	"github.com/sozercan/unstuck/pkg/types"
+)
+
+// maxEventsPerObject limits how many warnings are attached per target or blocker
+const maxEventsPerObject = 3
+
+// CloudProviderErrorPatterns contains patterns to match cloud provider failures in event notes
+var CloudProviderErrorPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)(SyncLoadBalancerFailed|DeletingLoadBalancerFailed|EnsuringLoadBalancer.*failed)`),
+	regexp.MustCompile(`(?i)(UnauthorizedOperation|AccessDenied|AuthorizationFailed|InvalidClientTokenId|ExpiredToken)`),
+	regexp.MustCompile(`(?i)(RequestLimitExceeded|Throttling|TooManyRequests|QuotaExceeded)`),
+	regexp.MustCompile(`(?i)(googleapi: Error|azure.*(StatusCode|RetryError)|AWS Error|aws: )`),
+}
+
+// EventDetector correlates Kubernetes Events with the target and its blockers
+type EventDetector struct {
+	client *kube.Client
+}
+
+// NewEventDetector creates a new event detector
+func NewEventDetector(client *kube.Client) *EventDetector {
+	return &EventDetector{client: client}
+}
+
+// WarningsFor returns the most recent Warning events regarding the given objects, newest first
+func (d *EventDetector) WarningsFor(ctx context.Context, refs []types.ResourceRef) ([]types.EventSummary, error) {
+	byNamespace := make(map[string][]eventsv1.Event)
+	var summaries []types.EventSummary
+
+	for _, ref := range refs {
+		// Events for cluster-scoped objects are recorded in the default namespace
+		ns := ref.Namespace
+		if ns == "" {
+			ns = metav1.NamespaceDefault
+		}
+
+		events, ok := byNamespace[ns]
+		if !ok {
+			list, err := d.client.Clientset.EventsV1().Events(ns).List(ctx, metav1.ListOptions{
+				FieldSelector: "type=Warning",
+			})
+			if err != nil {
+				return nil, fmt.Errorf("failed to list events in %s: %w", ns, err)
+			}
+			events = list.Items
+			byNamespace[ns] = events
+		}
+
+		var matched []types.EventSummary
+		for _, e := range events {
+			if e.Type != "Warning" || !eventRegards(e, ref) {
+				continue
+			}
+			matched = append(matched, summarizeEvent(e, ref))
+		}
+		sortEventsNewestFirst(matched)
+		if len(matched) > maxEventsPerObject {
+			matched = matched[:maxEventsPerObject]
+		}
+		summaries = append(summaries, matched...)
+	}
+
+	sortEventsNewestFirst(summaries)
+	return summaries, nil
+}
+
+// ClassifyEventNote classifies an event note as a webhook or cloud provider failure
+func ClassifyEventNote(note string) string {
+	if isWebhook, errType := IsWebhookError(errors.New(note)); isWebhook {
+		return errType
+	}
+	for _, pattern := range CloudProviderErrorPatterns {
+		if pattern.MatchString(note) {
+			return "cloud_provider_error"
+		}
+	}
+	return ""
+}
+
+// correlateEvents attaches recent warnings to the report and promotes a classified
+// failure to the root cause if no other analysis found one
+func correlateEvents(ctx context.Context, client *kube.Client, report *types.DiagnosisReport) {
+	refs := []types.ResourceRef{report.Target}
+	for _, b := range report.Blockers {
+		refs = append(refs, b.ResourceRef)
+	}
+
+	events, err := NewEventDetector(client).WarningsFor(ctx, refs)
+	if err != nil {
+		return
+	}
+	report.Events = events
+
+	// GC issues are explained on each blocker and take precedence over its events there too.
+	// Generic namespace condition text says less than a classified event, so it gives way
+	if (report.RootCause != "" && !isGenericConditionCause(report.RootCause)) || report.HasGCIssues() {
+		return
+	}
+	if cause := eventRootCause(events); cause != "" {
+		report.RootCause = cause
+	}
+}
+
+// eventRootCause builds a root cause from the most recent classified event
+func eventRootCause(events []types.EventSummary) string {
+	for _, e := range events {
+		switch e.Classification {
+		case "":
+			continue
+		case "cloud_provider_error":
+			return fmt.Sprintf("Cloud provider failure on %s - %s: %s", e.Regarding.String(), e.Reason, truncateNote(e.Note))
+		case "policy_denied":
+			return fmt.Sprintf("Admission policy denied update of %s - %s", e.Regarding.String(), truncateNote(e.Note))
+		default:
+			cause := fmt.Sprintf("Webhook failure on %s - %s", e.Regarding.String(), WebhookErrorTypeDescription(e.Classification))
+			if name := ExtractWebhookNameFromError(errors.New(e.Note)); name != "" {
+				cause += fmt.Sprintf(" (webhook %q)", name)
+			}
+			return cause
+		}
+	}
+	return ""
+}
+
+func eventRegards(e eventsv1.Event, ref types.ResourceRef) bool {
+	if e.Regarding.Name != ref.Name || e.Regarding.Kind != ref.Kind {
+		return false
+	}
+	return ref.Namespace == "" || e.Regarding.Namespace == ref.Namespace
+}
+
+func summarizeEvent(e eventsv1.Event, ref types.ResourceRef) types.EventSummary {
+	summary := types.EventSummary{
+		Regarding:      ref,
+		Reason:         e.Reason,
+		Note:           e.Note,
+		Count:          1,
+		Reporter:       e.ReportingController,
+		Classification: ClassifyEventNote(e.Reason + ": " + e.Note),
+	}
+	if summary.Reporter == "" {
+		summary.Reporter = e.DeprecatedSource.Component
+	}
+
+	switch {
+	case e.Series != nil:
+		summary.Count = e.Series.Count
+		summary.LastSeen = e.Series.LastObservedTime.Time
+	case !e.DeprecatedLastTimestamp.IsZero():
+		summary.LastSeen = e.DeprecatedLastTimestamp.Time
+		if e.DeprecatedCount > 0 {
+			summary.Count = e.DeprecatedCount
+		}
+	case !e.EventTime.IsZero():
+		summary.LastSeen = e.EventTime.Time
+	default:
+		summary.LastSeen = e.CreationTimestamp.Time
+	}
+	return summary
+}
+
+func sortEventsNewestFirst(events []types.EventSummary) {
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].LastSeen.After(events[j].LastSeen)
+	})
+}
+
+func truncateNote(note string) string {
+	if len(note) <= 120 {
+		return note
+	}
+	return note[:117] + "..."
+}
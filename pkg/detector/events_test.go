@@ -0,0 +1,150 @@
+package detector
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	eventsv1 "k8s.io/api/events/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/sozercan/unstuck/pkg/kube"
+	"github.com/sozercan/unstuck/pkg/types"
+)
+
+func newWarningEvent(name, namespace, kind, objName, reason, note string, at time.Time) *eventsv1.Event {
+	return &eventsv1.Event{
+		ObjectMeta:              metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Type:                    "Warning",
+		Reason:                  reason,
+		Note:                    note,
+		Regarding:               corev1.ObjectReference{Kind: kind, Name: objName, Namespace: namespace},
+		DeprecatedLastTimestamp: metav1.NewTime(at),
+	}
+}
+
+func TestEventDetector_WarningsFor(t *testing.T) {
+	now := time.Now()
+	clientset := fake.NewSimpleClientset(
+		newWarningEvent("e1", "apps", "Widget", "w1", "FailedDelete", "old failure", now.Add(-time.Hour)),
+		newWarningEvent("e2", "apps", "Widget", "w1", "FailedDelete",
+			`Internal error occurred: failed calling webhook "validate.example.com": connection refused`, now),
+		newWarningEvent("e3", "apps", "Widget", "other", "FailedDelete", "unrelated", now),
+		&eventsv1.Event{
+			ObjectMeta: metav1.ObjectMeta{Name: "e4", Namespace: "apps"},
+			Type:       "Normal",
+			Reason:     "Deleting",
+			Regarding:  corev1.ObjectReference{Kind: "Widget", Name: "w1", Namespace: "apps"},
+		},
+	)
+	d := NewEventDetector(&kube.Client{Clientset: clientset})
+
+	events, err := d.WarningsFor(context.Background(), []types.ResourceRef{
+		{Kind: "Widget", Namespace: "apps", Name: "w1"},
+	})
+
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	assert.Equal(t, "w1", events[0].Regarding.Name)
+	assert.Equal(t, "webhook_failed", events[0].Classification)
+	assert.Equal(t, "old failure", events[1].Note)
+	assert.Empty(t, events[1].Classification)
+}
+
+func TestClassifyEventNote(t *testing.T) {
+	tests := []struct {
+		name     string
+		note     string
+		expected string
+	}{
+		{"webhook denied", `admission webhook "deny.example.com" denied the request`, "webhook_denied"},
+		{"load balancer", "DeletingLoadBalancerFailed: Error deleting load balancer", "cloud_provider_error"},
+		{"aws auth", "UnauthorizedOperation: You are not authorized to perform this operation", "cloud_provider_error"},
+		{"admission policy", `widgets.example.com "w1" is forbidden: ValidatingAdmissionPolicy 'protect-widgets' with binding 'protect-widgets-binding' denied request: finalizers are immutable`, "policy_denied"},
+		{"plain", "FailedCleanup: still waiting", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, ClassifyEventNote(tt.note))
+		})
+	}
+}
+
+func TestEventRootCause(t *testing.T) {
+	ref := types.ResourceRef{Kind: "Widget", Namespace: "apps", Name: "w1"}
+
+	tests := []struct {
+		name     string
+		events   []types.EventSummary
+		expected string
+	}{
+		{"no events", nil, ""},
+		{"unclassified", []types.EventSummary{{Regarding: ref, Reason: "FailedDelete"}}, ""},
+		{
+			"webhook",
+			[]types.EventSummary{{Regarding: ref, Note: `admission webhook "deny.example.com" denied the request`, Classification: "webhook_denied"}},
+			`Webhook failure on Widget/apps/w1 - The webhook actively denied the request (webhook "deny.example.com")`,
+		},
+		{
+			"cloud",
+			[]types.EventSummary{{Regarding: ref, Reason: "DeletingLoadBalancerFailed", Note: "quota", Classification: "cloud_provider_error"}},
+			"Cloud provider failure on Widget/apps/w1 - DeletingLoadBalancerFailed: quota",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, eventRootCause(tt.events))
+		})
+	}
+}
+
+func TestCorrelateEvents_KeepsSpecificRootCause(t *testing.T) {
+	clientset := fake.NewSimpleClientset(
+		newWarningEvent("e1", "apps", "Widget", "w1", "FailedDelete",
+			`Internal error occurred: failed calling webhook "validate.example.com": connection refused`, time.Now()),
+	)
+	client := &kube.Client{Clientset: clientset}
+	blockers := []types.Blocker{{ResourceRef: types.ResourceRef{Kind: "Widget", Namespace: "apps", Name: "w1"}}}
+
+	specific := &types.DiagnosisReport{
+		Target:    types.ResourceRef{Kind: "Namespace", Name: "apps"},
+		Blockers:  blockers,
+		RootCause: "Corrupt objects - 1 object(s) cannot be read from storage",
+	}
+	correlateEvents(context.Background(), client, specific)
+	assert.Len(t, specific.Events, 1)
+	assert.Equal(t, "Corrupt objects - 1 object(s) cannot be read from storage", specific.RootCause)
+
+	empty := &types.DiagnosisReport{Target: types.ResourceRef{Kind: "Namespace", Name: "apps"}, Blockers: blockers}
+	correlateEvents(context.Background(), client, empty)
+	assert.Contains(t, empty.RootCause, "Webhook failure on Widget/apps/w1")
+}
+
+func TestCorrelateEvents_ReplacesGenericConditionCause(t *testing.T) {
+	clientset := fake.NewSimpleClientset(
+		newWarningEvent("e1", "apps", "Widget", "w1", "FailedDelete",
+			`Internal error occurred: failed calling webhook "validate.example.com": connection refused`, time.Now()),
+	)
+	client := &kube.Client{Clientset: clientset}
+	report := &types.DiagnosisReport{
+		Target:    types.ResourceRef{Kind: "Namespace", Name: "apps"},
+		Blockers:  []types.Blocker{{ResourceRef: types.ResourceRef{Kind: "Widget", Namespace: "apps", Name: "w1"}}},
+		RootCause: "CR instances stuck with unsatisfied finalizers",
+	}
+	correlateEvents(context.Background(), client, report)
+	assert.Contains(t, report.RootCause, "Webhook failure on Widget/apps/w1")
+
+	// Without a classified event the condition cause stays
+	quiet := &types.DiagnosisReport{
+		Target:    types.ResourceRef{Kind: "Namespace", Name: "apps"},
+		RootCause: "Resources remaining in namespace",
+	}
+	correlateEvents(context.Background(), &kube.Client{Clientset: fake.NewSimpleClientset()}, quiet)
+	assert.Equal(t, "Resources remaining in namespace", quiet.RootCause)
+}
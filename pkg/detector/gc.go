@@ -0,0 +1,150 @@
+package detector
+
+import (
+	"context"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/sozercan/unstuck/pkg/kube"
+	"github.com/sozercan/unstuck/pkg/types"
+)
+
+// GCDetector finds blockers that are waiting on the garbage collector rather than a controller
+type GCDetector struct {
+	client *kube.Client
+	mapper *resourceMapper
+}
+
+// NewGCDetector creates a new garbage collector detector
+func NewGCDetector(client *kube.Client) *GCDetector {
+	return &GCDetector{client: client, mapper: newResourceMapper(client)}
+}
+
+// Analyze flags GC finalizers and resolves every owner reference UID of the blockers.
+// Owners that cannot be looked up for other reasons (e.g. RBAC) are not reported.
+func (d *GCDetector) Analyze(ctx context.Context, blockers []types.Blocker) []types.GCIssue {
+	var issues []types.GCIssue
+
+	for _, b := range blockers {
+		for _, f := range b.Finalizers {
+			switch f {
+			case metav1.FinalizerOrphanDependents:
+				issues = append(issues, types.GCIssue{
+					Type:      types.GCIssueOrphanFinalizer,
+					Object:    b.ResourceRef,
+					Finalizer: f,
+					Message:   "Garbage collector has not orphaned the dependents; check kube-controller-manager and unavailable aggregated APIs",
+				})
+			case metav1.FinalizerDeleteDependents:
+				issues = append(issues, types.GCIssue{
+					Type:      types.GCIssueForegroundFinalizer,
+					Object:    b.ResourceRef,
+					Finalizer: f,
+					Message:   foregroundMessage(b, blockers),
+				})
+			}
+		}
+
+		for _, owner := range b.OwnerReferences {
+			exists, err := d.ownerExists(ctx, owner, b.Namespace)
+			if err != nil || exists {
+				continue
+			}
+			ref := types.ResourceRef{Kind: owner.Kind, APIVersion: owner.APIVersion, Name: owner.Name}
+			if _, namespaced, _ := d.mapper.resourceFor(owner.APIVersion, owner.Kind); namespaced {
+				ref.Namespace = b.Namespace
+			}
+			issues = append(issues, types.GCIssue{
+				Type:     types.GCIssueDanglingOwner,
+				Object:   b.ResourceRef,
+				Owner:    &ref,
+				OwnerUID: string(owner.UID),
+				Message:  fmt.Sprintf("Owner %s (uid %s) no longer exists", ref.String(), owner.UID),
+			})
+		}
+	}
+
+	return issues
+}
+
+// ownerExists returns true if an object with the owner reference's UID exists
+func (d *GCDetector) ownerExists(ctx context.Context, owner metav1.OwnerReference, namespace string) (bool, error) {
+	gvr, namespaced, err := d.mapper.resourceFor(owner.APIVersion, owner.Kind)
+	if err != nil {
+		return false, err
+	}
+	// The owner's type is no longer served, so the owner cannot exist
+	if gvr.Empty() {
+		return false, nil
+	}
+
+	// Namespaced owners must live in the dependent's namespace
+	if !namespaced {
+		namespace = ""
+	}
+
+	obj, err := d.client.Dynamic.Resource(gvr).Namespace(namespace).Get(ctx, owner.Name, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	// An owner recreated under the same name does not own the dependent
+	return obj.GetUID() == owner.UID, nil
+}
+
+// foregroundMessage explains what a foreground deletion is waiting for
+func foregroundMessage(b types.Blocker, blockers []types.Blocker) string {
+	dependents := 0
+	for _, other := range blockers {
+		for _, owner := range other.OwnerReferences {
+			if string(owner.UID) == b.UID && owner.BlockOwnerDeletion != nil && *owner.BlockOwnerDeletion {
+				dependents++
+				break
+			}
+		}
+	}
+	if dependents > 0 {
+		return fmt.Sprintf("Foreground deletion is waiting for %d dependent(s) with blockOwnerDeletion", dependents)
+	}
+	return "Foreground deletion is waiting for the garbage collector to delete blocking dependents"
+}
+
+// analyzeGarbageCollection attaches GC issues to the report
+func analyzeGarbageCollection(ctx context.Context, client *kube.Client, report *types.DiagnosisReport) {
+	report.GCIssues = NewGCDetector(client).Analyze(ctx, report.Blockers)
+}
+
+// gcRecommendations explains how GC issues are handled by the plan
+func gcRecommendations(report *types.DiagnosisReport) []string {
+	if !report.HasGCIssues() {
+		return nil
+	}
+
+	dangling, finalizers := 0, 0
+	for _, issue := range report.GCIssues {
+		if issue.Type == types.GCIssueDanglingOwner {
+			dangling++
+		} else {
+			finalizers++
+		}
+	}
+
+	var recs []string
+	if finalizers > 0 {
+		recs = append(recs, fmt.Sprintf(
+			"%d blocker(s) carry garbage collector finalizers (orphan, foregroundDeletion). These are processed by kube-controller-manager, not an operator; the plan removes only those finalizers.",
+			finalizers,
+		))
+	}
+	if dangling > 0 {
+		recs = append(recs, fmt.Sprintf(
+			"%d owner reference(s) point at objects that no longer exist. The plan drops those references instead of stripping finalizers.",
+			dangling,
+		))
+	}
+	return recs
+}
@@ -0,0 +1,90 @@
+package detector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/sozercan/unstuck/pkg/kube"
+	"github.com/sozercan/unstuck/pkg/types"
+)
+
+func TestGCDetector_Analyze(t *testing.T) {
+	owner := &unstructured.Unstructured{}
+	owner.SetAPIVersion("apps/v1")
+	owner.SetKind("ReplicaSet")
+	owner.SetNamespace("apps")
+	owner.SetName("web-7d9")
+	owner.SetUID("live-uid")
+
+	clientset := fake.NewSimpleClientset()
+	clientset.Discovery().(*fakediscovery.FakeDiscovery).Resources = []*metav1.APIResourceList{
+		{
+			GroupVersion: "apps/v1",
+			APIResources: []metav1.APIResource{{Name: "replicasets", Kind: "ReplicaSet", Namespaced: true}},
+		},
+	}
+	client := &kube.Client{
+		Clientset: clientset,
+		Discovery: clientset.Discovery(),
+		Dynamic:   dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), owner),
+	}
+
+	blockTrue := true
+	pod := types.ResourceRef{Kind: "Pod", APIVersion: "v1", Namespace: "apps", Name: "web-7d9-abc"}
+	widget := types.ResourceRef{Kind: "Widget", APIVersion: "example.com/v1", Namespace: "apps", Name: "w1"}
+	blockers := []types.Blocker{
+		{
+			ResourceRef: pod,
+			Finalizers:  []string{metav1.FinalizerOrphanDependents},
+			OwnerReferences: []metav1.OwnerReference{
+				{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: "web-7d9", UID: "live-uid"},
+				{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: "web-old", UID: "gone-uid"},
+			},
+		},
+		{
+			ResourceRef: widget,
+			UID:         "widget-uid",
+			Finalizers:  []string{metav1.FinalizerDeleteDependents},
+			OwnerReferences: []metav1.OwnerReference{
+				// The owner's API is no longer served
+				{APIVersion: "legacy.example.com/v1", Kind: "Gadget", Name: "g1", UID: "gadget-uid"},
+			},
+		},
+		{
+			ResourceRef: types.ResourceRef{Kind: "Widget", APIVersion: "example.com/v1", Namespace: "apps", Name: "w2"},
+			OwnerReferences: []metav1.OwnerReference{
+				{APIVersion: "example.com/v1", Kind: "Widget", Name: "w1", UID: k8stypes.UID("widget-uid"), BlockOwnerDeletion: &blockTrue},
+			},
+		},
+	}
+
+	issues := NewGCDetector(client).Analyze(context.Background(), blockers)
+
+	require.Len(t, issues, 5)
+	assert.Equal(t, types.GCIssueOrphanFinalizer, issues[0].Type)
+	assert.Equal(t, pod, issues[0].Object)
+
+	assert.Equal(t, types.GCIssueDanglingOwner, issues[1].Type)
+	assert.Equal(t, "gone-uid", issues[1].OwnerUID)
+	assert.Equal(t, "apps", issues[1].Owner.Namespace)
+
+	assert.Equal(t, types.GCIssueForegroundFinalizer, issues[2].Type)
+	assert.Equal(t, "Foreground deletion is waiting for 1 dependent(s) with blockOwnerDeletion", issues[2].Message)
+
+	assert.Equal(t, types.GCIssueDanglingOwner, issues[3].Type)
+	assert.Equal(t, "gadget-uid", issues[3].OwnerUID)
+
+	// w2's owner w1 cannot be listed (example.com/v1 is not discovered), so it is dangling too
+	assert.Equal(t, types.GCIssueDanglingOwner, issues[4].Type)
+	assert.Equal(t, "widget-uid", issues[4].OwnerUID)
+}
@@ -0,0 +1,365 @@
+package detector
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/sozercan/unstuck/pkg/kube"
+	"github.com/sozercan/unstuck/pkg/types"
+)
+
+const (
+	// argoResourcesFinalizer deletes an Application's resources; /foreground and /background
+	// variants select the propagation policy
+	argoResourcesFinalizer = "resources-finalizer.argocd.argoproj.io"
+	// argoPostDeleteFinalizer waits for post-delete hooks
+	argoPostDeleteFinalizer = "post-delete-finalizer.argocd.argoproj.io"
+	argoGroup               = "argoproj.io"
+	// argoInClusterServer is the destination server of the cluster Argo CD runs in
+	argoInClusterServer = "https://kubernetes.default.svc"
+	// argoClusterSecretSelector selects the Secrets Argo CD stores registered clusters in
+	argoClusterSecretSelector = "argocd.argoproj.io/secret-type=cluster"
+	// argoSyncOptionsAnnotation with Delete=false keeps a resource when its Application is deleted
+	argoSyncOptionsAnnotation = "argocd.argoproj.io/sync-options"
+
+	fluxFinalizer          = "finalizers.fluxcd.io"
+	fluxKustomizationGroup = "kustomize.toolkit.fluxcd.io"
+	fluxHelmGroup          = "helm.toolkit.fluxcd.io"
+	// fluxPruneAnnotation set to disabled keeps a resource when its Kustomization is deleted
+	fluxPruneAnnotation = "kustomize.toolkit.fluxcd.io/prune"
+
+	// maxManagedResourceChecks limits how many managed resources are looked up per object
+	maxManagedResourceChecks = 100
+)
+
+// GitOpsDetector reads the status of Argo CD Applications and Flux Kustomizations and
+// HelmReleases to explain why their deletion cascade has not finished
+type GitOpsDetector struct {
+	client *kube.Client
+	mapper *resourceMapper
+}
+
+// NewGitOpsDetector creates a new GitOps detector
+func NewGitOpsDetector(client *kube.Client) *GitOpsDetector {
+	return &GitOpsDetector{client: client, mapper: newResourceMapper(client)}
+}
+
+// Analyze sets GitOps on blockers carrying an Argo CD or Flux finalizer. Objects that cannot be
+// read are skipped.
+func (d *GitOpsDetector) Analyze(ctx context.Context, blockers []types.Blocker, controllers []types.ControllerStatus) {
+	for i := range blockers {
+		b := &blockers[i]
+		gv, _ := schema.ParseGroupVersion(b.APIVersion)
+
+		var info *types.GitOpsInfo
+		switch {
+		case gv.Group == argoGroup && b.Kind == "Application":
+			info = d.argoApplication(ctx, b, gv)
+		case gv.Group == fluxKustomizationGroup && b.Kind == "Kustomization":
+			info = d.fluxObject(ctx, b, gv, "kustomizations")
+		case gv.Group == fluxHelmGroup && b.Kind == "HelmRelease":
+			info = d.fluxObject(ctx, b, gv, "helmreleases")
+		}
+		if info == nil {
+			continue
+		}
+
+		info.ControllerState = types.ExternalControllerUnknown
+		for _, c := range controllers {
+			if !containsString(c.Finalizers, info.Finalizer) {
+				continue
+			}
+			info.Controller = c.Namespace + "/" + c.Name
+			if c.IsReconciling() {
+				info.ControllerState = types.ExternalControllerRunning
+			} else {
+				// Nothing else matters until the controller runs again
+				info.ControllerState = types.ExternalControllerNotRunning
+				info.StuckReason = fmt.Sprintf("%s %s is not reconciling", c.Kind, info.Controller)
+			}
+			break
+		}
+		if info.StuckReason == "" {
+			info.StuckReason = remainingReason(info)
+		}
+		b.GitOps = info
+	}
+}
+
+// argoApplication reads an Argo CD Application's destination, sync and health status and the
+// resources it deployed
+func (d *GitOpsDetector) argoApplication(ctx context.Context, b *types.Blocker, gv schema.GroupVersion) *types.GitOpsInfo {
+	finalizer := ""
+	for _, f := range b.Finalizers {
+		if strings.HasPrefix(f, argoResourcesFinalizer) || f == argoPostDeleteFinalizer {
+			finalizer = f
+			break
+		}
+	}
+	if finalizer == "" {
+		return nil
+	}
+	app, err := d.client.Dynamic.Resource(gv.WithResource("applications")).Namespace(b.Namespace).Get(ctx, b.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil
+	}
+
+	info := &types.GitOpsInfo{
+		Tool:      types.GitOpsArgoCD,
+		Finalizer: finalizer,
+		Prune:     strings.HasPrefix(finalizer, argoResourcesFinalizer),
+	}
+	info.SyncStatus, _, _ = unstructured.NestedString(app.Object, "status", "sync", "status")
+	info.HealthStatus, _, _ = unstructured.NestedString(app.Object, "status", "health", "status")
+
+	server, _, _ := unstructured.NestedString(app.Object, "spec", "destination", "server")
+	name, _, _ := unstructured.NestedString(app.Object, "spec", "destination", "name")
+	info.Destination = server
+	if info.Destination == "" {
+		info.Destination = name
+	}
+	info.InCluster = server == argoInClusterServer || name == "in-cluster"
+	if !info.InCluster {
+		info.DestinationIssue = d.argoClusterIssue(ctx, server, name)
+	}
+
+	resources, _, _ := unstructured.NestedSlice(app.Object, "status", "resources")
+	var refs []types.ResourceRef
+	for _, r := range resources {
+		m, ok := r.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		group, _ := m["group"].(string)
+		version, _ := m["version"].(string)
+		ref := types.ResourceRef{APIVersion: schema.GroupVersion{Group: group, Version: version}.String()}
+		ref.Kind, _ = m["kind"].(string)
+		ref.Namespace, _ = m["namespace"].(string)
+		ref.Name, _ = m["name"].(string)
+		refs = append(refs, ref)
+	}
+	info.ManagedCount = len(refs)
+	if info.InCluster {
+		info.Remaining = d.remainingResources(ctx, refs, func(obj *unstructured.Unstructured) bool {
+			return strings.Contains(obj.GetAnnotations()[argoSyncOptionsAnnotation], "Delete=false")
+		})
+	}
+
+	conditions, _, _ := unstructured.NestedSlice(app.Object, "status", "conditions")
+	for _, c := range conditions {
+		m, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		condType, _ := m["type"].(string)
+		msg, _ := m["message"].(string)
+		if condType == "DeletionError" {
+			info.StuckReason = "DeletionError: " + truncateNote(msg)
+			break
+		}
+	}
+
+	switch {
+	case info.DestinationIssue != "":
+		info.StuckReason = info.DestinationIssue
+	case info.StuckReason == "" && finalizer == argoPostDeleteFinalizer:
+		info.StuckReason = "post-delete hooks have not completed"
+	}
+	return info
+}
+
+// argoClusterIssue checks that an external destination cluster is registered with Argo CD.
+// Registered clusters are stored as labeled Secrets, usually in the argocd namespace.
+func (d *GitOpsDetector) argoClusterIssue(ctx context.Context, server, name string) string {
+	secrets, err := d.client.Clientset.CoreV1().Secrets(metav1.NamespaceAll).List(ctx, metav1.ListOptions{
+		LabelSelector: argoClusterSecretSelector,
+	})
+	if err != nil {
+		return ""
+	}
+	for _, s := range secrets.Items {
+		if (server != "" && string(s.Data["server"]) == server) || (name != "" && string(s.Data["name"]) == name) {
+			return ""
+		}
+	}
+	destination := server
+	if destination == "" {
+		destination = name
+	}
+	return fmt.Sprintf("destination cluster %s is not registered with Argo CD, so its resources cannot be deleted", destination)
+}
+
+// fluxObject reads a Flux Kustomization's or HelmRelease's Ready condition, target cluster and
+// inventory
+func (d *GitOpsDetector) fluxObject(ctx context.Context, b *types.Blocker, gv schema.GroupVersion, resource string) *types.GitOpsInfo {
+	if !containsString(b.Finalizers, fluxFinalizer) {
+		return nil
+	}
+	obj, err := d.client.Dynamic.Resource(gv.WithResource(resource)).Namespace(b.Namespace).Get(ctx, b.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil
+	}
+
+	info := &types.GitOpsInfo{
+		Tool:        types.GitOpsFlux,
+		Finalizer:   fluxFinalizer,
+		Destination: "in-cluster",
+		InCluster:   true,
+		Prune:       true,
+	}
+	if b.Kind == "Kustomization" {
+		info.Prune, _, _ = unstructured.NestedBool(obj.Object, "spec", "prune")
+	}
+	// Suspended objects are released without running the cascade
+	if suspended, _, _ := unstructured.NestedBool(obj.Object, "spec", "suspend"); suspended {
+		info.Prune = false
+	}
+
+	if secret, _, _ := unstructured.NestedString(obj.Object, "spec", "kubeConfig", "secretRef", "name"); secret != "" {
+		info.Destination = "kubeConfig " + b.Namespace + "/" + secret
+		info.InCluster = false
+		if _, err := d.client.Clientset.CoreV1().Secrets(b.Namespace).Get(ctx, secret, metav1.GetOptions{}); apierrors.IsNotFound(err) {
+			info.DestinationIssue = fmt.Sprintf("kubeConfig secret %s/%s not found, so the remote cluster cannot be reached", b.Namespace, secret)
+		}
+	}
+	if sa, _, _ := unstructured.NestedString(obj.Object, "spec", "serviceAccountName"); sa != "" && info.InCluster {
+		if _, err := d.client.Clientset.CoreV1().ServiceAccounts(b.Namespace).Get(ctx, sa, metav1.GetOptions{}); apierrors.IsNotFound(err) {
+			info.DestinationIssue = fmt.Sprintf("service account %s/%s used for impersonation not found, so managed resources cannot be deleted", b.Namespace, sa)
+		}
+	}
+
+	conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	for _, c := range conditions {
+		m, ok := c.(map[string]interface{})
+		if !ok || m["type"] != "Ready" {
+			continue
+		}
+		info.HealthStatus, _ = m["status"].(string)
+		info.SyncStatus, _ = m["reason"].(string)
+		if info.HealthStatus == string(metav1.ConditionFalse) {
+			msg, _ := m["message"].(string)
+			info.StuckReason = info.SyncStatus + ": " + truncateNote(msg)
+		}
+	}
+	if info.DestinationIssue != "" {
+		info.StuckReason = info.DestinationIssue
+	}
+
+	// HelmReleases are uninstalled by helm-controller and keep no inventory of their resources
+	entries, _, _ := unstructured.NestedSlice(obj.Object, "status", "inventory", "entries")
+	var refs []types.ResourceRef
+	for _, e := range entries {
+		m, ok := e.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		id, _ := m["id"].(string)
+		version, _ := m["v"].(string)
+		if ref, ok := parseFluxInventoryID(id, version); ok {
+			refs = append(refs, ref)
+		}
+	}
+	info.ManagedCount = len(refs)
+	if info.InCluster {
+		info.Remaining = d.remainingResources(ctx, refs, func(obj *unstructured.Unstructured) bool {
+			return obj.GetAnnotations()[fluxPruneAnnotation] == "disabled"
+		})
+	}
+	return info
+}
+
+// parseFluxInventoryID parses a Flux inventory entry ID of the form <namespace>_<name>_<group>_<kind>
+func parseFluxInventoryID(id, version string) (types.ResourceRef, bool) {
+	parts := strings.Split(id, "_")
+	if len(parts) != 4 || version == "" {
+		return types.ResourceRef{}, false
+	}
+	return types.ResourceRef{
+		APIVersion: schema.GroupVersion{Group: parts[2], Version: version}.String(),
+		Kind:       parts[3],
+		Namespace:  parts[0],
+		Name:       parts[1],
+	}, true
+}
+
+// remainingResources returns the managed resources that still exist. Resources whose type is no
+// longer served or that cannot be read are skipped.
+func (d *GitOpsDetector) remainingResources(ctx context.Context, refs []types.ResourceRef, keep func(*unstructured.Unstructured) bool) []types.ManagedResource {
+	var remaining []types.ManagedResource
+	for i, ref := range refs {
+		if i >= maxManagedResourceChecks {
+			break
+		}
+		gvr, namespaced, err := d.mapper.resourceFor(ref.APIVersion, ref.Kind)
+		if err != nil || gvr.Empty() {
+			continue
+		}
+		namespace := ref.Namespace
+		if !namespaced {
+			namespace = ""
+		}
+		obj, err := d.client.Dynamic.Resource(gvr).Namespace(namespace).Get(ctx, ref.Name, metav1.GetOptions{})
+		if err != nil {
+			continue
+		}
+		ref.Namespace = namespace
+		remaining = append(remaining, types.ManagedResource{
+			ResourceRef: ref,
+			Terminating: obj.GetDeletionTimestamp() != nil,
+			Keep:        keep(obj),
+		})
+	}
+	return remaining
+}
+
+// remainingReason explains a stuck cascade from the managed resources that are left
+func remainingReason(info *types.GitOpsInfo) string {
+	terminating := 0
+	for _, r := range info.Remaining {
+		if r.Terminating {
+			terminating++
+		}
+	}
+	if terminating > 0 {
+		return fmt.Sprintf("waiting for %d managed resource(s) that are still terminating", terminating)
+	}
+	return ""
+}
+
+// analyzeGitOps attaches the state of Argo CD and Flux deletion cascades to the blockers
+func analyzeGitOps(ctx context.Context, client *kube.Client, report *types.DiagnosisReport) {
+	NewGitOpsDetector(client).Analyze(ctx, report.Blockers, report.Controllers)
+}
+
+// gitOpsRecommendations explains why GitOps cascades are stuck and what removing their finalizers leaves behind
+func gitOpsRecommendations(report *types.DiagnosisReport) []string {
+	var recs []string
+	for _, b := range report.Blockers {
+		g := b.GitOps
+		if g == nil {
+			continue
+		}
+
+		rec := fmt.Sprintf("%s %s is held by %s", g.Tool, b.ResourceRef.String(), g.Finalizer)
+		if g.StuckReason != "" {
+			rec += ": " + g.StuckReason
+		}
+		rec += "."
+		switch {
+		case !g.Prune:
+			rec += " Its managed resources are kept when it is deleted, so removing the finalizer is safe."
+		case !g.InCluster:
+			rec += fmt.Sprintf(" Removing the finalizer leaves %d managed resource(s) in %s behind; delete them there first.", g.ManagedCount, g.Destination)
+		case len(g.CascadeCandidates()) > 0:
+			rec += fmt.Sprintf(" %d managed resource(s) still exist; the plan deletes them before removing the finalizer.", len(g.CascadeCandidates()))
+		}
+		recs = append(recs, rec)
+	}
+	return recs
+}
@@ -0,0 +1,157 @@
+package detector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/sozercan/unstuck/pkg/kube"
+	"github.com/sozercan/unstuck/pkg/types"
+)
+
+func gitOpsObject(apiVersion, kind, namespace, name string, spec, status map[string]interface{}) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": apiVersion,
+		"kind":       kind,
+		"metadata":   map[string]interface{}{"namespace": namespace, "name": name},
+		"spec":       spec,
+		"status":     status,
+	}}
+}
+
+func TestGitOpsDetector_Analyze(t *testing.T) {
+	inCluster := gitOpsObject("argoproj.io/v1alpha1", "Application", "argocd", "web", map[string]interface{}{
+		"destination": map[string]interface{}{"server": "https://kubernetes.default.svc", "namespace": "apps"},
+	}, map[string]interface{}{
+		"sync":   map[string]interface{}{"status": "Synced"},
+		"health": map[string]interface{}{"status": "Healthy"},
+		"resources": []interface{}{
+			map[string]interface{}{"group": "apps", "version": "v1", "kind": "Deployment", "namespace": "apps", "name": "web"},
+			map[string]interface{}{"version": "v1", "kind": "ConfigMap", "namespace": "apps", "name": "web-config"},
+			map[string]interface{}{"version": "v1", "kind": "ConfigMap", "namespace": "apps", "name": "already-gone"},
+		},
+	})
+	remote := gitOpsObject("argoproj.io/v1alpha1", "Application", "argocd", "edge", map[string]interface{}{
+		"destination": map[string]interface{}{"server": "https://edge.example.com"},
+	}, map[string]interface{}{
+		"resources": []interface{}{
+			map[string]interface{}{"group": "apps", "version": "v1", "kind": "Deployment", "namespace": "apps", "name": "edge"},
+		},
+	})
+	kustomization := gitOpsObject("kustomize.toolkit.fluxcd.io/v1", "Kustomization", "flux-system", "apps", map[string]interface{}{
+		"prune":              true,
+		"serviceAccountName": "deployer",
+	}, map[string]interface{}{
+		"conditions": []interface{}{map[string]interface{}{"type": "Ready", "status": "False", "reason": "PruneFailed", "message": "forbidden"}},
+		"inventory": map[string]interface{}{"entries": []interface{}{
+			map[string]interface{}{"id": "apps_web_apps_Deployment", "v": "v1"},
+		}},
+	})
+
+	deployment := &unstructured.Unstructured{}
+	deployment.SetAPIVersion("apps/v1")
+	deployment.SetKind("Deployment")
+	deployment.SetNamespace("apps")
+	deployment.SetName("web")
+	configMap := &unstructured.Unstructured{}
+	configMap.SetAPIVersion("v1")
+	configMap.SetKind("ConfigMap")
+	configMap.SetNamespace("apps")
+	configMap.SetName("web-config")
+	configMap.SetAnnotations(map[string]string{argoSyncOptionsAnnotation: "Delete=false"})
+
+	dynamic := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		{Group: "argoproj.io", Version: "v1alpha1", Resource: "applications"}:             "ApplicationList",
+		{Group: "kustomize.toolkit.fluxcd.io", Version: "v1", Resource: "kustomizations"}: "KustomizationList",
+	}, inCluster, remote, kustomization, deployment, configMap)
+
+	clientset := fake.NewSimpleClientset(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "argocd",
+			Name:      "cluster-staging",
+			Labels:    map[string]string{"argocd.argoproj.io/secret-type": "cluster"},
+		},
+		Data: map[string][]byte{"server": []byte("https://staging.example.com")},
+	})
+	clientset.Discovery().(*fakediscovery.FakeDiscovery).Resources = []*metav1.APIResourceList{
+		{GroupVersion: "apps/v1", APIResources: []metav1.APIResource{{Name: "deployments", Kind: "Deployment", Namespaced: true}}},
+		{GroupVersion: "v1", APIResources: []metav1.APIResource{{Name: "configmaps", Kind: "ConfigMap", Namespaced: true}}},
+	}
+	client := &kube.Client{Clientset: clientset, Discovery: clientset.Discovery(), Dynamic: dynamic}
+
+	blocker := func(apiVersion, kind, namespace, name, finalizer string) types.Blocker {
+		return types.Blocker{
+			ResourceRef: types.ResourceRef{APIVersion: apiVersion, Kind: kind, Namespace: namespace, Name: name},
+			Finalizers:  []string{finalizer},
+		}
+	}
+	blockers := []types.Blocker{
+		blocker("argoproj.io/v1alpha1", "Application", "argocd", "web", "resources-finalizer.argocd.argoproj.io"),
+		blocker("argoproj.io/v1alpha1", "Application", "argocd", "edge", "resources-finalizer.argocd.argoproj.io/background"),
+		blocker("kustomize.toolkit.fluxcd.io/v1", "Kustomization", "flux-system", "apps", "finalizers.fluxcd.io"),
+	}
+	controllers := []types.ControllerStatus{{
+		Name:       "argocd-application-controller",
+		Namespace:  "argocd",
+		Kind:       "StatefulSet",
+		Finalizers: []string{"resources-finalizer.argocd.argoproj.io"},
+		Replicas:   0,
+	}}
+
+	NewGitOpsDetector(client).Analyze(context.Background(), blockers, controllers)
+
+	web := blockers[0].GitOps
+	require.NotNil(t, web)
+	assert.Equal(t, types.GitOpsArgoCD, web.Tool)
+	assert.True(t, web.InCluster)
+	assert.Equal(t, "Synced", web.SyncStatus)
+	assert.Equal(t, 3, web.ManagedCount)
+	require.Len(t, web.Remaining, 2)
+	assert.Equal(t, types.ExternalControllerNotRunning, web.ControllerState)
+	assert.Equal(t, "StatefulSet argocd/argocd-application-controller is not reconciling", web.StuckReason)
+	assert.Equal(t, []types.ResourceRef{{APIVersion: "apps/v1", Kind: "Deployment", Namespace: "apps", Name: "web"}}, web.CascadeCandidates(),
+		"resources annotated Delete=false are kept")
+
+	edge := blockers[1].GitOps
+	require.NotNil(t, edge)
+	assert.False(t, edge.InCluster)
+	assert.Empty(t, edge.Remaining)
+	assert.Equal(t, types.ExternalControllerUnknown, edge.ControllerState)
+	assert.Contains(t, edge.StuckReason, "https://edge.example.com is not registered with Argo CD")
+
+	apps := blockers[2].GitOps
+	require.NotNil(t, apps)
+	assert.Equal(t, types.GitOpsFlux, apps.Tool)
+	assert.True(t, apps.Prune)
+	assert.Equal(t, "PruneFailed", apps.SyncStatus)
+	assert.Contains(t, apps.StuckReason, "service account flux-system/deployer used for impersonation not found")
+	assert.Len(t, apps.CascadeCandidates(), 1)
+
+	recs := gitOpsRecommendations(&types.DiagnosisReport{Blockers: blockers})
+	require.Len(t, recs, 3)
+	assert.Contains(t, recs[0], "the plan deletes them before removing the finalizer")
+	assert.Contains(t, recs[1], "delete them there first")
+}
+
+func TestParseFluxInventoryID(t *testing.T) {
+	ref, ok := parseFluxInventoryID("apps_web_apps_Deployment", "v1")
+	require.True(t, ok)
+	assert.Equal(t, types.ResourceRef{APIVersion: "apps/v1", Kind: "Deployment", Namespace: "apps", Name: "web"}, ref)
+
+	ref, ok = parseFluxInventoryID("_apps__Namespace", "v1")
+	require.True(t, ok)
+	assert.Equal(t, types.ResourceRef{APIVersion: "v1", Kind: "Namespace", Name: "apps"}, ref)
+
+	_, ok = parseFluxInventoryID("malformed", "v1")
+	assert.False(t, ok)
+}
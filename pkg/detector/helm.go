@@ -0,0 +1,284 @@
+package detector
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/sozercan/unstuck/pkg/kube"
+	"github.com/sozercan/unstuck/pkg/types"
+)
+
+const (
+	helmManagedByLabel             = "app.kubernetes.io/managed-by"
+	helmReleaseNameAnnotation      = "meta.helm.sh/release-name"
+	helmReleaseNamespaceAnnotation = "meta.helm.sh/release-namespace"
+	// helmReleaseSelector selects the Secrets Helm stores release revisions in
+	helmReleaseSelector = "owner=helm"
+)
+
+// HelmDetector reads Helm release Secrets to find releases whose uninstall did not finish and
+// the hook Jobs they are waiting on
+type HelmDetector struct {
+	client *kube.Client
+}
+
+// NewHelmDetector creates a new Helm detector
+func NewHelmDetector(client *kube.Client) *HelmDetector {
+	return &HelmDetector{client: client}
+}
+
+// helmReleaseRecord is the part of a Helm v3 release record unstuck reads
+type helmReleaseRecord struct {
+	Name string `json:"name"`
+	Info struct {
+		Status      string `json:"status"`
+		Description string `json:"description"`
+	} `json:"info"`
+	Chart struct {
+		Metadata struct {
+			Name    string `json:"name"`
+			Version string `json:"version"`
+		} `json:"metadata"`
+	} `json:"chart"`
+	Version   int    `json:"version"`
+	Namespace string `json:"namespace"`
+	Hooks     []struct {
+		Name    string   `json:"name"`
+		Kind    string   `json:"kind"`
+		Events  []string `json:"events"`
+		LastRun struct {
+			Phase string `json:"phase"`
+		} `json:"last_run"`
+	} `json:"hooks"`
+}
+
+// Analyze returns the Helm releases that installed blockers and, when namespace is set, the
+// releases in that namespace whose last operation did not finish. Releases whose Secret is gone
+// are returned without a status.
+func (d *HelmDetector) Analyze(ctx context.Context, blockers []types.Blocker, namespace string) []types.HelmRelease {
+	releases := make(map[string]*types.HelmRelease)
+	release := func(ns, name string) *types.HelmRelease {
+		key := ns + "/" + name
+		if releases[key] == nil {
+			releases[key] = &types.HelmRelease{Name: name, Namespace: ns}
+		}
+		return releases[key]
+	}
+
+	for _, b := range blockers {
+		ns, name, ok := strings.Cut(b.HelmRelease, "/")
+		if !ok {
+			continue
+		}
+		r := release(ns, name)
+		r.Blockers = append(r.Blockers, b.ResourceRef)
+	}
+
+	// Releases stuck in uninstalling may have no blockers left, only a hook Job that never finished
+	if namespace != "" {
+		if secrets, err := d.client.Clientset.CoreV1().Secrets(namespace).List(ctx, metav1.ListOptions{LabelSelector: helmReleaseSelector}); err == nil {
+			for name, secret := range latestReleaseSecrets(secrets.Items) {
+				if (types.HelmRelease{Status: secret.Labels["status"]}).IsStuck() {
+					release(namespace, name)
+				}
+			}
+		}
+	}
+
+	var result []types.HelmRelease
+	for _, r := range releases {
+		d.readRelease(ctx, r)
+		result = append(result, *r)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].String() < result[j].String()
+	})
+	return result
+}
+
+// readRelease fills in a release from the Secret of its latest revision and checks its hook Jobs
+func (d *HelmDetector) readRelease(ctx context.Context, r *types.HelmRelease) {
+	secrets, err := d.client.Clientset.CoreV1().Secrets(r.Namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: helmReleaseSelector + ",name=" + r.Name,
+	})
+	if err != nil {
+		return
+	}
+	secret, ok := latestReleaseSecrets(secrets.Items)[r.Name]
+	if !ok {
+		return
+	}
+	r.Status = secret.Labels["status"]
+	r.Revision, _ = strconv.Atoi(secret.Labels["version"])
+
+	record, err := decodeHelmRelease(secret.Data["release"])
+	if err != nil {
+		return
+	}
+	r.Status = record.Info.Status
+	r.Description = record.Info.Description
+	r.Revision = record.Version
+	if record.Chart.Metadata.Name != "" {
+		r.Chart = record.Chart.Metadata.Name + "-" + record.Chart.Metadata.Version
+	}
+
+	for _, h := range record.Hooks {
+		if h.Kind != "Job" {
+			continue
+		}
+		job, err := d.client.Clientset.BatchV1().Jobs(r.Namespace).Get(ctx, h.Name, metav1.GetOptions{})
+		if err != nil || jobCondition(job, batchv1.JobComplete) != nil {
+			continue
+		}
+		hook := types.HelmHook{
+			ResourceRef: types.ResourceRef{Kind: "Job", APIVersion: batchv1.SchemeGroupVersion.String(), Namespace: r.Namespace, Name: h.Name},
+			Events:      h.Events,
+			LastRun:     h.LastRun.Phase,
+			Terminating: job.DeletionTimestamp != nil,
+		}
+		if c := jobCondition(job, batchv1.JobFailed); c != nil {
+			hook.Failed = true
+			hook.Message = c.Reason + ": " + truncateNote(c.Message)
+		} else {
+			hook.Message = fmt.Sprintf("%d pod(s) active, %d failed", job.Status.Active, job.Status.Failed)
+		}
+		r.Hooks = append(r.Hooks, hook)
+	}
+}
+
+// jobCondition returns a condition of a Job if it is true
+func jobCondition(job *batchv1.Job, condType batchv1.JobConditionType) *batchv1.JobCondition {
+	for i := range job.Status.Conditions {
+		if job.Status.Conditions[i].Type == condType && job.Status.Conditions[i].Status == corev1.ConditionTrue {
+			return &job.Status.Conditions[i]
+		}
+	}
+	return nil
+}
+
+// latestReleaseSecrets returns the Secret of the highest revision of each release
+func latestReleaseSecrets(secrets []corev1.Secret) map[string]corev1.Secret {
+	latest := make(map[string]corev1.Secret)
+	revisions := make(map[string]int)
+	for _, s := range secrets {
+		name := s.Labels["name"]
+		revision, err := strconv.Atoi(s.Labels["version"])
+		if name == "" || err != nil {
+			continue
+		}
+		if _, ok := latest[name]; !ok || revision > revisions[name] {
+			latest[name] = s
+			revisions[name] = revision
+		}
+	}
+	return latest
+}
+
+// decodeHelmRelease decodes a release record, which Helm stores base64 encoded and usually gzipped
+func decodeHelmRelease(data []byte) (*helmReleaseRecord, error) {
+	raw, err := base64.StdEncoding.DecodeString(string(data))
+	if err != nil {
+		return nil, err
+	}
+	if bytes.HasPrefix(raw, []byte{0x1f, 0x8b, 0x08}) {
+		r, err := gzip.NewReader(bytes.NewReader(raw))
+		if err != nil {
+			return nil, err
+		}
+		defer r.Close()
+		if raw, err = io.ReadAll(r); err != nil {
+			return nil, err
+		}
+	}
+	var record helmReleaseRecord
+	if err := json.Unmarshal(raw, &record); err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+// helmReleaseOf returns the Helm release that installed an object as namespace/name, or an empty
+// string if the object was not installed by Helm
+func helmReleaseOf(obj metav1.Object) string {
+	name := obj.GetAnnotations()[helmReleaseNameAnnotation]
+	if name == "" || obj.GetLabels()[helmManagedByLabel] != "Helm" {
+		return ""
+	}
+	namespace := obj.GetAnnotations()[helmReleaseNamespaceAnnotation]
+	if namespace == "" {
+		namespace = obj.GetNamespace()
+	}
+	return namespace + "/" + name
+}
+
+// analyzeHelm attaches the Helm releases of the blockers, and stuck releases in a namespace target
+func analyzeHelm(ctx context.Context, client *kube.Client, report *types.DiagnosisReport) {
+	namespace := ""
+	if report.TargetType == types.TargetTypeNamespace {
+		namespace = report.Target.Name
+	}
+	report.HelmReleases = NewHelmDetector(client).Analyze(ctx, report.Blockers, namespace)
+}
+
+// helmRecommendations explains stuck Helm operations and the hook Jobs they wait on
+func helmRecommendations(report *types.DiagnosisReport) []string {
+	var recs []string
+	for _, r := range report.HelmReleases {
+		uninstall := fmt.Sprintf("helm uninstall %s -n %s --no-hooks", r.Name, r.Namespace)
+		switch {
+		case r.Status == "":
+			if len(r.Blockers) > 0 {
+				recs = append(recs, fmt.Sprintf(
+					"%d blocker(s) were installed by Helm release %s, whose release Secret is gone. Helm can no longer uninstall them; they are handled like other blockers.",
+					len(r.Blockers), r.String(),
+				))
+			}
+		case r.IsStuck():
+			rec := fmt.Sprintf("Helm release %s (revision %d) is %s", r.String(), r.Revision, r.Status)
+			if r.Description != "" {
+				rec += ": " + truncateNote(r.Description)
+			}
+			rec += "."
+			for _, h := range r.Hooks {
+				state := "is still running"
+				if h.Failed {
+					state = "failed"
+				}
+				rec += fmt.Sprintf(" Its %s hook Job %s %s.", strings.Join(h.Events, ","), h.Name, state)
+			}
+			// Hooks of installs, upgrades and rollbacks are left to Helm
+			if r.Status == "uninstalling" {
+				if hasDeleteHooks(r) {
+					rec += fmt.Sprintf(" The plan deletes the delete hook Job(s); then run `%s` to finish without hooks.", uninstall)
+				} else {
+					rec += fmt.Sprintf(" Run `%s` to finish the uninstall.", uninstall)
+				}
+			}
+			recs = append(recs, rec)
+		}
+	}
+	return recs
+}
+
+// hasDeleteHooks returns true if the release has a pre-delete or post-delete hook Job that is not
+// being deleted already
+func hasDeleteHooks(r types.HelmRelease) bool {
+	for _, h := range r.Hooks {
+		if h.IsDeleteHook() && !h.Terminating {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,117 @@
+package detector
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/sozercan/unstuck/pkg/kube"
+	"github.com/sozercan/unstuck/pkg/types"
+)
+
+// helmReleaseSecret encodes a release record the way Helm's Secret driver stores it
+func helmReleaseSecret(t *testing.T, name, status string, revision int, hooks ...map[string]interface{}) *corev1.Secret {
+	record, err := json.Marshal(map[string]interface{}{
+		"name":      name,
+		"namespace": "apps",
+		"version":   revision,
+		"info":      map[string]interface{}{"status": status, "description": "Deletion in progress (or silently failed)"},
+		"chart":     map[string]interface{}{"metadata": map[string]interface{}{"name": name, "version": "1.2.0"}},
+		"hooks":     hooks,
+	})
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	_, err = w.Write(record)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "apps",
+			Name:      "sh.helm.release.v1." + name + ".v" + strconv.Itoa(revision),
+			Labels:    map[string]string{"owner": "helm", "name": name, "status": status, "version": strconv.Itoa(revision)},
+		},
+		Type: "helm.sh/release.v1",
+		Data: map[string][]byte{"release": []byte(base64.StdEncoding.EncodeToString(buf.Bytes()))},
+	}
+}
+
+func TestHelmDetector_Analyze(t *testing.T) {
+	hook := func(name string) map[string]interface{} {
+		return map[string]interface{}{"name": name, "kind": "Job", "events": []string{"pre-delete"}, "last_run": map[string]interface{}{"phase": "Running"}}
+	}
+	job := func(name string, condType batchv1.JobConditionType) *batchv1.Job {
+		j := &batchv1.Job{ObjectMeta: metav1.ObjectMeta{Namespace: "apps", Name: name}}
+		if condType != "" {
+			j.Status.Conditions = []batchv1.JobCondition{{Type: condType, Status: corev1.ConditionTrue, Reason: "BackoffLimitExceeded", Message: "Job has reached the specified backoff limit"}}
+		}
+		return j
+	}
+
+	clientset := fake.NewSimpleClientset(
+		helmReleaseSecret(t, "web", "deployed", 2),
+		helmReleaseSecret(t, "web", "uninstalling", 3, hook("web-cleanup"), hook("web-done"), hook("web-missing")),
+		helmReleaseSecret(t, "db", "deployed", 1),
+		job("web-cleanup", batchv1.JobFailed),
+		job("web-done", batchv1.JobComplete),
+	)
+
+	blockers := []types.Blocker{
+		{ResourceRef: types.ResourceRef{Kind: "Service", APIVersion: "v1", Namespace: "apps", Name: "web"}, HelmRelease: "apps/web"},
+		{ResourceRef: types.ResourceRef{Kind: "ConfigMap", APIVersion: "v1", Namespace: "apps", Name: "old"}, HelmRelease: "apps/legacy"},
+	}
+
+	releases := NewHelmDetector(&kube.Client{Clientset: clientset}).Analyze(context.Background(), blockers, "apps")
+
+	// db is deployed and installed no blockers
+	require.Len(t, releases, 2)
+	legacy, web := releases[0], releases[1]
+
+	assert.Equal(t, "apps/legacy", legacy.String())
+	assert.Empty(t, legacy.Status, "no release Secret is left")
+	assert.Len(t, legacy.Blockers, 1)
+
+	assert.Equal(t, 3, web.Revision)
+	assert.Equal(t, "uninstalling", web.Status)
+	assert.Equal(t, "web-1.2.0", web.Chart)
+	assert.True(t, web.IsStuck())
+	require.Len(t, web.Hooks, 1, "completed and missing hook Jobs are not reported")
+	assert.Equal(t, "web-cleanup", web.Hooks[0].Name)
+	assert.True(t, web.Hooks[0].Failed)
+	assert.True(t, web.Hooks[0].IsDeleteHook())
+	assert.Equal(t, "Running", web.Hooks[0].LastRun)
+	assert.Contains(t, web.Hooks[0].Message, "BackoffLimitExceeded")
+
+	report := &types.DiagnosisReport{HelmReleases: releases}
+	recs := helmRecommendations(report)
+	require.Len(t, recs, 2)
+	assert.Contains(t, recs[0], "release Secret is gone")
+	assert.Contains(t, recs[1], "pre-delete hook Job web-cleanup failed")
+	assert.Contains(t, recs[1], "helm uninstall web -n apps --no-hooks")
+}
+
+func TestHelmReleaseOf(t *testing.T) {
+	obj := &unstructured.Unstructured{}
+	obj.SetNamespace("apps")
+	obj.SetAnnotations(map[string]string{"meta.helm.sh/release-name": "web"})
+	assert.Empty(t, helmReleaseOf(obj), "not managed by Helm")
+
+	obj.SetLabels(map[string]string{"app.kubernetes.io/managed-by": "Helm"})
+	assert.Equal(t, "apps/web", helmReleaseOf(obj))
+
+	obj.SetAnnotations(map[string]string{"meta.helm.sh/release-name": "web", "meta.helm.sh/release-namespace": "platform"})
+	assert.Equal(t, "platform/web", helmReleaseOf(obj))
+}
@@ -0,0 +1,111 @@
+package detector
+
+import (
+	"context"
+	"fmt"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/sozercan/unstuck/pkg/kube"
+	"github.com/sozercan/unstuck/pkg/types"
+)
+
+// JobTrackingDetector checks pods held by the job tracking finalizer against their owning Job
+type JobTrackingDetector struct {
+	client *kube.Client
+}
+
+// NewJobTrackingDetector creates a new job tracking detector
+func NewJobTrackingDetector(client *kube.Client) *JobTrackingDetector {
+	return &JobTrackingDetector{client: client}
+}
+
+// Analyze sets JobTracking on pod blockers with the job tracking finalizer. A Job counts as gone
+// if it is not found or has a different UID; Jobs that cannot be read are assumed to exist.
+func (d *JobTrackingDetector) Analyze(ctx context.Context, blockers []types.Blocker) {
+	for i := range blockers {
+		b := &blockers[i]
+		if b.Kind != "Pod" || b.APIVersion != "v1" || !containsString(b.Finalizers, batchv1.JobTrackingFinalizer) {
+			continue
+		}
+
+		tracking := &types.JobTracking{}
+		if pod, err := d.client.Clientset.CoreV1().Pods(b.Namespace).Get(ctx, b.Name, metav1.GetOptions{}); err == nil {
+			tracking.Phase = string(pod.Status.Phase)
+			tracking.Terminated = pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed
+		}
+
+		owner := jobOwner(b.OwnerReferences)
+		if owner == nil {
+			tracking.Message = "pod has no Job owner; nothing will remove the finalizer"
+			b.JobTracking = tracking
+			continue
+		}
+		tracking.Job = owner.Name
+
+		job, err := d.client.Clientset.BatchV1().Jobs(b.Namespace).Get(ctx, owner.Name, metav1.GetOptions{})
+		switch {
+		case apierrors.IsNotFound(err), err == nil && job.UID != owner.UID:
+			tracking.Message = fmt.Sprintf("Job %s no longer exists; nothing will remove the finalizer", owner.Name)
+		case err != nil:
+			tracking.JobExists = true
+			tracking.Message = fmt.Sprintf("cannot check Job %s: %v", owner.Name, err)
+		case tracking.Terminated:
+			tracking.JobExists = true
+			tracking.Message = fmt.Sprintf("Job %s exists but has not counted the %s pod; check the job controller in kube-controller-manager", owner.Name, tracking.Phase)
+		default:
+			tracking.JobExists = true
+			tracking.Message = fmt.Sprintf("Job %s exists; the job controller removes the finalizer once the pod terminates", owner.Name)
+		}
+		b.JobTracking = tracking
+	}
+}
+
+// jobOwner returns the controller owner reference of a pod if it is a Job
+func jobOwner(owners []metav1.OwnerReference) *metav1.OwnerReference {
+	for i := range owners {
+		o := &owners[i]
+		if o.Kind == "Job" && o.APIVersion == batchv1.SchemeGroupVersion.String() && o.Controller != nil && *o.Controller {
+			return o
+		}
+	}
+	return nil
+}
+
+// analyzeJobTracking attaches the state of the owning Job to pods held by the job tracking finalizer
+func analyzeJobTracking(ctx context.Context, client *kube.Client, report *types.DiagnosisReport) {
+	NewJobTrackingDetector(client).Analyze(ctx, report.Blockers)
+}
+
+// jobTrackingRecommendations explains how pods held by the job tracking finalizer are handled
+func jobTrackingRecommendations(report *types.DiagnosisReport) []string {
+	orphaned, tracked := 0, 0
+	for _, b := range report.Blockers {
+		if b.JobTracking == nil {
+			continue
+		}
+		if b.JobTracking.CanRemoveFinalizer() {
+			orphaned++
+		} else {
+			tracked++
+		}
+	}
+
+	var recs []string
+	if orphaned > 0 {
+		recs = append(recs, fmt.Sprintf(
+			"%d pod(s) carry %s but their Job is gone. The plan removes only that finalizer, which is low risk.",
+			orphaned, batchv1.JobTrackingFinalizer,
+		))
+	}
+	if tracked > 0 {
+		recs = append(recs, fmt.Sprintf(
+			"%d pod(s) carry %s and their Job still exists. Removing the finalizer makes the Job miscount them; check the job controller in kube-controller-manager first.",
+			tracked, batchv1.JobTrackingFinalizer,
+		))
+	}
+	return recs
+}
@@ -0,0 +1,80 @@
+package detector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/sozercan/unstuck/pkg/kube"
+	"github.com/sozercan/unstuck/pkg/types"
+)
+
+func TestJobTrackingDetector_Analyze(t *testing.T) {
+	controller := true
+	jobRef := func(name string, uid k8stypes.UID) []metav1.OwnerReference {
+		return []metav1.OwnerReference{{APIVersion: "batch/v1", Kind: "Job", Name: name, UID: uid, Controller: &controller}}
+	}
+	pod := func(name string, phase corev1.PodPhase) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "batch", Name: name},
+			Status:     corev1.PodStatus{Phase: phase},
+		}
+	}
+
+	clientset := fake.NewSimpleClientset(
+		&batchv1.Job{ObjectMeta: metav1.ObjectMeta{Namespace: "batch", Name: "live", UID: "live-uid"}},
+		&batchv1.Job{ObjectMeta: metav1.ObjectMeta{Namespace: "batch", Name: "recreated", UID: "new-uid"}},
+		pod("live-running", corev1.PodRunning),
+		pod("live-done", corev1.PodSucceeded),
+		pod("deleted-job", corev1.PodFailed),
+		pod("recreated-job", corev1.PodSucceeded),
+	)
+
+	blocker := func(name string, owners []metav1.OwnerReference, finalizers ...string) types.Blocker {
+		return types.Blocker{
+			ResourceRef:     types.ResourceRef{Kind: "Pod", APIVersion: "v1", Namespace: "batch", Name: name},
+			Finalizers:      finalizers,
+			OwnerReferences: owners,
+		}
+	}
+	blockers := []types.Blocker{
+		blocker("live-running", jobRef("live", "live-uid"), batchv1.JobTrackingFinalizer),
+		blocker("live-done", jobRef("live", "live-uid"), batchv1.JobTrackingFinalizer),
+		blocker("deleted-job", jobRef("gone", "gone-uid"), batchv1.JobTrackingFinalizer),
+		blocker("recreated-job", jobRef("recreated", "old-uid"), batchv1.JobTrackingFinalizer),
+		blocker("other", jobRef("gone", "gone-uid"), "example.com/cleanup"),
+	}
+
+	NewJobTrackingDetector(&kube.Client{Clientset: clientset}).Analyze(context.Background(), blockers)
+
+	require.NotNil(t, blockers[0].JobTracking)
+	assert.True(t, blockers[0].JobTracking.JobExists)
+	assert.False(t, blockers[0].JobTracking.Terminated)
+
+	require.NotNil(t, blockers[1].JobTracking)
+	assert.True(t, blockers[1].JobTracking.JobExists)
+	assert.True(t, blockers[1].JobTracking.Terminated)
+	assert.Contains(t, blockers[1].JobTracking.Message, "has not counted the Succeeded pod")
+
+	require.NotNil(t, blockers[2].JobTracking)
+	assert.True(t, blockers[2].JobTracking.CanRemoveFinalizer())
+	assert.Equal(t, "gone", blockers[2].JobTracking.Job)
+	assert.Equal(t, "Failed", blockers[2].JobTracking.Phase)
+
+	require.NotNil(t, blockers[3].JobTracking)
+	assert.True(t, blockers[3].JobTracking.CanRemoveFinalizer(), "a Job recreated under the same name does not track the pod")
+
+	assert.Nil(t, blockers[4].JobTracking)
+
+	recs := jobTrackingRecommendations(&types.DiagnosisReport{Blockers: blockers})
+	require.Len(t, recs, 2)
+	assert.Contains(t, recs[0], "2 pod(s)")
+	assert.Contains(t, recs[1], "2 pod(s)")
+}
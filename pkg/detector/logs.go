@@ -0,0 +1,199 @@
+package detector
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/sozercan/unstuck/pkg/kube"
+	"github.com/sozercan/unstuck/pkg/types"
+)
+
+const (
+	// logTailLines is how many recent lines are read from each controller container
+	logTailLines int64 = 1000
+	// maxLogPodsPerController limits how many pods are read per controller
+	maxLogPodsPerController = 2
+	// maxLogLinesPerBlocker limits how many matching lines are kept per blocker and container
+	maxLogLinesPerBlocker = 5
+	// maxLogLineLength truncates long (usually structured) log lines
+	maxLogLineLength = 240
+)
+
+// LogDetector extracts controller log lines that mention blockers
+type LogDetector struct {
+	client *kube.Client
+}
+
+// NewLogDetector creates a new log detector
+func NewLogDetector(client *kube.Client) *LogDetector {
+	return &LogDetector{client: client}
+}
+
+// ExcerptsFor reads recent logs from the pods of each controller and returns the lines
+// mentioning the blockers whose finalizers that controller handles. Controllers whose pods
+// cannot be found are skipped and reported in the returned error
+func (d *LogDetector) ExcerptsFor(ctx context.Context, controllers []types.ControllerStatus, blockers []types.Blocker) ([]types.LogExcerpt, error) {
+	var excerpts []types.LogExcerpt
+	var errs []error
+
+	for _, c := range controllers {
+		handled := blockersHandledBy(c, blockers)
+		if len(handled) == 0 {
+			continue
+		}
+
+		pods, err := d.controllerPods(ctx, c)
+		if err != nil {
+			// One unreadable controller should not hide the logs of the others
+			errs = append(errs, err)
+			continue
+		}
+
+		for _, pod := range pods {
+			for _, container := range pod.Spec.Containers {
+				logs, err := d.containerLogs(ctx, pod, container.Name)
+				if err != nil {
+					// Logs may be unavailable (RBAC, kubelet unreachable); skip the container
+					continue
+				}
+				for _, b := range handled {
+					lines, total := matchLogLines(logs, b)
+					if total == 0 {
+						continue
+					}
+					excerpts = append(excerpts, types.LogExcerpt{
+						Controller: fmt.Sprintf("%s/%s/%s", c.Kind, c.Namespace, c.Name),
+						Pod:        pod.Name,
+						Container:  container.Name,
+						Blocker:    b.ResourceRef,
+						Lines:      lines,
+						Total:      total,
+					})
+				}
+			}
+		}
+	}
+
+	return excerpts, errors.Join(errs...)
+}
+
+// controllerPods returns the running pods of a controller workload
+func (d *LogDetector) controllerPods(ctx context.Context, c types.ControllerStatus) ([]corev1.Pod, error) {
+	listOpts := metav1.ListOptions{}
+
+	switch c.Kind {
+	case "Deployment":
+		deploy, err := d.client.Clientset.AppsV1().Deployments(c.Namespace).Get(ctx, c.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get deployment %s/%s: %w", c.Namespace, c.Name, err)
+		}
+		selector, err := metav1.LabelSelectorAsSelector(deploy.Spec.Selector)
+		if err != nil {
+			return nil, fmt.Errorf("invalid selector on deployment %s/%s: %w", c.Namespace, c.Name, err)
+		}
+		listOpts.LabelSelector = selector.String()
+	case "StatefulSet":
+		sts, err := d.client.Clientset.AppsV1().StatefulSets(c.Namespace).Get(ctx, c.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get statefulset %s/%s: %w", c.Namespace, c.Name, err)
+		}
+		selector, err := metav1.LabelSelectorAsSelector(sts.Spec.Selector)
+		if err != nil {
+			return nil, fmt.Errorf("invalid selector on statefulset %s/%s: %w", c.Namespace, c.Name, err)
+		}
+		listOpts.LabelSelector = selector.String()
+	}
+
+	list, err := d.client.Clientset.CoreV1().Pods(c.Namespace).List(ctx, listOpts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods in %s: %w", c.Namespace, err)
+	}
+
+	var pods []corev1.Pod
+	for _, pod := range list.Items {
+		if pod.Status.Phase != corev1.PodRunning {
+			continue
+		}
+		if listOpts.LabelSelector == "" && serviceAccountName(pod.Spec.ServiceAccountName) != c.ServiceAccount {
+			continue
+		}
+		pods = append(pods, pod)
+		if len(pods) == maxLogPodsPerController {
+			break
+		}
+	}
+	return pods, nil
+}
+
+// containerLogs fetches the tail of a container's log via the pods/log subresource
+func (d *LogDetector) containerLogs(ctx context.Context, pod corev1.Pod, container string) (string, error) {
+	tail := logTailLines
+	raw, err := d.client.Clientset.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, &corev1.PodLogOptions{
+		Container: container,
+		TailLines: &tail,
+	}).DoRaw(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to get logs for %s/%s: %w", pod.Name, container, err)
+	}
+	return string(raw), nil
+}
+
+// matchLogLines returns the most recent lines mentioning the blocker by UID, or by name
+// together with its namespace, and the total number of matching lines
+func matchLogLines(logs string, b types.Blocker) ([]string, int) {
+	var matched []string
+	total := 0
+
+	scanner := bufio.NewScanner(strings.NewReader(logs))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !logLineMentions(line, b) {
+			continue
+		}
+		total++
+		matched = append(matched, truncateLogLine(line))
+		if len(matched) > maxLogLinesPerBlocker {
+			matched = matched[1:]
+		}
+	}
+	return matched, total
+}
+
+func logLineMentions(line string, b types.Blocker) bool {
+	if b.UID != "" && strings.Contains(line, b.UID) {
+		return true
+	}
+	if !strings.Contains(line, b.Name) {
+		return false
+	}
+	return b.Namespace == "" || strings.Contains(line, b.Namespace)
+}
+
+// blockersHandledBy returns the blockers carrying a finalizer the controller is responsible for
+func blockersHandledBy(c types.ControllerStatus, blockers []types.Blocker) []types.Blocker {
+	var handled []types.Blocker
+	for _, b := range blockers {
+		for _, f := range b.Finalizers {
+			if containsString(c.Finalizers, f) {
+				handled = append(handled, b)
+				break
+			}
+		}
+	}
+	return handled
+}
+
+func truncateLogLine(line string) string {
+	line = strings.TrimSpace(line)
+	if len(line) <= maxLogLineLength {
+		return line
+	}
+	return line[:maxLogLineLength-3] + "..."
+}
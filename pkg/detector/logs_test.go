@@ -0,0 +1,119 @@
+package detector
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/sozercan/unstuck/pkg/kube"
+	"github.com/sozercan/unstuck/pkg/types"
+)
+
+func TestMatchLogLines(t *testing.T) {
+	blocker := types.Blocker{
+		ResourceRef: types.ResourceRef{Kind: "Widget", Namespace: "apps", Name: "w1"},
+		UID:         "0b7c6d2e-1111-2222-3333-444455556666",
+	}
+	logs := strings.Join([]string{
+		`I0101 reconciling widget apps/w1`,
+		`E0101 failed to clean up {"uid": "0b7c6d2e-1111-2222-3333-444455556666", "error": "bucket not empty"}`,
+		`I0101 reconciling widget other/w1`,
+		`I0101 reconciling widget apps/w2`,
+	}, "\n")
+
+	lines, total := matchLogLines(logs, blocker)
+
+	assert.Equal(t, 2, total)
+	require.Len(t, lines, 2)
+	assert.Contains(t, lines[1], "bucket not empty")
+}
+
+func TestMatchLogLines_KeepsMostRecent(t *testing.T) {
+	blocker := types.Blocker{ResourceRef: types.ResourceRef{Kind: "Widget", Name: "w1"}}
+	var logLines []string
+	for i := 0; i < maxLogLinesPerBlocker+3; i++ {
+		logLines = append(logLines, fmt.Sprintf("attempt %d: w1 still has dependents", i))
+	}
+
+	lines, total := matchLogLines(strings.Join(logLines, "\n"), blocker)
+
+	assert.Equal(t, maxLogLinesPerBlocker+3, total)
+	require.Len(t, lines, maxLogLinesPerBlocker)
+	assert.Equal(t, logLines[len(logLines)-1], lines[len(lines)-1])
+}
+
+func TestLogDetector_ControllerPods(t *testing.T) {
+	selector := &metav1.LabelSelector{MatchLabels: map[string]string{"app": "widget-operator"}}
+	pod := func(name, app string, phase corev1.PodPhase) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "ops", Labels: map[string]string{"app": app}},
+			Status:     corev1.PodStatus{Phase: phase},
+		}
+	}
+	clientset := fake.NewSimpleClientset(
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "widget-operator", Namespace: "ops"},
+			Spec:       appsv1.DeploymentSpec{Selector: selector},
+		},
+		pod("widget-operator-1", "widget-operator", corev1.PodRunning),
+		pod("widget-operator-2", "widget-operator", corev1.PodPending),
+		pod("other-1", "other", corev1.PodRunning),
+	)
+	d := NewLogDetector(&kube.Client{Clientset: clientset})
+
+	pods, err := d.controllerPods(context.Background(), types.ControllerStatus{
+		Kind: "Deployment", Namespace: "ops", Name: "widget-operator",
+	})
+
+	require.NoError(t, err)
+	require.Len(t, pods, 1)
+	assert.Equal(t, "widget-operator-1", pods[0].Name)
+}
+
+func TestBlockersHandledBy(t *testing.T) {
+	blockers := []types.Blocker{
+		{ResourceRef: types.ResourceRef{Name: "a"}, Finalizers: []string{"example.com/cleanup"}},
+		{ResourceRef: types.ResourceRef{Name: "b"}, Finalizers: []string{"other.io/finalizer"}},
+	}
+
+	handled := blockersHandledBy(types.ControllerStatus{Finalizers: []string{"example.com/cleanup"}}, blockers)
+
+	require.Len(t, handled, 1)
+	assert.Equal(t, "a", handled[0].Name)
+}
+
+func TestLogDetector_ExcerptsFor_SkipsMissingController(t *testing.T) {
+	clientset := fake.NewSimpleClientset(
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "widget-operator-1", Namespace: "ops"},
+			Spec: corev1.PodSpec{
+				ServiceAccountName: "widget-operator",
+				Containers:         []corev1.Container{{Name: "manager"}},
+			},
+			Status: corev1.PodStatus{Phase: corev1.PodRunning},
+		},
+	)
+	d := NewLogDetector(&kube.Client{Clientset: clientset})
+	finalizers := []string{"example.com/cleanup"}
+	controllers := []types.ControllerStatus{
+		{Kind: "Deployment", Namespace: "ops", Name: "gone", Finalizers: finalizers},
+		{Kind: "ServiceAccount", Namespace: "ops", Name: "widget-operator", ServiceAccount: "widget-operator", Finalizers: finalizers},
+	}
+	// The fake clientset serves "fake logs" for every container
+	blockers := []types.Blocker{{ResourceRef: types.ResourceRef{Name: "w1"}, UID: "fake", Finalizers: finalizers}}
+
+	excerpts, err := d.ExcerptsFor(context.Background(), controllers, blockers)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "ops/gone")
+	require.Len(t, excerpts, 1)
+	assert.Equal(t, "widget-operator-1", excerpts[0].Pod)
+}
@@ -0,0 +1,53 @@
+package detector
+
+import (
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/sozercan/unstuck/pkg/kube"
+)
+
+// resourceMapper maps an apiVersion and kind to its resource, caching discovery per group version
+type resourceMapper struct {
+	client *kube.Client
+	// Discovered resources per group version, nil if the group version is no longer served
+	resources map[string]*metav1.APIResourceList
+}
+
+func newResourceMapper(client *kube.Client) *resourceMapper {
+	return &resourceMapper{client: client, resources: make(map[string]*metav1.APIResourceList)}
+}
+
+// resourceFor returns the resource for an apiVersion and kind and whether it is namespaced.
+// It returns an empty GroupVersionResource if the group version or kind is no longer served.
+func (m *resourceMapper) resourceFor(apiVersion, kind string) (schema.GroupVersionResource, bool, error) {
+	gv, err := schema.ParseGroupVersion(apiVersion)
+	if err != nil {
+		return schema.GroupVersionResource{}, false, err
+	}
+
+	list, ok := m.resources[apiVersion]
+	if !ok {
+		list, err = m.client.Discovery.ServerResourcesForGroupVersion(apiVersion)
+		if err != nil {
+			if !apierrors.IsNotFound(err) {
+				return schema.GroupVersionResource{}, false, err
+			}
+			list = nil
+		}
+		m.resources[apiVersion] = list
+	}
+	if list == nil {
+		return schema.GroupVersionResource{}, false, nil
+	}
+
+	for _, r := range list.APIResources {
+		if r.Kind == kind && !strings.Contains(r.Name, "/") {
+			return gv.WithResource(r.Name), r.Namespaced, nil
+		}
+	}
+	return schema.GroupVersionResource{}, false, nil
+}
@@ -18,12 +18,18 @@ import (
 
 // NamespaceDetector detects issues with stuck namespaces
 type NamespaceDetector struct {
-	client *kube.Client
+	client     *kube.Client
+	stuckAfter time.Duration
 }
 
 // NewNamespaceDetector creates a new namespace detector
 func NewNamespaceDetector(client *kube.Client) *NamespaceDetector {
-	return &NamespaceDetector{client: client}
+	return &NamespaceDetector{client: client, stuckAfter: DefaultStuckAfter}
+}
+
+// SetStuckAfter sets how long a blocker may be terminating before it is classified Stuck
+func (d *NamespaceDetector) SetStuckAfter(stuckAfter time.Duration) {
+	d.stuckAfter = stuckAfter
 }
 
 // Detect analyzes a namespace and returns a diagnosis report
@@ -42,10 +48,14 @@ func (d *NamespaceDetector) Detect(ctx context.Context, name string) (*types.Dia
 			APIVersion: "v1",
 			Name:       name,
 		},
-		TargetType:  types.TargetTypeNamespace,
-		Status:      string(ns.Status.Phase),
-		Finalizers:  ns.Finalizers,
-		DiagnosedAt: time.Now(),
+		TargetType:          types.TargetTypeNamespace,
+		Status:              string(ns.Status.Phase),
+		Finalizers:          ns.Finalizers,
+		FinalizerProvenance: finalizerProvenance(ns.ManagedFields),
+		DiagnosedAt:         time.Now(),
+	}
+	for _, f := range ns.Spec.Finalizers {
+		report.SpecFinalizers = append(report.SpecFinalizers, string(f))
 	}
 
 	if ns.Status.Phase != corev1.NamespaceTerminating {
@@ -59,12 +69,13 @@ func (d *NamespaceDetector) Detect(ctx context.Context, name string) (*types.Dia
 		t := ns.DeletionTimestamp.Time
 		report.DeletionTimestamp = &t
 		report.TerminatingFor = formatDuration(time.Since(t))
+		report.TargetStuck = time.Since(t) >= d.stuckAfter
 	}
 
 	report.Conditions = parseNamespaceConditions(ns.Status.Conditions)
 	report.RootCause, report.DiscoveryFailures = analyzeConditions(ns.Status.Conditions)
 
-	blockers, err := d.enumerateResources(ctx, name)
+	blockers, visible, corrupt, err := d.enumerateResources(ctx, name)
 	if err != nil {
 		report.DiscoveryFailures = append(report.DiscoveryFailures, types.DiscoveryFailure{
 			GroupVersion: "unknown",
@@ -73,18 +84,60 @@ func (d *NamespaceDetector) Detect(ctx context.Context, name string) (*types.Dia
 	}
 	report.Blockers = blockers
 
+	// The namespace controller cannot delete objects it cannot decode
+	report.CorruptObjects = corrupt
+	if report.HasCorruptObjects() {
+		report.RootCause = fmt.Sprintf("Corrupt objects - %d object(s) cannot be read from storage", len(corrupt))
+	}
+
+	// An empty namespace that is still terminating waits on its own metadata finalizers
+	if len(blockers) == 0 && len(ns.Finalizers) > 0 && !report.HasDiscoveryFailures() && !report.HasCorruptObjects() {
+		report.RootCause = fmt.Sprintf(
+			"Namespace metadata finalizers remain: %s - these are not handled by the namespace controller",
+			strings.Join(ns.Finalizers, ", "),
+		)
+	}
+
+	// Compare what the namespace controller still sees with what we can list
+	report.RemainingContent = parseRemainingContent(ns.Status.Conditions)
+	crossCheckContent(report.RemainingContent, visible, blockers)
+
+	// Controllers evicted by the namespace deletion can never process their finalizers
+	detectControllers(ctx, d.client, report, blockers)
+	if report.ControllerError == "" {
+		report.SelfDeadlock = buildSelfDeadlock(name, report.Controllers, blockers)
+		if report.HasSelfDeadlock() {
+			report.RootCause = fmt.Sprintf(
+				"Self-deadlock - controller for %s runs inside the namespace being deleted",
+				strings.Join(report.SelfDeadlock.Finalizers, ", "),
+			)
+		}
+	}
+
+	analyzeBlockers(ctx, d.client, report, d.stuckAfter)
+	if report.RootCause == "" {
+		report.RootCause = "Namespace finalizer blocking deletion"
+	}
+
 	report.Recommendations = d.buildRecommendations(report)
 
 	return report, nil
 }
 
-func (d *NamespaceDetector) enumerateResources(ctx context.Context, namespace string) ([]types.Blocker, error) {
+// enumerateResources returns the objects in the namespace that block deletion, the number of
+// objects of every group-resource that could be listed, keyed like the namespace conditions,
+// and the objects whose list failed because they cannot be read from storage
+func (d *NamespaceDetector) enumerateResources(ctx context.Context, namespace string) ([]types.Blocker, map[string]int, []types.CorruptObject, error) {
 	var blockers []types.Blocker
+	var corrupt []types.CorruptObject
+	visible := make(map[string]int)
+	// Resources served at several versions are listed once per version
+	seen := make(map[string]bool)
 
 	_, apiResourceLists, err := d.client.Discovery.ServerGroupsAndResources()
 	if err != nil {
 		if !isPartialDiscoveryError(err) {
-			return nil, err
+			return nil, nil, nil, err
 		}
 	}
 
@@ -115,10 +168,24 @@ func (d *NamespaceDetector) enumerateResources(ctx context.Context, namespace st
 
 			list, err := d.client.Dynamic.Resource(gvr).Namespace(namespace).List(ctx, metav1.ListOptions{})
 			if err != nil {
+				corrupt = append(corrupt, corruptObjects(err, gvr, apiResource.Kind, true)...)
 				continue
 			}
 
+			// An empty list still counts as visible
+			groupResource := groupResourceString(gv.Group, apiResource.Name)
+			if _, ok := visible[groupResource]; !ok {
+				visible[groupResource] = 0
+			}
 			for _, item := range list.Items {
+				if uid := string(item.GetUID()); uid != "" {
+					if seen[uid] {
+						continue
+					}
+					seen[uid] = true
+				}
+				visible[groupResource]++
+
 				finalizers := item.GetFinalizers()
 				deletionTimestamp := item.GetDeletionTimestamp()
 
@@ -130,9 +197,12 @@ func (d *NamespaceDetector) enumerateResources(ctx context.Context, namespace st
 							Namespace:  item.GetNamespace(),
 							Name:       item.GetName(),
 						},
-						Finalizers:        finalizers,
-						DeletionTimestamp: deletionTimestamp,
-						OwnerReferences:   item.GetOwnerReferences(),
+						UID:                 string(item.GetUID()),
+						Finalizers:          finalizers,
+						DeletionTimestamp:   deletionTimestamp,
+						OwnerReferences:     item.GetOwnerReferences(),
+						FinalizerProvenance: finalizerProvenance(item.GetManagedFields()),
+						HelmRelease:         helmReleaseOf(&item),
 					}
 					if deletionTimestamp != nil {
 						blocker.Age = time.Since(deletionTimestamp.Time)
@@ -143,20 +213,35 @@ func (d *NamespaceDetector) enumerateResources(ctx context.Context, namespace st
 		}
 	}
 
-	return blockers, nil
+	return blockers, visible, corrupt, nil
 }
 
 func (d *NamespaceDetector) buildRecommendations(report *types.DiagnosisReport) []string {
 	var recs []string
 
+	if report.HasSelfDeadlock() {
+		var names []string
+		for _, c := range report.SelfDeadlock.Controllers {
+			names = append(names, c.Kind+"/"+c.Name)
+		}
+		recs = append(recs, fmt.Sprintf(
+			"Self-deadlock: %s lives in namespace %q and cannot process finalizers on %d objects. No clean path exists; remove their finalizers with `unstuck plan namespace %s`.",
+			strings.Join(names, ", "), report.Target.Name, len(report.SelfDeadlock.Blockers), report.Target.Name,
+		))
+	}
+
 	if report.HasDiscoveryFailures() {
 		recs = append(recs, "Discovery failures detected. CRDs may have been deleted. Use `unstuck plan` with --max-escalation=4 --allow-force")
 	}
 
+	if report.HasContentMismatches() {
+		recs = append(recs, "The namespace controller reports content that unstuck cannot see. Check your RBAC and any unavailable aggregated APIs before force-finalizing, or that content will be orphaned.")
+	}
+
 	if report.HasBlockers() {
 		stuckCount := 0
 		for _, b := range report.Blockers {
-			if b.IsTerminating() {
+			if b.Status == types.BlockerStatusStuck {
 				stuckCount++
 			}
 		}
@@ -167,6 +252,10 @@ func (d *NamespaceDetector) buildRecommendations(report *types.DiagnosisReport)
 		}
 	}
 
+	recs = append(recs, namespaceFinalizerRecommendations(report)...)
+	recs = append(recs, corruptObjectRecommendations(report, "unstuck plan namespace "+report.Target.Name)...)
+	recs = append(recs, blockerRecommendations(report, d.stuckAfter)...)
+
 	if len(recs) == 0 {
 		recs = append(recs, "Unable to determine specific blockers. The namespace finalizer may need force removal.")
 	}
@@ -187,6 +276,25 @@ func parseNamespaceConditions(conditions []corev1.NamespaceCondition) []types.Co
 	return summaries
 }
 
+// Causes derived from namespace conditions that only restate that content remains; a more
+// specific cause found later, such as a classified event, replaces them
+const (
+	contentFailureCause   = "Namespace controller failed to delete some content"
+	gvParsingFailureCause = "Namespace controller could not parse some API group versions"
+	finalizersLeftCause   = "CR instances stuck with unsatisfied finalizers"
+	contentRemainingCause = "Resources remaining in namespace"
+)
+
+// isGenericConditionCause reports whether cause is one of the generic namespace condition causes
+func isGenericConditionCause(cause string) bool {
+	switch cause {
+	case contentFailureCause, gvParsingFailureCause, finalizersLeftCause, contentRemainingCause:
+		return true
+	default:
+		return false
+	}
+}
+
 func analyzeConditions(conditions []corev1.NamespaceCondition) (string, []types.DiscoveryFailure) {
 	var rootCause string
 	var failures []types.DiscoveryFailure
@@ -207,22 +315,28 @@ func analyzeConditions(conditions []corev1.NamespaceCondition) (string, []types.
 				})
 			}
 
+		case corev1.NamespaceDeletionContentFailure:
+			if rootCause == "" {
+				rootCause = contentFailureCause
+			}
+
+		case corev1.NamespaceDeletionGVParsingFailure:
+			if rootCause == "" {
+				rootCause = gvParsingFailureCause
+			}
+
 		case corev1.NamespaceFinalizersRemaining:
 			if rootCause == "" {
-				rootCause = "CR instances stuck with unsatisfied finalizers"
+				rootCause = finalizersLeftCause
 			}
 
 		case corev1.NamespaceContentRemaining:
 			if rootCause == "" {
-				rootCause = "Resources remaining in namespace"
+				rootCause = contentRemainingCause
 			}
 		}
 	}
 
-	if rootCause == "" {
-		rootCause = "Namespace finalizer blocking deletion"
-	}
-
 	return rootCause, failures
 }
 
@@ -266,3 +380,21 @@ func containsVerb(verbs []string, verb string) bool {
 func isPartialDiscoveryError(err error) bool {
 	return strings.Contains(err.Error(), "unable to retrieve the complete list")
 }
+
+// namespaceFinalizerRecommendations explains the namespace's own metadata finalizers, which the
+// namespace controller never removes
+func namespaceFinalizerRecommendations(report *types.DiagnosisReport) []string {
+	var recs []string
+	for _, f := range report.Finalizers {
+		rec := fmt.Sprintf("Namespace %q carries metadata finalizer %s", report.Target.Name, f)
+		for _, p := range report.FinalizerProvenance {
+			if p.Finalizer == f {
+				rec += fmt.Sprintf(" managed by `%s`", p.Manager)
+				break
+			}
+		}
+		rec += ". The namespace controller only clears spec.finalizers; check that tool, or let the plan remove it at Level 2 once the namespace is empty and has been terminating longer than --stuck-after."
+		recs = append(recs, rec)
+	}
+	return recs
+}
@@ -0,0 +1,395 @@
+package detector
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/sozercan/unstuck/pkg/kube"
+	"github.com/sozercan/unstuck/pkg/types"
+)
+
+const (
+	// loadBalancerCleanupFinalizer is added by the service controller of cloud-controller-manager
+	// to Services of type LoadBalancer
+	loadBalancerCleanupFinalizer = "service.kubernetes.io/load-balancer-cleanup"
+	// cloudControllerManager is matched against kube-system pod names and component labels
+	cloudControllerManager = "cloud-controller-manager"
+	// ingressClassAnnotation is the deprecated way of selecting an IngressClass
+	ingressClassAnnotation = "kubernetes.io/ingress.class"
+	gatewayAPIGroup        = "gateway.networking.k8s.io"
+)
+
+// ingressLoadBalancerFinalizers are the prefixes of Ingress finalizers that delete a cloud load
+// balancer. Other Ingress finalizers belong to controllers that manage no cloud resources.
+var ingressLoadBalancerFinalizers = []string{
+	"ingress.k8s.aws/",                    // AWS Load Balancer Controller
+	"group.ingress.k8s.aws/",              // AWS Load Balancer Controller IngressGroups
+	"networking.gke.io/ingress-finalizer", // GKE Ingress
+}
+
+// ingressControllerPods maps IngressClass controllers to the name their pods are matched by.
+// Other controllers are matched by the last segment of their name.
+var ingressControllerPods = map[string]string{
+	"ingress.k8s.aws/alb":           "aws-load-balancer-controller",
+	"networking.gke.io/ingress-gce": "ingress-gce",
+}
+
+// NetworkingDetector finds Services, Ingresses and Gateways whose finalizers delete cloud load
+// balancers, and checks whether the controller that would do so is running
+type NetworkingDetector struct {
+	client *kube.Client
+}
+
+// NewNetworkingDetector creates a new networking detector
+func NewNetworkingDetector(client *kube.Client) *NetworkingDetector {
+	return &NetworkingDetector{client: client}
+}
+
+// Analyze sets External on blockers whose finalizers clean up a load balancer. Controllers found
+// by the controller detector take precedence over the class-based check, since they also cover
+// leader election. Objects that cannot be read are skipped.
+func (d *NetworkingDetector) Analyze(ctx context.Context, blockers []types.Blocker, controllers []types.ControllerStatus) {
+	for i := range blockers {
+		b := &blockers[i]
+		var external *types.ExternalResource
+
+		gv, _ := schema.ParseGroupVersion(b.APIVersion)
+		switch {
+		case gv.Group == "" && b.Kind == "Service":
+			external = d.serviceLoadBalancer(ctx, b)
+		case gv.Group == networkingv1.GroupName && b.Kind == "Ingress":
+			external = d.ingressLoadBalancer(ctx, b)
+		case gv.Group == gatewayAPIGroup && b.Kind == "Gateway":
+			external = d.gatewayLoadBalancer(ctx, b, gv.Version)
+		}
+		if external == nil {
+			continue
+		}
+
+		for _, c := range controllers {
+			if !containsString(c.Finalizers, external.Finalizer) {
+				continue
+			}
+			if external.Controller == "" {
+				external.Controller = c.Namespace + "/" + c.Name
+			}
+			if c.IsReconciling() {
+				external.ControllerState = types.ExternalControllerRunning
+			} else {
+				external.ControllerState = types.ExternalControllerNotRunning
+				external.Message = fmt.Sprintf("%s %s/%s is not reconciling", c.Kind, c.Namespace, c.Name)
+			}
+			break
+		}
+		b.External = external
+	}
+}
+
+// serviceLoadBalancer returns the load balancer of a Service of type LoadBalancer
+func (d *NetworkingDetector) serviceLoadBalancer(ctx context.Context, b *types.Blocker) *types.ExternalResource {
+	finalizer := networkingFinalizer(b.Finalizers, loadBalancerCleanupFinalizer)
+	if finalizer == "" {
+		return nil
+	}
+	svc, err := d.client.Clientset.CoreV1().Services(b.Namespace).Get(ctx, b.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil
+	}
+	if svc.Spec.Type != corev1.ServiceTypeLoadBalancer && finalizer != loadBalancerCleanupFinalizer {
+		return nil
+	}
+
+	external := &types.ExternalResource{
+		Type:      types.ExternalLoadBalancer,
+		Finalizer: finalizer,
+		Addresses: loadBalancerAddresses(svc.Status.LoadBalancer.Ingress),
+	}
+	// Services with a loadBalancerClass are ignored by cloud-controller-manager
+	if svc.Spec.LoadBalancerClass != nil {
+		external.Class = *svc.Spec.LoadBalancerClass
+		external.ControllerState = types.ExternalControllerUnknown
+		external.Message = fmt.Sprintf("load balancer is implemented by the controller of loadBalancerClass %s", external.Class)
+		return external
+	}
+
+	external.Controller = cloudControllerManager
+	external.ControllerState, external.Message = d.cloudControllerManagerState(ctx)
+	return external
+}
+
+// cloudControllerManagerState looks for cloud-controller-manager pods in kube-system. Managed
+// clusters run it in the control plane, so not finding it means the state is unknown.
+func (d *NetworkingDetector) cloudControllerManagerState(ctx context.Context) (types.ExternalControllerState, string) {
+	pods, err := d.client.Clientset.CoreV1().Pods(metav1.NamespaceSystem).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return types.ExternalControllerUnknown, fmt.Sprintf("cannot list pods in %s: %v", metav1.NamespaceSystem, err)
+	}
+
+	found := 0
+	for _, pod := range pods.Items {
+		if !strings.Contains(pod.Name, cloudControllerManager) &&
+			pod.Labels["component"] != cloudControllerManager && pod.Labels["app"] != cloudControllerManager {
+			continue
+		}
+		found++
+		if pod.DeletionTimestamp == nil && isPodReady(&pod) {
+			return types.ExternalControllerRunning, ""
+		}
+	}
+	if found > 0 {
+		return types.ExternalControllerNotRunning, fmt.Sprintf("none of %d cloud-controller-manager pod(s) in %s is ready", found, metav1.NamespaceSystem)
+	}
+	return types.ExternalControllerUnknown, fmt.Sprintf("no cloud-controller-manager pod in %s; on managed clusters it runs in the control plane", metav1.NamespaceSystem)
+}
+
+// ingressLoadBalancer returns the load balancer of an Ingress and the controller of its IngressClass
+func (d *NetworkingDetector) ingressLoadBalancer(ctx context.Context, b *types.Blocker) *types.ExternalResource {
+	finalizer := ingressFinalizer(b.Finalizers)
+	if finalizer == "" {
+		return nil
+	}
+	ing, err := d.client.Clientset.NetworkingV1().Ingresses(b.Namespace).Get(ctx, b.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil
+	}
+
+	external := &types.ExternalResource{
+		Type:            types.ExternalIngress,
+		Finalizer:       finalizer,
+		ControllerState: types.ExternalControllerUnknown,
+		Addresses:       ingressAddresses(ing.Status.LoadBalancer.Ingress),
+	}
+	switch {
+	case ing.Spec.IngressClassName != nil:
+		external.Class = *ing.Spec.IngressClassName
+	case ing.Annotations[ingressClassAnnotation] != "":
+		external.Class = ing.Annotations[ingressClassAnnotation]
+	}
+
+	if external.Class == "" {
+		class, err := d.defaultIngressClass(ctx)
+		if err != nil || class == nil {
+			external.Message = "no IngressClass set and no default IngressClass found"
+			return external
+		}
+		external.Class = class.Name
+		external.Controller = class.Spec.Controller
+		external.ControllerState, external.Message = d.ingressControllerState(ctx, class.Spec.Controller)
+		return external
+	}
+
+	class, err := d.client.Clientset.NetworkingV1().IngressClasses().Get(ctx, external.Class, metav1.GetOptions{})
+	switch {
+	case apierrors.IsNotFound(err):
+		external.ControllerState = types.ExternalControllerNotRunning
+		external.Message = fmt.Sprintf("IngressClass %s not found; its controller was likely uninstalled", external.Class)
+	case err == nil:
+		external.Controller = class.Spec.Controller
+		external.ControllerState, external.Message = d.ingressControllerState(ctx, class.Spec.Controller)
+	}
+	return external
+}
+
+// ingressControllerState looks for the pods of an IngressClass controller in all namespaces.
+// Managed controllers such as GKE Ingress run outside the cluster, so not finding them means
+// the state is unknown.
+func (d *NetworkingDetector) ingressControllerState(ctx context.Context, controller string) (types.ExternalControllerState, string) {
+	name, ok := ingressControllerPods[controller]
+	if !ok {
+		name = controller[strings.LastIndex(controller, "/")+1:]
+	}
+	if name == "" {
+		return types.ExternalControllerUnknown, ""
+	}
+
+	pods, err := d.client.Clientset.CoreV1().Pods(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return types.ExternalControllerUnknown, fmt.Sprintf("cannot list pods: %v", err)
+	}
+
+	found := 0
+	for _, pod := range pods.Items {
+		if !strings.HasPrefix(pod.Name, name) &&
+			pod.Labels["app.kubernetes.io/name"] != name && pod.Labels["app"] != name {
+			continue
+		}
+		found++
+		if pod.DeletionTimestamp == nil && isPodReady(&pod) {
+			return types.ExternalControllerRunning, ""
+		}
+	}
+	if found > 0 {
+		return types.ExternalControllerNotRunning, fmt.Sprintf("none of %d %s pod(s) is ready", found, name)
+	}
+	return types.ExternalControllerUnknown, fmt.Sprintf("no %s pod found; managed ingress controllers run outside the cluster", name)
+}
+
+// defaultIngressClass returns the IngressClass marked as default, or nil if there is none
+func (d *NetworkingDetector) defaultIngressClass(ctx context.Context) (*networkingv1.IngressClass, error) {
+	classes, err := d.client.Clientset.NetworkingV1().IngressClasses().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for i := range classes.Items {
+		if classes.Items[i].Annotations[networkingv1.AnnotationIsDefaultIngressClass] == "true" {
+			return &classes.Items[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// gatewayLoadBalancer returns the addresses of a Gateway and the state of its GatewayClass controller
+func (d *NetworkingDetector) gatewayLoadBalancer(ctx context.Context, b *types.Blocker, version string) *types.ExternalResource {
+	finalizer := networkingFinalizer(b.Finalizers, "")
+	if finalizer == "" {
+		return nil
+	}
+	gvr := schema.GroupVersionResource{Group: gatewayAPIGroup, Version: version, Resource: "gateways"}
+	gw, err := d.client.Dynamic.Resource(gvr).Namespace(b.Namespace).Get(ctx, b.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil
+	}
+
+	external := &types.ExternalResource{
+		Type:            types.ExternalGateway,
+		Finalizer:       finalizer,
+		ControllerState: types.ExternalControllerUnknown,
+	}
+	external.Class, _, _ = unstructured.NestedString(gw.Object, "spec", "gatewayClassName")
+	addresses, _, _ := unstructured.NestedSlice(gw.Object, "status", "addresses")
+	for _, a := range addresses {
+		if m, ok := a.(map[string]interface{}); ok {
+			if value, _ := m["value"].(string); value != "" {
+				external.Addresses = append(external.Addresses, value)
+			}
+		}
+	}
+
+	classGVR := schema.GroupVersionResource{Group: gatewayAPIGroup, Version: version, Resource: "gatewayclasses"}
+	class, err := d.client.Dynamic.Resource(classGVR).Get(ctx, external.Class, metav1.GetOptions{})
+	switch {
+	case apierrors.IsNotFound(err):
+		external.ControllerState = types.ExternalControllerNotRunning
+		external.Message = fmt.Sprintf("GatewayClass %s not found; its controller was likely uninstalled", external.Class)
+	case err == nil:
+		external.Controller, _, _ = unstructured.NestedString(class.Object, "spec", "controllerName")
+		external.ControllerState, external.Message = gatewayClassState(class)
+	}
+	return external
+}
+
+// gatewayClassState reads the Accepted condition, which only a running controller sets
+func gatewayClassState(class *unstructured.Unstructured) (types.ExternalControllerState, string) {
+	conditions, _, _ := unstructured.NestedSlice(class.Object, "status", "conditions")
+	for _, c := range conditions {
+		m, ok := c.(map[string]interface{})
+		if !ok || m["type"] != "Accepted" {
+			continue
+		}
+		if m["status"] == string(metav1.ConditionTrue) {
+			return types.ExternalControllerRunning, ""
+		}
+		msg, _ := m["message"].(string)
+		return types.ExternalControllerNotRunning, fmt.Sprintf("GatewayClass %s is not accepted: %s", class.GetName(), msg)
+	}
+	return types.ExternalControllerNotRunning, fmt.Sprintf("GatewayClass %s was never accepted by its controller", class.GetName())
+}
+
+func isPodReady(pod *corev1.Pod) bool {
+	for _, c := range pod.Status.Conditions {
+		if c.Type == corev1.PodReady {
+			return c.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// networkingFinalizer returns the preferred finalizer if present, otherwise the first finalizer
+// that is not handled by the garbage collector
+func networkingFinalizer(finalizers []string, preferred string) string {
+	if preferred != "" && containsString(finalizers, preferred) {
+		return preferred
+	}
+	for _, f := range finalizers {
+		if f != metav1.FinalizerOrphanDependents && f != metav1.FinalizerDeleteDependents {
+			return f
+		}
+	}
+	return ""
+}
+
+// ingressFinalizer returns the first finalizer of a known load balancer controller
+func ingressFinalizer(finalizers []string) string {
+	for _, f := range finalizers {
+		for _, prefix := range ingressLoadBalancerFinalizers {
+			if strings.HasPrefix(f, prefix) {
+				return f
+			}
+		}
+	}
+	return ""
+}
+
+func loadBalancerAddresses(ingress []corev1.LoadBalancerIngress) []string {
+	var addresses []string
+	for _, i := range ingress {
+		if i.IP != "" {
+			addresses = append(addresses, i.IP)
+		}
+		if i.Hostname != "" {
+			addresses = append(addresses, i.Hostname)
+		}
+	}
+	return addresses
+}
+
+func ingressAddresses(ingress []networkingv1.IngressLoadBalancerIngress) []string {
+	var addresses []string
+	for _, i := range ingress {
+		if i.IP != "" {
+			addresses = append(addresses, i.IP)
+		}
+		if i.Hostname != "" {
+			addresses = append(addresses, i.Hostname)
+		}
+	}
+	return addresses
+}
+
+// analyzeNetworking attaches the load balancers that networking finalizers clean up to the blockers
+func analyzeNetworking(ctx context.Context, client *kube.Client, report *types.DiagnosisReport) {
+	NewNetworkingDetector(client).Analyze(ctx, report.Blockers, report.Controllers)
+}
+
+// networkingRecommendations warns that removing networking finalizers leaks load balancers
+func networkingRecommendations(report *types.DiagnosisReport) []string {
+	var recs []string
+	for _, b := range report.ExternalBlockers() {
+		e := b.External
+		switch e.ControllerState {
+		case types.ExternalControllerRunning:
+			recs = append(recs, fmt.Sprintf(
+				"%s owns %s and its controller %s is running; check its events for cloud errors instead of removing %s, which would leak the load balancer.",
+				b.ResourceRef.String(), e.Description(), e.Controller, e.Finalizer,
+			))
+		default:
+			rec := fmt.Sprintf(
+				"%s owns %s that is only deleted by %s. Removing the finalizer leaks it; delete it in the cloud provider afterwards.",
+				b.ResourceRef.String(), e.Description(), e.Finalizer,
+			)
+			if e.Message != "" {
+				rec = fmt.Sprintf("%s (%s)", rec, e.Message)
+			}
+			recs = append(recs, rec)
+		}
+	}
+	return recs
+}
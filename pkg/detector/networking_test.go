@@ -0,0 +1,213 @@
+package detector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/sozercan/unstuck/pkg/kube"
+	"github.com/sozercan/unstuck/pkg/types"
+)
+
+func TestNetworkingDetector_Analyze(t *testing.T) {
+	lbClass := "service.k8s.aws/nlb"
+	ingressClass := "alb"
+
+	objects := []runtime.Object{
+		&corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "apps", Name: "web"},
+			Spec:       corev1.ServiceSpec{Type: corev1.ServiceTypeLoadBalancer},
+			Status: corev1.ServiceStatus{LoadBalancer: corev1.LoadBalancerStatus{
+				Ingress: []corev1.LoadBalancerIngress{{IP: "203.0.113.10"}},
+			}},
+		},
+		&corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "apps", Name: "nlb"},
+			Spec:       corev1.ServiceSpec{Type: corev1.ServiceTypeLoadBalancer, LoadBalancerClass: &lbClass},
+		},
+		&corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "apps", Name: "internal"},
+			Spec:       corev1.ServiceSpec{Type: corev1.ServiceTypeClusterIP},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "kube-system", Name: "cloud-controller-manager-abc"},
+			Status:     corev1.PodStatus{Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionFalse}}},
+		},
+		&networkingv1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "apps", Name: "web"},
+			Spec:       networkingv1.IngressSpec{IngressClassName: &ingressClass},
+			Status: networkingv1.IngressStatus{LoadBalancer: networkingv1.IngressLoadBalancerStatus{
+				Ingress: []networkingv1.IngressLoadBalancerIngress{{Hostname: "k8s-apps-web.elb.amazonaws.com"}},
+			}},
+		},
+	}
+
+	gateway := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "gateway.networking.k8s.io/v1",
+		"kind":       "Gateway",
+		"metadata":   map[string]interface{}{"namespace": "apps", "name": "public"},
+		"spec":       map[string]interface{}{"gatewayClassName": "gke-l7-global-external-managed"},
+		"status": map[string]interface{}{
+			"addresses": []interface{}{map[string]interface{}{"type": "IPAddress", "value": "198.51.100.7"}},
+		},
+	}}
+	gatewayClass := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "gateway.networking.k8s.io/v1",
+		"kind":       "GatewayClass",
+		"metadata":   map[string]interface{}{"name": "gke-l7-global-external-managed"},
+		"spec":       map[string]interface{}{"controllerName": "networking.gke.io/gateway"},
+		"status": map[string]interface{}{
+			"conditions": []interface{}{map[string]interface{}{"type": "Accepted", "status": "True"}},
+		},
+	}}
+	dynamic := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		{Group: "gateway.networking.k8s.io", Version: "v1", Resource: "gateways"}:       "GatewayList",
+		{Group: "gateway.networking.k8s.io", Version: "v1", Resource: "gatewayclasses"}: "GatewayClassList",
+	}, gatewayClass)
+	// The fake tracker would guess "gatewaies" as the plural of Gateway
+	gatewaysGVR := schema.GroupVersionResource{Group: "gateway.networking.k8s.io", Version: "v1", Resource: "gateways"}
+	require.NoError(t, dynamic.Tracker().Create(gatewaysGVR, gateway, "apps"))
+
+	client := &kube.Client{Clientset: fake.NewSimpleClientset(objects...), Dynamic: dynamic}
+
+	blocker := func(apiVersion, kind, name string, finalizers ...string) types.Blocker {
+		return types.Blocker{
+			ResourceRef: types.ResourceRef{APIVersion: apiVersion, Kind: kind, Namespace: "apps", Name: name},
+			Finalizers:  finalizers,
+		}
+	}
+	blockers := []types.Blocker{
+		blocker("v1", "Service", "web", loadBalancerCleanupFinalizer),
+		blocker("v1", "Service", "nlb", "service.k8s.aws/resources"),
+		blocker("v1", "Service", "internal", "example.com/cleanup"),
+		blocker("networking.k8s.io/v1", "Ingress", "web", "ingress.k8s.aws/resources"),
+		blocker("gateway.networking.k8s.io/v1", "Gateway", "public", "gateway.networking.k8s.io/finalizer"),
+		blocker("networking.k8s.io/v1", "Ingress", "missing", "ingress.k8s.aws/resources"),
+	}
+	controllers := []types.ControllerStatus{{
+		Name:       "aws-load-balancer-controller",
+		Namespace:  "kube-system",
+		Kind:       "Deployment",
+		Finalizers: []string{"service.k8s.aws/resources"},
+		Available:  true,
+		Ready:      true,
+	}}
+
+	NewNetworkingDetector(client).Analyze(context.Background(), blockers, controllers)
+
+	web := blockers[0].External
+	require.NotNil(t, web)
+	assert.Equal(t, types.ExternalLoadBalancer, web.Type)
+	assert.Equal(t, []string{"203.0.113.10"}, web.Addresses)
+	assert.Equal(t, "cloud-controller-manager", web.Controller)
+	assert.Equal(t, types.ExternalControllerNotRunning, web.ControllerState)
+
+	nlb := blockers[1].External
+	require.NotNil(t, nlb)
+	assert.Equal(t, lbClass, nlb.Class)
+	assert.Equal(t, "kube-system/aws-load-balancer-controller", nlb.Controller)
+	assert.Equal(t, types.ExternalControllerRunning, nlb.ControllerState)
+	assert.Equal(t, "LoadBalancer (no address in status)", nlb.Description())
+
+	assert.Nil(t, blockers[2].External, "ClusterIP services have no load balancer")
+
+	ing := blockers[3].External
+	require.NotNil(t, ing)
+	assert.Equal(t, types.ExternalIngress, ing.Type)
+	assert.Equal(t, []string{"k8s-apps-web.elb.amazonaws.com"}, ing.Addresses)
+	assert.Equal(t, types.ExternalControllerNotRunning, ing.ControllerState)
+	assert.Contains(t, ing.Message, "IngressClass alb not found")
+
+	gw := blockers[4].External
+	require.NotNil(t, gw)
+	assert.Equal(t, types.ExternalGateway, gw.Type)
+	assert.Equal(t, []string{"198.51.100.7"}, gw.Addresses)
+	assert.Equal(t, "networking.gke.io/gateway", gw.Controller)
+	assert.Equal(t, types.ExternalControllerRunning, gw.ControllerState)
+
+	assert.Nil(t, blockers[5].External, "objects that cannot be read are skipped")
+}
+
+func TestNetworkingRecommendations(t *testing.T) {
+	report := &types.DiagnosisReport{Blockers: []types.Blocker{{
+		ResourceRef: types.ResourceRef{Kind: "Service", Namespace: "apps", Name: "web"},
+		External: &types.ExternalResource{
+			Type:            types.ExternalLoadBalancer,
+			Finalizer:       loadBalancerCleanupFinalizer,
+			Addresses:       []string{"203.0.113.10"},
+			ControllerState: types.ExternalControllerUnknown,
+			Message:         "no cloud-controller-manager pod in kube-system",
+		},
+	}}}
+
+	recs := networkingRecommendations(report)
+	require.Len(t, recs, 1)
+	assert.Contains(t, recs[0], "LoadBalancer 203.0.113.10")
+	assert.Contains(t, recs[0], "leaks it")
+
+	assert.Empty(t, networkingRecommendations(&types.DiagnosisReport{}))
+}
+
+func TestNetworkingDetector_IngressController(t *testing.T) {
+	alb, gce := "alb", "gce"
+	ready := corev1.PodStatus{Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}}}
+	ingress := func(name string, class *string) *networkingv1.Ingress {
+		return &networkingv1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "apps", Name: name},
+			Spec:       networkingv1.IngressSpec{IngressClassName: class},
+		}
+	}
+	client := &kube.Client{Clientset: fake.NewSimpleClientset(
+		&networkingv1.IngressClass{
+			ObjectMeta: metav1.ObjectMeta{Name: alb},
+			Spec:       networkingv1.IngressClassSpec{Controller: "ingress.k8s.aws/alb"},
+		},
+		&networkingv1.IngressClass{
+			ObjectMeta: metav1.ObjectMeta{Name: gce},
+			Spec:       networkingv1.IngressClassSpec{Controller: "networking.gke.io/ingress-gce"},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "kube-system", Name: "aws-load-balancer-controller-5d9f"},
+			Status:     ready,
+		},
+		ingress("web", &alb),
+		ingress("gke", &gce),
+		ingress("cert", &alb),
+	)}
+
+	blocker := func(name, finalizer string) types.Blocker {
+		return types.Blocker{
+			ResourceRef: types.ResourceRef{APIVersion: "networking.k8s.io/v1", Kind: "Ingress", Namespace: "apps", Name: name},
+			Finalizers:  []string{finalizer},
+		}
+	}
+	blockers := []types.Blocker{
+		blocker("web", "ingress.k8s.aws/resources"),
+		blocker("gke", "networking.gke.io/ingress-finalizer-V2"),
+		blocker("cert", "example.com/certificate"),
+	}
+
+	NewNetworkingDetector(client).Analyze(context.Background(), blockers, nil)
+
+	web := blockers[0].External
+	require.NotNil(t, web)
+	assert.Equal(t, "ingress.k8s.aws/alb", web.Controller)
+	assert.Equal(t, types.ExternalControllerRunning, web.ControllerState)
+
+	gke := blockers[1].External
+	require.NotNil(t, gke)
+	assert.Equal(t, types.ExternalControllerUnknown, gke.ControllerState)
+	assert.Contains(t, gke.Message, "no ingress-gce pod found")
+
+	assert.Nil(t, blockers[2].External, "finalizers of other controllers do not clean up load balancers")
+}
@@ -0,0 +1,225 @@
+package detector
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	admissionv1 "k8s.io/api/admissionregistration/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/sozercan/unstuck/pkg/kube"
+	"github.com/sozercan/unstuck/pkg/types"
+)
+
+// AdmissionPolicyDetector finds ValidatingAdmissionPolicies whose CEL rules run on the UPDATE
+// that removes a blocker's finalizers
+type AdmissionPolicyDetector struct {
+	client          *kube.Client
+	mapper          *resourceMapper
+	namespaceLabels map[string]labels.Set
+}
+
+// NewAdmissionPolicyDetector creates a new admission policy detector
+func NewAdmissionPolicyDetector(client *kube.Client) *AdmissionPolicyDetector {
+	return &AdmissionPolicyDetector{
+		client:          client,
+		mapper:          newResourceMapper(client),
+		namespaceLabels: make(map[string]labels.Set),
+	}
+}
+
+// DetectForBlockers returns the policy bindings with a Deny action that cover an UPDATE of any blocker
+func (d *AdmissionPolicyDetector) DetectForBlockers(ctx context.Context, blockers []types.Blocker) ([]types.AdmissionPolicyInfo, error) {
+	if len(blockers) == 0 {
+		return nil, nil
+	}
+
+	policies, err := d.client.Clientset.AdmissionregistrationV1().ValidatingAdmissionPolicies().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list validating admission policies: %w", err)
+	}
+	if len(policies.Items) == 0 {
+		return nil, nil
+	}
+	bindings, err := d.client.Clientset.AdmissionregistrationV1().ValidatingAdmissionPolicyBindings().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list validating admission policy bindings: %w", err)
+	}
+
+	policyByName := make(map[string]*admissionv1.ValidatingAdmissionPolicy, len(policies.Items))
+	for i := range policies.Items {
+		policyByName[policies.Items[i].Name] = &policies.Items[i]
+	}
+
+	var infos []types.AdmissionPolicyInfo
+	for _, binding := range bindings.Items {
+		policy, ok := policyByName[binding.Spec.PolicyName]
+		if !ok || !hasDenyAction(binding.Spec.ValidationActions) {
+			continue
+		}
+
+		info := types.AdmissionPolicyInfo{
+			Policy:        policy.Name,
+			Binding:       binding.Name,
+			FailurePolicy: string(admissionv1.Fail),
+		}
+		for _, a := range binding.Spec.ValidationActions {
+			info.ValidationActions = append(info.ValidationActions, string(a))
+		}
+		if policy.Spec.FailurePolicy != nil {
+			info.FailurePolicy = string(*policy.Spec.FailurePolicy)
+		}
+		for _, v := range policy.Spec.Validations {
+			info.Validations = append(info.Validations, v.Expression)
+		}
+
+		for _, b := range blockers {
+			matched, conditional := d.matches(ctx, policy, &binding, b)
+			if !matched {
+				continue
+			}
+			info.Blockers = append(info.Blockers, b.ResourceRef)
+			info.Conditional = info.Conditional || conditional
+		}
+		if len(info.Blockers) > 0 {
+			infos = append(infos, info)
+		}
+	}
+
+	return infos, nil
+}
+
+// matches returns whether the policy and binding cover an UPDATE of the blocker, and whether the
+// match depends on object selectors or match conditions that cannot be evaluated here
+func (d *AdmissionPolicyDetector) matches(ctx context.Context, policy *admissionv1.ValidatingAdmissionPolicy, binding *admissionv1.ValidatingAdmissionPolicyBinding, b types.Blocker) (bool, bool) {
+	// A policy without match constraints does not match anything
+	if policy.Spec.MatchConstraints == nil {
+		return false, false
+	}
+
+	gvr, namespaced, err := d.mapper.resourceFor(b.APIVersion, b.Kind)
+	if err != nil || gvr.Empty() {
+		return false, false
+	}
+
+	conditional := len(policy.Spec.MatchConditions) > 0
+	for _, mr := range []*admissionv1.MatchResources{policy.Spec.MatchConstraints, binding.Spec.MatchResources} {
+		if mr == nil {
+			continue
+		}
+		matched, cond := d.matchResources(ctx, mr, gvr, namespaced, b)
+		if !matched {
+			return false, false
+		}
+		conditional = conditional || cond
+	}
+	return true, conditional
+}
+
+// matchResources evaluates resource rules and the namespace selector for an UPDATE of the blocker.
+// Object selectors need the blocker's labels and are reported as conditional instead.
+func (d *AdmissionPolicyDetector) matchResources(ctx context.Context, mr *admissionv1.MatchResources, gvr schema.GroupVersionResource, namespaced bool, b types.Blocker) (bool, bool) {
+	// Unset resource rules on a binding do not constrain the policy's match
+	if len(mr.ResourceRules) > 0 && !matchesNamedRules(mr.ResourceRules, gvr, b.Name) {
+		return false, false
+	}
+	if matchesNamedRules(mr.ExcludeResourceRules, gvr, b.Name) {
+		return false, false
+	}
+
+	conditional := false
+	if mr.ObjectSelector != nil && (len(mr.ObjectSelector.MatchLabels) > 0 || len(mr.ObjectSelector.MatchExpressions) > 0) {
+		conditional = true
+	}
+
+	// The namespace selector never skips cluster-scoped objects
+	if namespaced && mr.NamespaceSelector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(mr.NamespaceSelector)
+		if err != nil {
+			return false, false
+		}
+		if !selector.Empty() {
+			nsLabels, err := d.labelsOfNamespace(ctx, b.Namespace)
+			if err != nil {
+				conditional = true
+			} else if !selector.Matches(nsLabels) {
+				return false, false
+			}
+		}
+	}
+
+	return true, conditional
+}
+
+func (d *AdmissionPolicyDetector) labelsOfNamespace(ctx context.Context, name string) (labels.Set, error) {
+	if set, ok := d.namespaceLabels[name]; ok {
+		return set, nil
+	}
+	ns, err := d.client.Clientset.CoreV1().Namespaces().Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	d.namespaceLabels[name] = labels.Set(ns.Labels)
+	return d.namespaceLabels[name], nil
+}
+
+// matchesNamedRules returns true if any rule covers an UPDATE of the named object
+func matchesNamedRules(rules []admissionv1.NamedRuleWithOperations, gvr schema.GroupVersionResource, name string) bool {
+	for _, r := range rules {
+		if !matchesOperation(r.Operations, admissionv1.Update) || !matchesRule(r.Rule, gvr) {
+			continue
+		}
+		if len(r.ResourceNames) > 0 && !containsString(r.ResourceNames, name) {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+func matchesOperation(operations []admissionv1.OperationType, op admissionv1.OperationType) bool {
+	for _, o := range operations {
+		if o == admissionv1.OperationAll || o == op {
+			return true
+		}
+	}
+	return false
+}
+
+func hasDenyAction(actions []admissionv1.ValidationAction) bool {
+	for _, a := range actions {
+		if a == admissionv1.Deny {
+			return true
+		}
+	}
+	return false
+}
+
+// correlateAdmissionPolicies attaches the admission policies that validate updates of blockers
+func correlateAdmissionPolicies(ctx context.Context, client *kube.Client, report *types.DiagnosisReport) {
+	policies, err := NewAdmissionPolicyDetector(client).DetectForBlockers(ctx, report.Blockers)
+	if err != nil {
+		return
+	}
+	report.AdmissionPolicies = policies
+}
+
+// admissionPolicyRecommendations explains how admission policies affect finalizer removal
+func admissionPolicyRecommendations(report *types.DiagnosisReport) []string {
+	var recs []string
+	for _, p := range report.AdmissionPolicies {
+		rec := fmt.Sprintf(
+			"ValidatingAdmissionPolicy %q (binding %q, actions %s) validates updates of %d blocker(s) and may deny finalizer removal.",
+			p.Policy, p.Binding, strings.Join(p.ValidationActions, ","), len(p.Blockers),
+		)
+		if p.Conditional {
+			rec += " The match depends on object labels or match conditions that were not evaluated."
+		}
+		rec += " Use `unstuck apply --dry-run=server` to see whether it rejects the patches."
+		recs = append(recs, rec)
+	}
+	return recs
+}
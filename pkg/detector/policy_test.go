@@ -0,0 +1,111 @@
+package detector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	admissionv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/sozercan/unstuck/pkg/kube"
+	"github.com/sozercan/unstuck/pkg/types"
+)
+
+func widgetPolicy(name string, operations ...admissionv1.OperationType) *admissionv1.ValidatingAdmissionPolicy {
+	return &admissionv1.ValidatingAdmissionPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: admissionv1.ValidatingAdmissionPolicySpec{
+			MatchConstraints: &admissionv1.MatchResources{
+				ResourceRules: []admissionv1.NamedRuleWithOperations{{
+					RuleWithOperations: admissionv1.RuleWithOperations{
+						Operations: operations,
+						Rule: admissionv1.Rule{
+							APIGroups:   []string{"example.com"},
+							APIVersions: []string{"*"},
+							Resources:   []string{"widgets"},
+						},
+					},
+				}},
+			},
+			Validations: []admissionv1.Validation{{Expression: "object.metadata.finalizers.size() > 0"}},
+		},
+	}
+}
+
+func policyBinding(name, policy string, mr *admissionv1.MatchResources, actions ...admissionv1.ValidationAction) *admissionv1.ValidatingAdmissionPolicyBinding {
+	return &admissionv1.ValidatingAdmissionPolicyBinding{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: admissionv1.ValidatingAdmissionPolicyBindingSpec{
+			PolicyName:        policy,
+			MatchResources:    mr,
+			ValidationActions: actions,
+		},
+	}
+}
+
+func TestAdmissionPolicyDetector_DetectForBlockers(t *testing.T) {
+	objectSelected := widgetPolicy("object-selected", admissionv1.Update)
+	objectSelected.Spec.MatchConstraints.ObjectSelector = &metav1.LabelSelector{MatchLabels: map[string]string{"protected": "true"}}
+
+	clientset := fake.NewSimpleClientset(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "apps", Labels: map[string]string{"env": "prod"}}},
+		widgetPolicy("protect-widgets", admissionv1.Update),
+		widgetPolicy("create-only", admissionv1.Create),
+		objectSelected,
+		policyBinding("protect-widgets-deny", "protect-widgets", nil, admissionv1.Deny),
+		policyBinding("protect-widgets-warn", "protect-widgets", nil, admissionv1.Warn),
+		policyBinding("protect-widgets-dev", "protect-widgets", &admissionv1.MatchResources{
+			NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"env": "dev"}},
+		}, admissionv1.Deny),
+		policyBinding("create-only", "create-only", nil, admissionv1.Deny),
+		policyBinding("object-selected", "object-selected", nil, admissionv1.Deny, admissionv1.Audit),
+	)
+	clientset.Discovery().(*fakediscovery.FakeDiscovery).Resources = []*metav1.APIResourceList{{
+		GroupVersion: "example.com/v1",
+		APIResources: []metav1.APIResource{{Name: "widgets", Kind: "Widget", Namespaced: true}},
+	}}
+	client := &kube.Client{Clientset: clientset, Discovery: clientset.Discovery()}
+
+	widget := types.ResourceRef{Kind: "Widget", APIVersion: "example.com/v1", Namespace: "apps", Name: "w1"}
+	blockers := []types.Blocker{
+		{ResourceRef: widget},
+		{ResourceRef: types.ResourceRef{Kind: "ConfigMap", APIVersion: "v1", Namespace: "apps", Name: "cm"}},
+	}
+
+	policies, err := NewAdmissionPolicyDetector(client).DetectForBlockers(context.Background(), blockers)
+	require.NoError(t, err)
+
+	byBinding := make(map[string]types.AdmissionPolicyInfo)
+	for _, p := range policies {
+		byBinding[p.Binding] = p
+	}
+	require.Len(t, byBinding, 2)
+
+	deny := byBinding["protect-widgets-deny"]
+	assert.Equal(t, "protect-widgets", deny.Policy)
+	assert.Equal(t, []types.ResourceRef{widget}, deny.Blockers)
+	assert.Equal(t, "Fail", deny.FailurePolicy)
+	assert.Equal(t, []string{"object.metadata.finalizers.size() > 0"}, deny.Validations)
+	assert.False(t, deny.Conditional)
+
+	selected := byBinding["object-selected"]
+	assert.True(t, selected.Conditional)
+	assert.Equal(t, []string{"Deny", "Audit"}, selected.ValidationActions)
+}
+
+func TestAdmissionPolicyDetector_NoPolicies(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	client := &kube.Client{Clientset: clientset, Discovery: clientset.Discovery()}
+
+	policies, err := NewAdmissionPolicyDetector(client).DetectForBlockers(context.Background(), []types.Blocker{
+		{ResourceRef: types.ResourceRef{Kind: "Widget", APIVersion: "example.com/v1", Namespace: "apps", Name: "w1"}},
+	})
+
+	require.NoError(t, err)
+	assert.Empty(t, policies)
+}
@@ -0,0 +1,258 @@
+package detector
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	admissionv1 "k8s.io/api/admissionregistration/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/sozercan/unstuck/pkg/kube"
+	"github.com/sozercan/unstuck/pkg/types"
+)
+
+// probeTarget is a blocker used to probe the webhooks that match its resource and namespace
+type probeTarget struct {
+	gvr        schema.GroupVersionResource
+	namespaced bool
+	blocker    types.ResourceRef
+}
+
+// webhookCandidate is a webhook whose rules cover an UPDATE of at least one blocker
+type webhookCandidate struct {
+	info    types.WebhookInfo
+	targets []probeTarget
+}
+
+// ProbeForBlockers returns the webhooks that intercept an UPDATE of any blocker. Each is probed
+// with a server-side dry-run UPDATE of a blocker it matches, so Healthy reflects whether the
+// webhook actually admits the request. Webhooks that could not be probed keep the result of the
+// service health check.
+func (d *WebhookDetector) ProbeForBlockers(ctx context.Context, blockers []types.Blocker) ([]types.WebhookInfo, error) {
+	if len(blockers) == 0 {
+		return nil, nil
+	}
+
+	targets := probeTargets(newResourceMapper(d.client), blockers)
+	if len(targets) == 0 {
+		return nil, nil
+	}
+
+	candidates, err := d.candidatesForUpdate(ctx, targets)
+	if err != nil {
+		return nil, err
+	}
+
+	// Webhooks match on resource and namespace, not on names, so one dry-run per pair is enough
+	results := make(map[probeTarget]error)
+	infos := make([]types.WebhookInfo, 0, len(candidates))
+	for _, c := range candidates {
+		for _, t := range c.targets {
+			probeErr, ok := results[t]
+			if !ok {
+				probeErr = d.dryRunUpdate(ctx, t)
+				results[t] = probeErr
+			}
+			applyProbeResult(&c.info, t.blocker, probeErr)
+			if c.info.ProbedWith != nil && !c.info.Healthy {
+				break
+			}
+		}
+		infos = append(infos, c.info)
+	}
+
+	return infos, nil
+}
+
+// probeTargets picks one blocker per resource and namespace
+func probeTargets(mapper *resourceMapper, blockers []types.Blocker) []probeTarget {
+	var targets []probeTarget
+	seen := make(map[string]bool)
+	for _, b := range blockers {
+		gvr, namespaced, err := mapper.resourceFor(b.APIVersion, b.Kind)
+		if err != nil || gvr.Empty() {
+			continue
+		}
+		key := gvr.String() + "/" + b.Namespace
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		targets = append(targets, probeTarget{gvr: gvr, namespaced: namespaced, blocker: b.ResourceRef})
+	}
+	return targets
+}
+
+// candidatesForUpdate lists the validating and mutating webhooks whose rules cover an UPDATE of
+// any target, with the result of the service health check as a baseline
+func (d *WebhookDetector) candidatesForUpdate(ctx context.Context, targets []probeTarget) ([]webhookCandidate, error) {
+	var candidates []webhookCandidate
+
+	vwcs, err := d.client.Clientset.AdmissionregistrationV1().ValidatingWebhookConfigurations().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list validating webhooks: %w", err)
+	}
+	for _, vwc := range vwcs.Items {
+		for _, webhook := range vwc.Webhooks {
+			matched := targetsForUpdate(webhook.Rules, targets)
+			if len(matched) == 0 {
+				continue
+			}
+			info := d.buildWebhookInfoWithHealth(ctx, vwc.ObjectMeta, webhook.Name, "validating", webhook.ClientConfig, webhook.FailurePolicy)
+			candidates = append(candidates, webhookCandidate{info: withURL(info, webhook.ClientConfig), targets: matched})
+		}
+	}
+
+	mwcs, err := d.client.Clientset.AdmissionregistrationV1().MutatingWebhookConfigurations().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list mutating webhooks: %w", err)
+	}
+	for _, mwc := range mwcs.Items {
+		for _, webhook := range mwc.Webhooks {
+			matched := targetsForUpdate(webhook.Rules, targets)
+			if len(matched) == 0 {
+				continue
+			}
+			info := d.buildWebhookInfoWithHealth(ctx, mwc.ObjectMeta, webhook.Name, "mutating", webhook.ClientConfig, webhook.FailurePolicy)
+			candidates = append(candidates, webhookCandidate{info: withURL(info, webhook.ClientConfig), targets: matched})
+		}
+	}
+
+	return candidates, nil
+}
+
+// targetsForUpdate returns the targets whose resource is covered by a rule for UPDATE
+func targetsForUpdate(rules []admissionv1.RuleWithOperations, targets []probeTarget) []probeTarget {
+	var matched []probeTarget
+	for _, t := range targets {
+		for _, r := range rules {
+			if matchesOperation(r.Operations, admissionv1.Update) && matchesRule(r.Rule, t.gvr) {
+				matched = append(matched, t)
+				break
+			}
+		}
+	}
+	return matched
+}
+
+// withURL records the URL of webhooks that are not backed by a service. Those cannot be
+// checked passively and are only known to be unhealthy once a probe fails.
+func withURL(info types.WebhookInfo, clientConfig admissionv1.WebhookClientConfig) types.WebhookInfo {
+	if clientConfig.URL != nil {
+		info.URL = *clientConfig.URL
+	}
+	return info
+}
+
+// dryRunUpdate sends the blocker back unchanged with dryRun=All. The API server runs the full
+// admission chain, including webhooks, without persisting anything.
+func (d *WebhookDetector) dryRunUpdate(ctx context.Context, t probeTarget) error {
+	namespace := t.blocker.Namespace
+	if !t.namespaced {
+		namespace = ""
+	}
+	resource := d.client.Dynamic.Resource(t.gvr).Namespace(namespace)
+
+	obj, err := resource.Get(ctx, t.blocker.Name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	_, err = resource.Update(ctx, obj, metav1.UpdateOptions{DryRun: []string{metav1.DryRunAll}})
+	return err
+}
+
+// applyProbeResult updates the webhook from the outcome of a dry-run UPDATE. Errors that do not
+// come from admission (RBAC, conflicts, webhooks without dry-run support) say nothing about the
+// webhook and leave the service health result in place.
+func applyProbeResult(info *types.WebhookInfo, blocker types.ResourceRef, probeErr error) {
+	ref := blocker
+	if probeErr == nil {
+		// A failure seen with another blocker still stands
+		if info.ProbedWith == nil {
+			info.Healthy = true
+			info.Error = ""
+			info.ErrorType = ""
+			info.ProbedWith = &ref
+		}
+		return
+	}
+
+	isWebhook, errorType := IsWebhookError(probeErr)
+	// A ValidatingAdmissionPolicy denial is reported by the policy detector, not by a webhook
+	if !isWebhook || errorType == "policy_denied" {
+		return
+	}
+	// An error naming another webhook means the chain stopped before reaching this one
+	if name := ExtractWebhookNameFromError(probeErr); name != "" && name != info.WebhookName {
+		return
+	}
+
+	info.Healthy = false
+	info.Error = probeErr.Error()
+	info.ErrorType = errorType
+	info.ProbedWith = &ref
+}
+
+// correlateWebhooks probes the webhooks that intercept blocker updates and reports the
+// unhealthy ones, promoting the first to the root cause
+func correlateWebhooks(ctx context.Context, client *kube.Client, report *types.DiagnosisReport) {
+	webhooks, err := NewWebhookDetector(client).ProbeForBlockers(ctx, report.Blockers)
+	if err != nil {
+		return
+	}
+	for _, w := range webhooks {
+		if !w.Healthy {
+			report.WebhookIssues = append(report.WebhookIssues, w)
+		}
+	}
+
+	if !report.HasWebhookIssues() || report.HasSelfDeadlock() || report.HasDiscoveryFailures() || report.HasCorruptObjects() {
+		return
+	}
+	w := report.WebhookIssues[0]
+	reason := w.Error
+	if w.ErrorType != "" {
+		reason = WebhookErrorTypeDescription(w.ErrorType)
+	}
+	verb := "may reject"
+	if w.RejectsUpdates() {
+		verb = "rejects"
+	}
+	report.RootCause = fmt.Sprintf("Webhook %q %s updates of blockers - %s", w.WebhookName, verb, reason)
+}
+
+// webhookRecommendations explains how to unblock updates rejected by webhooks
+func webhookRecommendations(report *types.DiagnosisReport) []string {
+	var recs []string
+	for _, w := range report.WebhookIssues {
+		kind := "mutatingwebhookconfiguration"
+		if w.Type == "validating" {
+			kind = "validatingwebhookconfiguration"
+		}
+
+		var source string
+		switch {
+		case w.ProbedWith != nil:
+			source = fmt.Sprintf("a dry-run update of %s failed", w.ProbedWith.String())
+		case w.ServiceRef != "":
+			source = fmt.Sprintf("service %s is not serving", w.ServiceRef)
+		default:
+			source = "it is unreachable"
+		}
+
+		outcome := "may fail"
+		if w.RejectsUpdates() {
+			outcome = "will fail"
+		}
+		rec := fmt.Sprintf("Webhook %q (%s %s) is unhealthy: %s. Finalizer removal %s until it is restored", w.WebhookName, kind, w.Name, source, outcome)
+		if strings.EqualFold(w.FailurePolicy, string(admissionv1.Ignore)) {
+			rec += "; its failurePolicy is Ignore, so only explicit denials block updates."
+		} else {
+			rec += fmt.Sprintf(" or `kubectl delete %s %s` is run (use with caution).", kind, w.Name)
+		}
+		recs = append(recs, rec)
+	}
+	return recs
+}
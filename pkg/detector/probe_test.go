@@ -0,0 +1,205 @@
+package detector
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	admissionv1 "k8s.io/api/admissionregistration/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/sozercan/unstuck/pkg/kube"
+	"github.com/sozercan/unstuck/pkg/types"
+)
+
+func widgetWebhookRules(operations ...admissionv1.OperationType) []admissionv1.RuleWithOperations {
+	return []admissionv1.RuleWithOperations{{
+		Operations: operations,
+		Rule: admissionv1.Rule{
+			APIGroups:   []string{"example.com"},
+			APIVersions: []string{"v1"},
+			Resources:   []string{"widgets"},
+		},
+	}}
+}
+
+func newProbeClient(t *testing.T, updateErr error, objects ...runtime.Object) *kube.Client {
+	t.Helper()
+
+	clientset := fake.NewSimpleClientset(objects...)
+	clientset.Discovery().(*fakediscovery.FakeDiscovery).Resources = []*metav1.APIResourceList{{
+		GroupVersion: "example.com/v1",
+		APIResources: []metav1.APIResource{{Name: "widgets", Kind: "Widget", Namespaced: true}},
+	}}
+
+	widget := &unstructured.Unstructured{}
+	widget.SetAPIVersion("example.com/v1")
+	widget.SetKind("Widget")
+	widget.SetNamespace("apps")
+	widget.SetName("w1")
+	dynamic := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), widget)
+	dynamic.PrependReactor("update", "widgets", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if updateErr != nil {
+			return true, nil, updateErr
+		}
+		return false, nil, nil
+	})
+
+	return &kube.Client{Clientset: clientset, Dynamic: dynamic, Discovery: clientset.Discovery()}
+}
+
+func TestWebhookDetector_ProbeForBlockers(t *testing.T) {
+	url := "https://webhooks.example.com/validate"
+	vwc := &admissionv1.ValidatingWebhookConfiguration{
+		ObjectMeta: metav1.ObjectMeta{Name: "widget-policy"},
+		Webhooks: []admissionv1.ValidatingWebhook{
+			{
+				Name:         "validate.example.com",
+				ClientConfig: admissionv1.WebhookClientConfig{URL: &url},
+				Rules:        widgetWebhookRules(admissionv1.Update),
+			},
+			{
+				Name:         "create-only.example.com",
+				ClientConfig: admissionv1.WebhookClientConfig{URL: &url},
+				Rules:        widgetWebhookRules(admissionv1.Create),
+			},
+		},
+	}
+	mwc := &admissionv1.MutatingWebhookConfiguration{
+		ObjectMeta: metav1.ObjectMeta{Name: "widget-defaults"},
+		Webhooks: []admissionv1.MutatingWebhook{{
+			Name:         "default.example.com",
+			ClientConfig: admissionv1.WebhookClientConfig{URL: &url},
+			Rules:        widgetWebhookRules(admissionv1.OperationAll),
+		}},
+	}
+	blocker := types.ResourceRef{Kind: "Widget", APIVersion: "example.com/v1", Namespace: "apps", Name: "w1"}
+	blockers := []types.Blocker{{ResourceRef: blocker}, {ResourceRef: types.ResourceRef{Kind: "Widget", APIVersion: "example.com/v1", Namespace: "apps", Name: "w2"}}}
+
+	t.Run("failed call marks the named webhook", func(t *testing.T) {
+		updateErr := errors.New(`Internal error occurred: failed calling webhook "validate.example.com": Post "https://webhooks.example.com/validate": tls: failed to verify certificate`)
+		client := newProbeClient(t, updateErr, vwc, mwc)
+
+		webhooks, err := NewWebhookDetector(client).ProbeForBlockers(context.Background(), blockers)
+		require.NoError(t, err)
+		require.Len(t, webhooks, 2)
+
+		validate := webhooks[0]
+		assert.Equal(t, "validate.example.com", validate.WebhookName)
+		assert.Equal(t, url, validate.URL)
+		assert.False(t, validate.Healthy)
+		assert.Equal(t, "webhook_failed", validate.ErrorType)
+		assert.Equal(t, updateErr.Error(), validate.Error)
+		require.NotNil(t, validate.ProbedWith)
+		assert.Equal(t, blocker, *validate.ProbedWith)
+
+		// The error names another webhook, so the probe says nothing about this one
+		mutate := webhooks[1]
+		assert.Equal(t, "default.example.com", mutate.WebhookName)
+		assert.True(t, mutate.Healthy)
+		assert.Nil(t, mutate.ProbedWith)
+	})
+
+	t.Run("successful probe overrides service check", func(t *testing.T) {
+		serviceBacked := &admissionv1.ValidatingWebhookConfiguration{
+			ObjectMeta: metav1.ObjectMeta{Name: "widget-service"},
+			Webhooks: []admissionv1.ValidatingWebhook{{
+				Name: "service.example.com",
+				ClientConfig: admissionv1.WebhookClientConfig{
+					Service: &admissionv1.ServiceReference{Namespace: "system", Name: "missing"},
+				},
+				Rules: widgetWebhookRules(admissionv1.Update),
+			}},
+		}
+		client := newProbeClient(t, nil, serviceBacked)
+
+		webhooks, err := NewWebhookDetector(client).ProbeForBlockers(context.Background(), blockers)
+		require.NoError(t, err)
+		require.Len(t, webhooks, 1)
+		assert.True(t, webhooks[0].Healthy)
+		assert.Empty(t, webhooks[0].Error)
+		require.NotNil(t, webhooks[0].ProbedWith)
+	})
+
+	t.Run("non-admission errors keep service check", func(t *testing.T) {
+		serviceBacked := &admissionv1.ValidatingWebhookConfiguration{
+			ObjectMeta: metav1.ObjectMeta{Name: "widget-service"},
+			Webhooks: []admissionv1.ValidatingWebhook{{
+				Name: "service.example.com",
+				ClientConfig: admissionv1.WebhookClientConfig{
+					Service: &admissionv1.ServiceReference{Namespace: "system", Name: "missing"},
+				},
+				Rules: widgetWebhookRules(admissionv1.Update),
+			}},
+		}
+		client := newProbeClient(t, errors.New(`widgets.example.com "w1" is forbidden: User cannot update resource`), serviceBacked)
+
+		webhooks, err := NewWebhookDetector(client).ProbeForBlockers(context.Background(), blockers)
+		require.NoError(t, err)
+		require.Len(t, webhooks, 1)
+		assert.False(t, webhooks[0].Healthy)
+		assert.Equal(t, "service system/missing not found", webhooks[0].Error)
+		assert.Nil(t, webhooks[0].ProbedWith)
+	})
+
+	t.Run("policy denial leaves webhooks untouched", func(t *testing.T) {
+		updateErr := errors.New(`widgets.example.com "w1" is forbidden: ValidatingAdmissionPolicy 'immutable-widgets' with binding 'immutable-widgets' denied request: widgets are immutable`)
+		client := newProbeClient(t, updateErr, vwc)
+
+		webhooks, err := NewWebhookDetector(client).ProbeForBlockers(context.Background(), blockers)
+		require.NoError(t, err)
+		require.Len(t, webhooks, 1)
+		assert.True(t, webhooks[0].Healthy)
+		assert.Empty(t, webhooks[0].ErrorType)
+		assert.Nil(t, webhooks[0].ProbedWith)
+	})
+}
+
+func TestCorrelateWebhooks_RootCause(t *testing.T) {
+	vwc := &admissionv1.ValidatingWebhookConfiguration{
+		ObjectMeta: metav1.ObjectMeta{Name: "widget-policy"},
+		Webhooks: []admissionv1.ValidatingWebhook{{
+			Name:         "validate.example.com",
+			ClientConfig: admissionv1.WebhookClientConfig{Service: &admissionv1.ServiceReference{Namespace: "system", Name: "policy"}},
+			Rules:        widgetWebhookRules(admissionv1.Update),
+		}},
+	}
+	client := newProbeClient(t, errors.New(`admission webhook "validate.example.com" denied the request: widgets are immutable`), vwc)
+
+	report := &types.DiagnosisReport{
+		RootCause: "Resource has finalizers: [example.com/cleanup]",
+		Blockers: []types.Blocker{{
+			ResourceRef: types.ResourceRef{Kind: "Widget", APIVersion: "example.com/v1", Namespace: "apps", Name: "w1"},
+		}},
+	}
+	correlateWebhooks(context.Background(), client, report)
+
+	require.Len(t, report.WebhookIssues, 1)
+	assert.Equal(t, "webhook_denied", report.WebhookIssues[0].ErrorType)
+	assert.Equal(t, `Webhook "validate.example.com" rejects updates of blockers - The webhook actively denied the request`, report.RootCause)
+	assert.Len(t, webhookRecommendations(report), 1)
+}
+
+func TestWebhookRecommendations_ServiceCheckOnly(t *testing.T) {
+	report := &types.DiagnosisReport{
+		WebhookIssues: []types.WebhookInfo{{
+			Name:          "widget-policy",
+			WebhookName:   "validate.example.com",
+			Type:          "validating",
+			ServiceRef:    "system/policy",
+			FailurePolicy: "Ignore",
+		}},
+	}
+
+	recs := webhookRecommendations(report)
+	require.Len(t, recs, 1)
+	assert.Contains(t, recs[0], "Finalizer removal may fail until it is restored")
+}
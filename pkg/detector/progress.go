@@ -0,0 +1,154 @@
+package detector
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/sozercan/unstuck/pkg/types"
+)
+
+// progressSamples is how many snapshots SampleProgress takes across the sampling window
+const progressSamples = 3
+
+// SnapshotFunc counts what is left of a deletion
+type SnapshotFunc func(ctx context.Context) (types.ProgressSample, error)
+
+// SampleProgress takes snapshots spread evenly over the duration and classifies the
+// deletion as progressing or stalled. Sampling stops early once nothing remains.
+func SampleProgress(ctx context.Context, snapshot SnapshotFunc, duration time.Duration) (*types.DeletionProgress, error) {
+	interval := duration / (progressSamples - 1)
+
+	var samples []types.ProgressSample
+	for i := 0; i < progressSamples; i++ {
+		if i > 0 {
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(interval):
+			}
+		}
+
+		sample, err := snapshot(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to sample deletion progress: %w", err)
+		}
+		samples = append(samples, sample)
+		if sample.Remaining == 0 {
+			break
+		}
+	}
+
+	return summarizeProgress(samples), nil
+}
+
+// summarizeProgress computes the deletion rate and ETA between the first and last samples
+func summarizeProgress(samples []types.ProgressSample) *types.DeletionProgress {
+	progress := &types.DeletionProgress{Samples: samples, State: types.ProgressStalled}
+	if len(samples) == 0 {
+		return progress
+	}
+
+	first, last := samples[0], samples[len(samples)-1]
+	elapsed := last.Time.Sub(first.Time)
+	removed := first.Remaining - last.Remaining
+	if removed > 0 && elapsed > 0 {
+		progress.State = types.ProgressProgressing
+		progress.RatePerMinute = float64(removed) / elapsed.Minutes()
+		progress.ETA = time.Duration(float64(last.Remaining) / float64(removed) * float64(elapsed)).Round(time.Second)
+	}
+	// The target may already be gone at the first sample
+	if last.Remaining == 0 {
+		progress.State = types.ProgressDone
+	}
+	return progress
+}
+
+// ApplyProgress attaches sampled progress to the report and explains it in the recommendations
+func ApplyProgress(report *types.DiagnosisReport, progress *types.DeletionProgress) {
+	report.Progress = progress
+	if report.IsDeletionDone() {
+		report.Recommendations = append([]string{"Deletion finished while sampling; nothing is left to remediate."}, report.Recommendations...)
+		return
+	}
+	if !report.IsProgressing() {
+		return
+	}
+	rec := fmt.Sprintf(
+		"Deletion is progressing at %.0f objects/min (ETA %s). It is slow, not stuck; wait instead of removing finalizers.",
+		progress.RatePerMinute, progress.ETA,
+	)
+	report.Recommendations = append([]string{rec}, report.Recommendations...)
+}
+
+// Snapshot counts the objects left in a terminating namespace
+func (d *NamespaceDetector) Snapshot(ctx context.Context, name string) (types.ProgressSample, error) {
+	sample := types.ProgressSample{Time: time.Now()}
+
+	if _, err := d.client.Clientset.CoreV1().Namespaces().Get(ctx, name, metav1.GetOptions{}); err != nil {
+		if apierrors.IsNotFound(err) {
+			return sample, nil
+		}
+		return sample, fmt.Errorf("failed to get namespace: %w", err)
+	}
+
+	blockers, visible, corrupt, err := d.enumerateResources(ctx, name)
+	if err != nil {
+		return sample, err
+	}
+	for groupResource, count := range visible {
+		// Events expire on their own TTL, which is not deletion progress
+		if groupResource == "events" || groupResource == "events.events.k8s.io" {
+			continue
+		}
+		sample.Remaining += count
+	}
+	sample.Remaining += len(corrupt)
+	sample.Blockers = len(blockers)
+	return sample, nil
+}
+
+// Snapshot counts the instances left for a terminating CRD
+func (d *CRDDetector) Snapshot(ctx context.Context, name string) (types.ProgressSample, error) {
+	sample := types.ProgressSample{Time: time.Now()}
+
+	crd, err := d.client.ApiExtensions.ApiextensionsV1().CustomResourceDefinitions().Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return sample, nil
+		}
+		return sample, fmt.Errorf("failed to get CRD: %w", err)
+	}
+
+	count, blockers, _, _, err := d.listInstances(ctx, crd)
+	if err != nil {
+		return sample, err
+	}
+	sample.Remaining = count
+	sample.Blockers = len(blockers)
+	return sample, nil
+}
+
+// Snapshot reports whether a terminating resource still exists
+func (d *ResourceDetector) Snapshot(ctx context.Context, resourceType, name, namespace string) (types.ProgressSample, error) {
+	sample := types.ProgressSample{Time: time.Now()}
+
+	gvr, _, err := d.resolveResourceType(ctx, resourceType)
+	if err != nil {
+		return sample, err
+	}
+
+	_, err = d.client.Dynamic.Resource(gvr).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return sample, nil
+		}
+		return sample, fmt.Errorf("failed to get %s %q: %w", resourceType, name, err)
+	}
+	sample.Remaining = 1
+	sample.Blockers = 1
+	return sample, nil
+}
@@ -0,0 +1,98 @@
+package detector
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sozercan/unstuck/pkg/types"
+)
+
+func TestSummarizeProgress(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		samples []types.ProgressSample
+		state   types.ProgressState
+		rate    float64
+		eta     time.Duration
+	}{
+		{
+			name: "progressing",
+			samples: []types.ProgressSample{
+				{Time: start, Remaining: 3000},
+				{Time: start.Add(30 * time.Second), Remaining: 2500},
+				{Time: start.Add(time.Minute), Remaining: 2000},
+			},
+			state: types.ProgressProgressing,
+			rate:  1000,
+			eta:   2 * time.Minute,
+		},
+		{
+			name: "stalled",
+			samples: []types.ProgressSample{
+				{Time: start, Remaining: 12},
+				{Time: start.Add(time.Minute), Remaining: 12},
+			},
+			state: types.ProgressStalled,
+		},
+		{
+			name:    "single sample",
+			samples: []types.ProgressSample{{Time: start, Remaining: 5}},
+			state:   types.ProgressStalled,
+		},
+		{
+			name:    "already gone",
+			samples: []types.ProgressSample{{Time: start, Remaining: 0}},
+			state:   types.ProgressDone,
+		},
+		{
+			name: "finished while sampling",
+			samples: []types.ProgressSample{
+				{Time: start, Remaining: 10},
+				{Time: start.Add(time.Minute), Remaining: 0},
+			},
+			state: types.ProgressDone,
+			rate:  10,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			progress := summarizeProgress(tt.samples)
+			assert.Equal(t, tt.state, progress.State)
+			assert.InDelta(t, tt.rate, progress.RatePerMinute, 0.001)
+			assert.Equal(t, tt.eta, progress.ETA)
+		})
+	}
+}
+
+func TestSampleProgress_StopsWhenNothingRemains(t *testing.T) {
+	remaining := []int{10, 0, 0}
+	calls := 0
+	snapshot := func(ctx context.Context) (types.ProgressSample, error) {
+		sample := types.ProgressSample{Time: time.Now(), Remaining: remaining[calls]}
+		calls++
+		return sample, nil
+	}
+
+	progress, err := SampleProgress(context.Background(), snapshot, 20*time.Millisecond)
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, calls)
+	assert.Equal(t, types.ProgressDone, progress.State)
+}
+
+func TestApplyProgress(t *testing.T) {
+	report := &types.DiagnosisReport{Recommendations: []string{"existing"}}
+
+	ApplyProgress(report, &types.DeletionProgress{State: types.ProgressProgressing, RatePerMinute: 120, ETA: time.Minute})
+
+	require.Len(t, report.Recommendations, 2)
+	assert.Contains(t, report.Recommendations[0], "120 objects/min (ETA 1m0s)")
+	assert.True(t, report.IsProgressing())
+}
@@ -0,0 +1,122 @@
+package detector
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/sozercan/unstuck/pkg/types"
+)
+
+// finalizerProvenance identifies the field manager owning each finalizer entry.
+// Finalizers are a set-type list, so managedFields records them as
+// {"f:metadata":{"f:finalizers":{"v:\"<finalizer>\"":{}}}}. When several managers
+// own the same entry, the most recent one wins. managedFields only records each manager's
+// last write to the object, so the time is not when the finalizer was added.
+func finalizerProvenance(managedFields []metav1.ManagedFieldsEntry) []types.FinalizerProvenance {
+	byFinalizer := make(map[string]types.FinalizerProvenance)
+
+	for _, entry := range managedFields {
+		if entry.FieldsV1 == nil {
+			continue
+		}
+		for _, finalizer := range managedFinalizers(entry.FieldsV1.Raw) {
+			existing, ok := byFinalizer[finalizer]
+			if ok && !managedAfter(entry.Time, existing.Time) {
+				continue
+			}
+			byFinalizer[finalizer] = types.FinalizerProvenance{
+				Finalizer: finalizer,
+				Manager:   entry.Manager,
+				Operation: string(entry.Operation),
+				Time:      entry.Time,
+			}
+		}
+	}
+
+	result := make([]types.FinalizerProvenance, 0, len(byFinalizer))
+	for _, p := range byFinalizer {
+		result = append(result, p)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Finalizer < result[j].Finalizer
+	})
+	return result
+}
+
+// managedFinalizers extracts finalizer values from a FieldsV1 document
+func managedFinalizers(raw []byte) []string {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return nil
+	}
+
+	var metadata map[string]json.RawMessage
+	if err := json.Unmarshal(fields["f:metadata"], &metadata); err != nil {
+		return nil
+	}
+
+	var finalizers map[string]json.RawMessage
+	if err := json.Unmarshal(metadata["f:finalizers"], &finalizers); err != nil {
+		return nil
+	}
+
+	var result []string
+	for key := range finalizers {
+		if !strings.HasPrefix(key, "v:") {
+			continue
+		}
+		var value string
+		if err := json.Unmarshal([]byte(strings.TrimPrefix(key, "v:")), &value); err != nil {
+			continue
+		}
+		result = append(result, value)
+	}
+	return result
+}
+
+func managedAfter(a, b *metav1.Time) bool {
+	if a == nil {
+		return false
+	}
+	if b == nil {
+		return true
+	}
+	return a.After(b.Time)
+}
+
+// provenanceRecommendations explains which tool manages each finalizer so it can be checked first
+func provenanceRecommendations(blockers []types.Blocker) []string {
+	type key struct{ finalizer, manager string }
+	counts := make(map[key]int)
+	latest := make(map[key]*metav1.Time)
+	var order []key
+
+	for _, b := range blockers {
+		for _, p := range b.FinalizerProvenance {
+			k := key{p.Finalizer, p.Manager}
+			if _, ok := counts[k]; !ok {
+				order = append(order, k)
+			}
+			counts[k]++
+			if managedAfter(p.Time, latest[k]) {
+				latest[k] = p.Time
+			}
+		}
+	}
+
+	var recs []string
+	for _, k := range order {
+		rec := fmt.Sprintf("Finalizer %s on %d object(s) is managed by `%s`", k.finalizer, counts[k], k.manager)
+		if t := latest[k]; t != nil {
+			rec += " (last write " + t.UTC().Format(time.RFC3339) + ")"
+		}
+		rec += "; check that tool before removing it."
+		recs = append(recs, rec)
+	}
+	return recs
+}
@@ -0,0 +1,95 @@
+package detector
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/sozercan/unstuck/pkg/types"
+)
+
+func TestFinalizerProvenance(t *testing.T) {
+	older := metav1.NewTime(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	newer := metav1.NewTime(time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC))
+
+	managedFields := []metav1.ManagedFieldsEntry{
+		{
+			Manager:   "helm",
+			Operation: metav1.ManagedFieldsOperationUpdate,
+			Time:      &older,
+			FieldsV1:  &metav1.FieldsV1{Raw: []byte(`{"f:metadata":{"f:finalizers":{".":{},"v:\"example.com/cleanup\"":{}}},"f:spec":{}}`)},
+		},
+		{
+			Manager:   "argocd-controller",
+			Operation: metav1.ManagedFieldsOperationApply,
+			Time:      &newer,
+			FieldsV1:  &metav1.FieldsV1{Raw: []byte(`{"f:metadata":{"f:finalizers":{"v:\"resources-finalizer.argocd.argoproj.io\"":{}}}}`)},
+		},
+		{
+			Manager:  "kubectl",
+			Time:     &newer,
+			FieldsV1: &metav1.FieldsV1{Raw: []byte(`{"f:spec":{"f:replicas":{}}}`)},
+		},
+		{
+			Manager:  "broken",
+			FieldsV1: &metav1.FieldsV1{Raw: []byte(`not json`)},
+		},
+	}
+
+	result := finalizerProvenance(managedFields)
+
+	require.Len(t, result, 2)
+	assert.Equal(t, "example.com/cleanup", result[0].Finalizer)
+	assert.Equal(t, "helm", result[0].Manager)
+	assert.Equal(t, "Update", result[0].Operation)
+	assert.Equal(t, "resources-finalizer.argocd.argoproj.io", result[1].Finalizer)
+	assert.Equal(t, "argocd-controller", result[1].Manager)
+}
+
+func TestFinalizerProvenance_MostRecentManagerWins(t *testing.T) {
+	older := metav1.NewTime(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	newer := metav1.NewTime(time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC))
+	raw := []byte(`{"f:metadata":{"f:finalizers":{"v:\"example.com/cleanup\"":{}}}}`)
+
+	result := finalizerProvenance([]metav1.ManagedFieldsEntry{
+		{Manager: "new-operator", Time: &newer, FieldsV1: &metav1.FieldsV1{Raw: raw}},
+		{Manager: "old-operator", Time: &older, FieldsV1: &metav1.FieldsV1{Raw: raw}},
+	})
+
+	require.Len(t, result, 1)
+	assert.Equal(t, "new-operator", result[0].Manager)
+}
+
+func TestProvenanceRecommendations(t *testing.T) {
+	at := metav1.NewTime(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	blockers := []types.Blocker{
+		{FinalizerProvenance: []types.FinalizerProvenance{{Finalizer: "example.com/cleanup", Manager: "helm", Time: &at}}},
+		{FinalizerProvenance: []types.FinalizerProvenance{{Finalizer: "example.com/cleanup", Manager: "helm", Time: &at}}},
+	}
+
+	recs := provenanceRecommendations(blockers)
+
+	require.Len(t, recs, 1)
+	assert.Equal(t, "Finalizer example.com/cleanup on 2 object(s) is managed by `helm` (last write 2026-01-01T00:00:00Z); check that tool before removing it.", recs[0])
+}
+
+func TestNamespaceFinalizerRecommendations(t *testing.T) {
+	report := &types.DiagnosisReport{
+		Target:     types.ResourceRef{Kind: "Namespace", Name: "team-a"},
+		Finalizers: []string{"controller.cattle.io/namespace-auth", "example.com/cleanup"},
+		FinalizerProvenance: []types.FinalizerProvenance{
+			{Finalizer: "controller.cattle.io/namespace-auth", Manager: "rancher"},
+		},
+		SpecFinalizers: []string{"kubernetes"},
+	}
+
+	recs := namespaceFinalizerRecommendations(report)
+	require.Len(t, recs, 2)
+	assert.Contains(t, recs[0], "controller.cattle.io/namespace-auth managed by `rancher`")
+	assert.NotContains(t, recs[1], "managed by")
+
+	assert.Empty(t, namespaceFinalizerRecommendations(&types.DiagnosisReport{SpecFinalizers: []string{"kubernetes"}}))
+}
@@ -15,12 +15,18 @@ import (
 
 // ResourceDetector detects issues with stuck generic resources
 type ResourceDetector struct {
-	client *kube.Client
+	client     *kube.Client
+	stuckAfter time.Duration
 }
 
 // NewResourceDetector creates a new resource detector
 func NewResourceDetector(client *kube.Client) *ResourceDetector {
-	return &ResourceDetector{client: client}
+	return &ResourceDetector{client: client, stuckAfter: DefaultStuckAfter}
+}
+
+// SetStuckAfter sets how long a blocker may be terminating before it is classified Stuck
+func (d *ResourceDetector) SetStuckAfter(stuckAfter time.Duration) {
+	d.stuckAfter = stuckAfter
 }
 
 // Detect analyzes a specific resource and returns a diagnosis report
@@ -77,13 +83,6 @@ func (d *ResourceDetector) Detect(ctx context.Context, resourceType, name, names
 	report.DeletionTimestamp = &t
 	report.TerminatingFor = formatDuration(time.Since(t))
 
-	// Analyze root cause
-	if len(obj.GetFinalizers()) > 0 {
-		report.RootCause = fmt.Sprintf("Resource has finalizers: %v", obj.GetFinalizers())
-	} else {
-		report.RootCause = "Resource is terminating but has no finalizers (may be waiting for dependents)"
-	}
-
 	// Add self as a blocker for consistency
 	report.Blockers = []types.Blocker{
 		{
@@ -93,13 +92,28 @@ func (d *ResourceDetector) Detect(ctx context.Context, resourceType, name, names
 				Namespace:  namespace,
 				Name:       name,
 			},
-			Finalizers:        obj.GetFinalizers(),
-			DeletionTimestamp: obj.GetDeletionTimestamp(),
-			OwnerReferences:   obj.GetOwnerReferences(),
-			Age:               time.Since(t),
+			UID:                 string(obj.GetUID()),
+			Finalizers:          obj.GetFinalizers(),
+			DeletionTimestamp:   obj.GetDeletionTimestamp(),
+			OwnerReferences:     obj.GetOwnerReferences(),
+			FinalizerProvenance: finalizerProvenance(obj.GetManagedFields()),
+			HelmRelease:         helmReleaseOf(obj),
+			Age:                 time.Since(t),
 		},
 	}
 
+	// Find the controller responsible for the finalizers and whether it is reconciling
+	detectControllers(ctx, d.client, report, report.Blockers)
+
+	analyzeBlockers(ctx, d.client, report, d.stuckAfter)
+	if report.RootCause == "" {
+		if len(obj.GetFinalizers()) > 0 {
+			report.RootCause = fmt.Sprintf("Resource has finalizers: %v", obj.GetFinalizers())
+		} else {
+			report.RootCause = "Resource is terminating but has no finalizers (may be waiting for dependents)"
+		}
+	}
+
 	// Build recommendations
 	report.Recommendations = d.buildRecommendations(report, kind, name, namespace)
 
@@ -165,6 +179,8 @@ func (d *ResourceDetector) buildRecommendations(report *types.DiagnosisReport, k
 		recs = append(recs, "Resource has no finalizers but is still terminating. Check for dependent resources or controller issues.")
 	}
 
+	recs = append(recs, blockerRecommendations(report, d.stuckAfter)...)
+
 	return recs
 }
 
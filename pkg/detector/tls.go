@@ -0,0 +1,192 @@
+package detector
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	admissionv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/sozercan/unstuck/pkg/types"
+)
+
+const (
+	// certManagerInjectFrom names the Certificate whose CA cert-manager injects into the caBundle
+	certManagerInjectFrom = "cert-manager.io/inject-ca-from"
+	// certManagerInjectFromSecret names the secret whose CA cert-manager injects into the caBundle
+	certManagerInjectFromSecret = "cert-manager.io/inject-ca-from-secret"
+)
+
+var certManagerCertificateGVR = schema.GroupVersionResource{Group: "cert-manager.io", Version: "v1", Resource: "certificates"}
+
+// servingSecretSuffixes are appended to the service name to guess the serving certificate secret
+// when cert-manager does not manage it
+var servingSecretSuffixes = []string{"-tls", "-cert", "-certs", "-server-cert", "-webhook-server-cert"}
+
+// checkTLS decodes the webhook's caBundle and, for service-backed webhooks whose serving secret
+// can be found, verifies that the serving certificate chains to the bundle and covers the
+// service DNS name. Problems are recorded in TLSIssues and make the webhook unhealthy.
+func (d *WebhookDetector) checkTLS(ctx context.Context, info *types.WebhookInfo, config metav1.ObjectMeta, clientConfig admissionv1.WebhookClientConfig) {
+	now := time.Now()
+
+	roots, err := parseCertificates(clientConfig.CABundle)
+	switch {
+	case err != nil:
+		info.TLSIssues = append(info.TLSIssues, fmt.Sprintf("caBundle cannot be parsed: %v", err))
+	case len(roots) == 0:
+		// Without a bundle the API server uses the system roots, which cannot be checked here
+	default:
+		info.CABundle = certificateInfo(roots[0], "")
+		for _, c := range roots {
+			if now.After(c.NotAfter) {
+				info.TLSIssues = append(info.TLSIssues, fmt.Sprintf("caBundle certificate %q expired at %s", c.Subject.String(), c.NotAfter.UTC().Format(time.RFC3339)))
+			}
+		}
+	}
+
+	if clientConfig.Service != nil {
+		if chain, secret := d.servingCertificate(ctx, config, clientConfig.Service); len(chain) > 0 {
+			info.ServingCert = certificateInfo(chain[0], secret)
+			if len(roots) > 0 {
+				info.TLSIssues = append(info.TLSIssues, verifyServingCertificate(chain, roots, clientConfig.Service, now)...)
+			}
+		}
+	}
+
+	if len(info.TLSIssues) > 0 {
+		info.Healthy = false
+		if info.Error == "" {
+			info.Error = info.TLSIssues[0]
+			info.ErrorType = "webhook_tls"
+		}
+	}
+}
+
+// verifyServingCertificate checks the serving chain against the caBundle and the service DNS name
+func verifyServingCertificate(chain, roots []*x509.Certificate, svc *admissionv1.ServiceReference, now time.Time) []string {
+	var issues []string
+	leaf := chain[0]
+	dnsName := fmt.Sprintf("%s.%s.svc", svc.Name, svc.Namespace)
+
+	if now.After(leaf.NotAfter) {
+		issues = append(issues, fmt.Sprintf("serving certificate expired at %s", leaf.NotAfter.UTC().Format(time.RFC3339)))
+	}
+	if err := leaf.VerifyHostname(dnsName); err != nil {
+		issues = append(issues, fmt.Sprintf("serving certificate does not cover %s", dnsName))
+	}
+
+	rootPool := x509.NewCertPool()
+	for _, c := range roots {
+		rootPool.AddCert(c)
+	}
+	intermediates := x509.NewCertPool()
+	for _, c := range chain[1:] {
+		intermediates.AddCert(c)
+	}
+	// Expiry is reported above, so verify the chain at a time the leaf was valid
+	_, err := leaf.Verify(x509.VerifyOptions{
+		Roots:         rootPool,
+		Intermediates: intermediates,
+		CurrentTime:   leaf.NotBefore.Add(time.Second),
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	})
+	if err != nil {
+		var unknown x509.UnknownAuthorityError
+		if errors.As(err, &unknown) {
+			issues = append(issues, "serving certificate is not signed by the caBundle")
+		} else {
+			issues = append(issues, fmt.Sprintf("serving certificate does not chain to the caBundle: %v", err))
+		}
+	}
+
+	return issues
+}
+
+// servingCertificate finds the secret with the service's serving certificate, preferring the
+// one cert-manager is told to inject from, and returns its chain and namespace/name
+func (d *WebhookDetector) servingCertificate(ctx context.Context, config metav1.ObjectMeta, svc *admissionv1.ServiceReference) ([]*x509.Certificate, string) {
+	var candidates []types.ResourceRef
+	if ref, ok := config.Annotations[certManagerInjectFromSecret]; ok {
+		if ns, name, ok := strings.Cut(ref, "/"); ok {
+			candidates = append(candidates, types.ResourceRef{Namespace: ns, Name: name})
+		}
+	}
+	if ref, ok := config.Annotations[certManagerInjectFrom]; ok {
+		if secret := d.certificateSecret(ctx, ref); secret != "" {
+			ns, _, _ := strings.Cut(ref, "/")
+			candidates = append(candidates, types.ResourceRef{Namespace: ns, Name: secret})
+		}
+	}
+	for _, suffix := range servingSecretSuffixes {
+		candidates = append(candidates, types.ResourceRef{Namespace: svc.Namespace, Name: svc.Name + suffix})
+	}
+
+	for _, c := range candidates {
+		secret, err := d.client.Clientset.CoreV1().Secrets(c.Namespace).Get(ctx, c.Name, metav1.GetOptions{})
+		if err != nil {
+			continue
+		}
+		chain, err := parseCertificates(secret.Data[corev1.TLSCertKey])
+		if err != nil || len(chain) == 0 {
+			continue
+		}
+		return chain, c.Namespace + "/" + c.Name
+	}
+	return nil, ""
+}
+
+// certificateSecret returns spec.secretName of a cert-manager Certificate given as namespace/name
+func (d *WebhookDetector) certificateSecret(ctx context.Context, ref string) string {
+	ns, name, ok := strings.Cut(ref, "/")
+	if !ok || d.client.Dynamic == nil {
+		return ""
+	}
+	cert, err := d.client.Dynamic.Resource(certManagerCertificateGVR).Namespace(ns).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return ""
+	}
+	secretName, _, _ := unstructured.NestedString(cert.Object, "spec", "secretName")
+	return secretName
+}
+
+// parseCertificates decodes every CERTIFICATE block of a PEM bundle
+func parseCertificates(data []byte) ([]*x509.Certificate, error) {
+	var certs []*x509.Certificate
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		certs = append(certs, cert)
+	}
+	if len(certs) == 0 && len(strings.TrimSpace(string(data))) > 0 {
+		return nil, errors.New("no PEM certificates found")
+	}
+	return certs, nil
+}
+
+func certificateInfo(c *x509.Certificate, secret string) *types.CertificateInfo {
+	return &types.CertificateInfo{
+		Subject:  c.Subject.String(),
+		Issuer:   c.Issuer.String(),
+		NotAfter: c.NotAfter,
+		DNSNames: c.DNSNames,
+		Secret:   secret,
+	}
+}
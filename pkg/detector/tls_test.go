@@ -0,0 +1,161 @@
+package detector
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	admissionv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/sozercan/unstuck/pkg/kube"
+	"github.com/sozercan/unstuck/pkg/types"
+)
+
+type testCert struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+// newTestCert creates a certificate valid from notBefore to notAfter, self-signed if parent is nil
+func newTestCert(t *testing.T, cn string, parent *testCert, notBefore, notAfter time.Time, dnsNames ...string) *testCert {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: cn},
+		NotBefore:             notBefore,
+		NotAfter:              notAfter,
+		DNSNames:              dnsNames,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  parent == nil,
+	}
+	signer, signerKey := template, key
+	if parent != nil {
+		signer, signerKey = parent.cert, parent.key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return &testCert{cert: cert, key: key, pem: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+func tlsSecret(namespace, name string, cert *testCert) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+		Type:       corev1.SecretTypeTLS,
+		Data:       map[string][]byte{corev1.TLSCertKey: cert.pem},
+	}
+}
+
+func TestWebhookDetector_CheckTLS(t *testing.T) {
+	now := time.Now()
+	from, to := now.Add(-time.Hour), now.Add(24*time.Hour)
+	svc := &admissionv1.ServiceReference{Namespace: "system", Name: "webhook"}
+
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		setup       func(t *testing.T) ([]byte, []*corev1.Secret)
+		wantIssues  []string
+		wantSecret  string
+	}{
+		{
+			name: "serving cert chains to bundle",
+			setup: func(t *testing.T) ([]byte, []*corev1.Secret) {
+				ca := newTestCert(t, "webhook-ca", nil, from, to)
+				leaf := newTestCert(t, "webhook", ca, from, to, "webhook.system.svc")
+				return ca.pem, []*corev1.Secret{tlsSecret("system", "webhook-tls", leaf)}
+			},
+			wantSecret: "system/webhook-tls",
+		},
+		{
+			name: "expired caBundle",
+			setup: func(t *testing.T) ([]byte, []*corev1.Secret) {
+				ca := newTestCert(t, "old-ca", nil, now.Add(-48*time.Hour), now.Add(-time.Hour))
+				return ca.pem, nil
+			},
+			wantIssues: []string{`caBundle certificate "CN=old-ca" expired at ` + now.Add(-time.Hour).UTC().Format(time.RFC3339)},
+		},
+		{
+			name: "serving cert signed by another CA",
+			setup: func(t *testing.T) ([]byte, []*corev1.Secret) {
+				ca := newTestCert(t, "webhook-ca", nil, from, to)
+				rotated := newTestCert(t, "rotated-ca", nil, from, to)
+				leaf := newTestCert(t, "webhook", rotated, from, to, "webhook.system.svc")
+				return ca.pem, []*corev1.Secret{tlsSecret("system", "webhook-cert", leaf)}
+			},
+			wantIssues: []string{"serving certificate is not signed by the caBundle"},
+			wantSecret: "system/webhook-cert",
+		},
+		{
+			name: "serving cert for wrong DNS name",
+			setup: func(t *testing.T) ([]byte, []*corev1.Secret) {
+				ca := newTestCert(t, "webhook-ca", nil, from, to)
+				leaf := newTestCert(t, "webhook", ca, from, to, "other.system.svc")
+				return ca.pem, []*corev1.Secret{tlsSecret("system", "webhook-tls", leaf)}
+			},
+			wantIssues: []string{"serving certificate does not cover webhook.system.svc"},
+			wantSecret: "system/webhook-tls",
+		},
+		{
+			name:        "cert-manager secret annotation",
+			annotations: map[string]string{certManagerInjectFromSecret: "certs/webhook-serving"},
+			setup: func(t *testing.T) ([]byte, []*corev1.Secret) {
+				ca := newTestCert(t, "webhook-ca", nil, from, to)
+				leaf := newTestCert(t, "webhook", ca, from, now.Add(-time.Minute), "webhook.system.svc")
+				return ca.pem, []*corev1.Secret{tlsSecret("certs", "webhook-serving", leaf)}
+			},
+			wantIssues: []string{"serving certificate expired at " + now.Add(-time.Minute).UTC().Format(time.RFC3339)},
+			wantSecret: "certs/webhook-serving",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bundle, secrets := tt.setup(t)
+			clientset := fake.NewSimpleClientset()
+			for _, s := range secrets {
+				_, err := clientset.CoreV1().Secrets(s.Namespace).Create(context.Background(), s, metav1.CreateOptions{})
+				require.NoError(t, err)
+			}
+			d := NewWebhookDetector(&kube.Client{Clientset: clientset})
+
+			info := types.WebhookInfo{Healthy: true}
+			config := metav1.ObjectMeta{Name: "webhook", Annotations: tt.annotations}
+			d.checkTLS(context.Background(), &info, config, admissionv1.WebhookClientConfig{Service: svc, CABundle: bundle})
+
+			assert.Equal(t, tt.wantIssues, info.TLSIssues)
+			assert.Equal(t, len(tt.wantIssues) == 0, info.Healthy)
+			require.NotNil(t, info.CABundle)
+			if tt.wantSecret == "" {
+				assert.Nil(t, info.ServingCert)
+			} else {
+				require.NotNil(t, info.ServingCert)
+				assert.Equal(t, tt.wantSecret, info.ServingCert.Secret)
+			}
+			if len(tt.wantIssues) > 0 {
+				assert.Equal(t, "webhook_tls", info.ErrorType)
+			}
+		})
+	}
+}
@@ -7,7 +7,6 @@ import (
 	"strings"
 
 	admissionv1 "k8s.io/api/admissionregistration/v1"
-	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 
@@ -37,6 +36,7 @@ var WebhookErrorPatterns = []struct {
 	{regexp.MustCompile(`no endpoints available`), "webhook_no_endpoints"},
 	{regexp.MustCompile(`service .* not found`), "webhook_service_missing"},
 	{regexp.MustCompile(`Internal error occurred: failed calling webhook`), "webhook_internal_error"},
+	{regexp.MustCompile(`ValidatingAdmissionPolicy '([^']+)' with binding '([^']+)' denied request`), "policy_denied"},
 }
 
 // WebhookReport contains the results of webhook detection
@@ -157,14 +157,16 @@ func ExtractWebhookNameFromError(err error) string {
 	}
 	msg := err.Error()
 
-	pattern := regexp.MustCompile(`admission webhook "([^"]+)"`)
-	matches := pattern.FindStringSubmatch(msg)
+	matches := webhookNamePattern.FindStringSubmatch(msg)
 	if len(matches) > 1 {
 		return matches[1]
 	}
 	return ""
 }
 
+// webhookNamePattern matches both denials and failed calls
+var webhookNamePattern = regexp.MustCompile(`(?:admission|calling) webhook "([^"]+)"`)
+
 func matchesGVR(rules []admissionv1.RuleWithOperations, gvr schema.GroupVersionResource) bool {
 	for _, rule := range rules {
 		if matchesRule(rule.Rule, gvr) {
@@ -223,7 +225,7 @@ func (d *WebhookDetector) DetectWithHealthCheck(ctx context.Context, gvr schema.
 	for _, vwc := range vwcs.Items {
 		for _, webhook := range vwc.Webhooks {
 			if matchesGVR(webhook.Rules, gvr) {
-				info := d.buildWebhookInfoWithHealth(ctx, vwc.Name, webhook.Name, "validating", webhook.ClientConfig, webhook.FailurePolicy)
+				info := d.buildWebhookInfoWithHealth(ctx, vwc.ObjectMeta, webhook.Name, "validating", webhook.ClientConfig, webhook.FailurePolicy)
 				report.ValidatingWebhooks = append(report.ValidatingWebhooks, info)
 			}
 		}
@@ -238,7 +240,7 @@ func (d *WebhookDetector) DetectWithHealthCheck(ctx context.Context, gvr schema.
 	for _, mwc := range mwcs.Items {
 		for _, webhook := range mwc.Webhooks {
 			if matchesGVR(webhook.Rules, gvr) {
-				info := d.buildWebhookInfoWithHealth(ctx, mwc.Name, webhook.Name, "mutating", webhook.ClientConfig, webhook.FailurePolicy)
+				info := d.buildWebhookInfoWithHealth(ctx, mwc.ObjectMeta, webhook.Name, "mutating", webhook.ClientConfig, webhook.FailurePolicy)
 				report.MutatingWebhooks = append(report.MutatingWebhooks, info)
 			}
 		}
@@ -247,10 +249,10 @@ func (d *WebhookDetector) DetectWithHealthCheck(ctx context.Context, gvr schema.
 	return report, nil
 }
 
-// buildWebhookInfoWithHealth creates a WebhookInfo with health check
-func (d *WebhookDetector) buildWebhookInfoWithHealth(ctx context.Context, configName, webhookName, webhookType string, clientConfig admissionv1.WebhookClientConfig, failurePolicy *admissionv1.FailurePolicyType) types.WebhookInfo {
+// buildWebhookInfoWithHealth creates a WebhookInfo with service and TLS health checks
+func (d *WebhookDetector) buildWebhookInfoWithHealth(ctx context.Context, config metav1.ObjectMeta, webhookName, webhookType string, clientConfig admissionv1.WebhookClientConfig, failurePolicy *admissionv1.FailurePolicyType) types.WebhookInfo {
 	info := types.WebhookInfo{
-		Name:        configName,
+		Name:        config.Name,
 		WebhookName: webhookName,
 		Type:        webhookType,
 		Healthy:     true, // Assume healthy until proven otherwise
@@ -265,52 +267,59 @@ func (d *WebhookDetector) buildWebhookInfoWithHealth(ctx context.Context, config
 		svcRef := fmt.Sprintf("%s/%s", clientConfig.Service.Namespace, clientConfig.Service.Name)
 		info.ServiceRef = svcRef
 
-		healthy, errMsg := d.checkServiceHealth(ctx, clientConfig.Service.Namespace, clientConfig.Service.Name)
+		healthy, errMsg, endpoints := d.checkServiceHealth(ctx, clientConfig.Service)
 		info.Healthy = healthy
+		info.Endpoints = endpoints
 		if errMsg != "" {
 			info.Error = errMsg
 		}
 	}
 
+	// Expired or mismatched certificates make every call fail even with ready endpoints
+	d.checkTLS(ctx, &info, config, clientConfig)
+
 	return info
 }
 
-// checkServiceHealth verifies that a webhook service has healthy endpoints
-func (d *WebhookDetector) checkServiceHealth(ctx context.Context, namespace, name string) (bool, string) {
+// checkServiceHealth verifies that a webhook service has ready endpoints on the webhook's port.
+// EndpointSlices are preferred; clusters that do not serve them fall back to core/v1 Endpoints.
+func (d *WebhookDetector) checkServiceHealth(ctx context.Context, ref *admissionv1.ServiceReference) (bool, string, []types.EndpointHealth) {
+	namespace, name := ref.Namespace, ref.Name
+
 	// Check if service exists
-	_, err := d.client.Clientset.CoreV1().Services(namespace).Get(ctx, name, metav1.GetOptions{})
+	svc, err := d.client.Clientset.CoreV1().Services(namespace).Get(ctx, name, metav1.GetOptions{})
 	if err != nil {
 		if strings.Contains(err.Error(), "not found") {
-			return false, fmt.Sprintf("service %s/%s not found", namespace, name)
+			return false, fmt.Sprintf("service %s/%s not found", namespace, name), nil
 		}
-		return false, fmt.Sprintf("failed to get service: %v", err)
+		return false, fmt.Sprintf("failed to get service: %v", err), nil
 	}
 
-	// Check if endpoints exist
-	endpoints, err := d.client.Clientset.CoreV1().Endpoints(namespace).Get(ctx, name, metav1.GetOptions{})
-	if err != nil {
-		if strings.Contains(err.Error(), "not found") {
-			return false, fmt.Sprintf("endpoints %s/%s not found", namespace, name)
-		}
-		return false, fmt.Sprintf("failed to get endpoints: %v", err)
+	port, ok := webhookServicePort(svc, ref)
+	if !ok {
+		return false, fmt.Sprintf("service %s/%s has no port %d", namespace, name, port.Port), nil
 	}
 
-	// Check if there are ready addresses
-	readyCount := countReadyAddresses(endpoints)
-	if readyCount == 0 {
-		return false, "no ready endpoints available"
+	var health []types.EndpointHealth
+	if slices, err := d.listEndpointSlices(ctx, namespace, name); err == nil && len(slices) > 0 {
+		health = endpointSliceHealth(slices, port)
+	} else {
+		// Check if endpoints exist
+		endpoints, err := d.client.Clientset.CoreV1().Endpoints(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			if strings.Contains(err.Error(), "not found") {
+				return false, fmt.Sprintf("endpoints %s/%s not found", namespace, name), nil
+			}
+			return false, fmt.Sprintf("failed to get endpoints: %v", err), nil
+		}
+		health = endpointsHealth(endpoints, port)
 	}
 
-	return true, ""
-}
-
-// countReadyAddresses counts ready addresses in endpoints
-func countReadyAddresses(endpoints *corev1.Endpoints) int {
-	count := 0
-	for _, subset := range endpoints.Subsets {
-		count += len(subset.Addresses)
+	// Check if there are ready addresses
+	if msg := unhealthyEndpointsMessage(svc, port, health); msg != "" {
+		return false, msg, health
 	}
-	return count
+	return true, "", health
 }
 
 // GenerateWebhookGuidance generates user-friendly guidance for resolving webhook issues
@@ -372,6 +381,10 @@ func WebhookErrorTypeDescription(errorType string) string {
 		return "The webhook service does not exist"
 	case "webhook_internal_error":
 		return "An internal error occurred calling the webhook"
+	case "webhook_tls":
+		return "The webhook's TLS certificates are invalid"
+	case "policy_denied":
+		return "A ValidatingAdmissionPolicy denied the request"
 	default:
 		return "Unknown webhook error"
 	}
@@ -525,51 +525,6 @@ func TestDetectWithHealthCheck_NoEndpoints(t *testing.T) {
 	assert.Equal(t, "no ready endpoints available", report.ValidatingWebhooks[0].Error)
 }
 
-func TestCountReadyAddresses(t *testing.T) {
-	tests := []struct {
-		name      string
-		endpoints *corev1.Endpoints
-		expected  int
-	}{
-		{
-			name:      "nil subsets",
-			endpoints: &corev1.Endpoints{},
-			expected:  0,
-		},
-		{
-			name: "one subset with addresses",
-			endpoints: &corev1.Endpoints{
-				Subsets: []corev1.EndpointSubset{
-					{
-						Addresses: []corev1.EndpointAddress{
-							{IP: "10.0.0.1"},
-							{IP: "10.0.0.2"},
-						},
-					},
-				},
-			},
-			expected: 2,
-		},
-		{
-			name: "multiple subsets",
-			endpoints: &corev1.Endpoints{
-				Subsets: []corev1.EndpointSubset{
-					{Addresses: []corev1.EndpointAddress{{IP: "10.0.0.1"}}},
-					{Addresses: []corev1.EndpointAddress{{IP: "10.0.0.2"}, {IP: "10.0.0.3"}}},
-				},
-			},
-			expected: 3,
-		},
-	}
-
-	for _, tc := range tests {
-		t.Run(tc.name, func(t *testing.T) {
-			count := countReadyAddresses(tc.endpoints)
-			assert.Equal(t, tc.expected, count)
-		})
-	}
-}
-
 func TestGenerateWebhookGuidance(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -149,3 +149,48 @@ func TestTruncate(t *testing.T) {
 		})
 	}
 }
+
+func TestPrinter_PrintPlan_Impact(t *testing.T) {
+	report := &types.DiagnosisReport{
+		Target:     types.ResourceRef{Kind: "Namespace", APIVersion: "v1", Name: "apps"},
+		TargetType: types.TargetTypeNamespace,
+		Status:     "Terminating",
+	}
+	plan := &types.Plan{
+		Target:    report.Target,
+		RiskLevel: types.RiskMedium,
+		Actions: []types.Action{
+			{ID: "action-001", Type: types.ActionInspect, Description: "Inspect namespace \"apps\"", Risk: types.RiskNone},
+			{ID: "action-002", Type: types.ActionPatch, Description: "Remove finalizers from Secret/apps/creds", Risk: types.RiskMedium},
+		},
+		Impact: []types.ActionImpact{{
+			ActionID: "action-002",
+			Items: []types.ImpactItem{{
+				Type:   types.ImpactDataDeleted,
+				Object: types.ResourceRef{Kind: "Secret", Namespace: "apps", Name: "creds"},
+				Detail: "Secret deleted",
+			}},
+		}},
+	}
+
+	var buf bytes.Buffer
+	printer := NewPrinter("text", false)
+	printer.SetOutput(&buf)
+	require.NoError(t, printer.PrintPlan(report, plan))
+	assert.Contains(t, buf.String(), "IMPACT")
+	assert.Contains(t, buf.String(), "Step 2: Remove finalizers from Secret/apps/creds")
+	assert.Contains(t, buf.String(), "[data-deleted] Secret/apps/creds: Secret deleted")
+
+	buf.Reset()
+	printer = NewPrinter("json", false)
+	printer.SetOutput(&buf)
+	require.NoError(t, printer.PrintPlan(report, plan))
+
+	var result struct {
+		Plan types.Plan `json:"plan"`
+	}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &result))
+	require.Len(t, result.Plan.Impact, 1)
+	assert.Equal(t, "action-002", result.Plan.Impact[0].ActionID)
+	assert.Equal(t, types.ImpactDataDeleted, result.Plan.Impact[0].Items[0].Type)
+}
@@ -6,6 +6,7 @@ import (
 	"io"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/fatih/color"
 	"github.com/rodaine/table"
@@ -96,23 +97,45 @@ func (p *Printer) printText(report *types.DiagnosisReport) error {
 	}
 
 	if len(report.Finalizers) > 0 {
-		fmt.Fprintf(p.out, "%-16s%s\n", "Finalizers:", strings.Join(report.Finalizers, ", "))
+		fmt.Fprintf(p.out, "%-16s%s\n", "Finalizers:", strings.Join(targetFinalizers(report), ", "))
+	}
+	if len(report.SpecFinalizers) > 0 {
+		fmt.Fprintf(p.out, "%-16s%s\n", "Spec Finalizers:", strings.Join(report.SpecFinalizers, ", "))
 	}
 
 	fmt.Fprintln(p.out)
 
+	if pr := report.Progress; pr != nil && len(pr.Samples) > 0 {
+		first, last := pr.Samples[0], pr.Samples[len(pr.Samples)-1]
+		cyan.Fprintln(p.out, "PROGRESS")
+		switch {
+		case report.IsDeletionDone():
+			green.Fprintln(p.out, "• Done: nothing left to delete")
+		case report.IsProgressing():
+			green.Fprintf(p.out, "• Progressing: %.0f objects/min, ETA %s\n", pr.RatePerMinute, pr.ETA)
+		default:
+			red.Fprintln(p.out, "• Stalled: no objects removed while sampling")
+		}
+		fmt.Fprintf(p.out, "• %d samples over %s: %d → %d objects remaining\n",
+			len(pr.Samples), last.Time.Sub(first.Time).Round(time.Second), first.Remaining, last.Remaining)
+		fmt.Fprintln(p.out)
+	}
+
 	if len(report.Blockers) > 0 {
 		bold.Fprintf(p.out, "BLOCKERS (%d found)\n", len(report.Blockers))
 
 		headerFmt := color.New(color.FgHiWhite, color.Bold).SprintfFunc()
-		tbl := table.New("#", "Resource", "Finalizer", "Status")
+		tbl := table.New("#", "Resource", "Finalizer", "Managed By", "Status", "Reason")
 		tbl.WithHeaderFormatter(headerFmt)
 		tbl.WithWriter(p.out)
 
 		for i, b := range report.Blockers {
-			status := "Stuck"
-			if !b.IsTerminating() {
-				status = "Pending"
+			status := string(b.Status)
+			if status == "" {
+				status = string(types.BlockerStatusStuck)
+				if !b.IsTerminating() {
+					status = string(types.BlockerStatusPending)
+				}
 			}
 
 			finalizer := "-"
@@ -128,12 +151,264 @@ func (p *Printer) printText(report *types.DiagnosisReport) error {
 				resourceName = b.Kind + "/" + b.Namespace + "/" + b.Name
 			}
 
-			tbl.AddRow(i+1, resourceName, finalizer, status)
+			managedBy := "-"
+			if len(b.Finalizers) > 0 {
+				if prov := b.ProvenanceFor(b.Finalizers[0]); prov != nil {
+					managedBy = prov.Manager
+					if prov.Time != nil {
+						managedBy += ", last write " + prov.Time.UTC().Format(time.RFC3339)
+					}
+				}
+			}
+
+			reason := "-"
+			if b.RootCause != "" {
+				reason = truncate(b.RootCause, 60)
+			}
+
+			tbl.AddRow(i+1, resourceName, finalizer, managedBy, status, reason)
 		}
 		tbl.Print()
 		fmt.Fprintln(p.out)
 	}
 
+	if rc := report.RemainingContent; rc != nil {
+		if len(rc.Resources) > 0 || len(rc.Finalizers) > 0 {
+			cyan.Fprintln(p.out, "REMAINING CONTENT (reported by namespace controller)")
+			for _, r := range rc.Resources {
+				fmt.Fprintf(p.out, "• %s: %d reported, %d visible\n", r.Name, r.Reported, r.Visible)
+			}
+			for _, f := range rc.Finalizers {
+				fmt.Fprintf(p.out, "• finalizer %s: %d reported, %d visible\n", f.Name, f.Reported, f.Visible)
+			}
+			fmt.Fprintln(p.out)
+		}
+		if len(rc.DeletionErrors) > 0 || len(rc.ParsingErrors) > 0 {
+			red.Fprintln(p.out, "DELETION ERRORS")
+			for _, e := range rc.DeletionErrors {
+				fmt.Fprintf(p.out, "• %s\n", truncate(e, 160))
+			}
+			for _, e := range rc.ParsingErrors {
+				fmt.Fprintf(p.out, "• %s\n", truncate(e, 160))
+			}
+			fmt.Fprintln(p.out)
+		}
+		if len(rc.Mismatches) > 0 {
+			yellow.Fprintln(p.out, "CONTENT MISMATCHES")
+			for _, m := range rc.Mismatches {
+				fmt.Fprintf(p.out, "• %s\n", m)
+			}
+			fmt.Fprintln(p.out)
+		}
+	}
+
+	if report.HasSelfDeadlock() {
+		red.Fprintln(p.out, "SELF-DEADLOCK")
+		for _, c := range report.SelfDeadlock.Controllers {
+			fmt.Fprintf(p.out, "• %s %s/%s handles %s\n", c.Kind, c.Namespace, c.Name, strings.Join(c.Finalizers, ", "))
+			if c.Message != "" {
+				fmt.Fprintf(p.out, "  %s\n", c.Message)
+			}
+		}
+		fmt.Fprintln(p.out)
+	}
+
+	if report.HasWebhookIssues() {
+		red.Fprintln(p.out, "WEBHOOKS")
+		for _, w := range report.WebhookIssues {
+			fmt.Fprintf(p.out, "• %s/%s (%s, failurePolicy=%s)", w.Name, w.WebhookName, w.Type, w.FailurePolicy)
+			if w.ProbedWith != nil {
+				fmt.Fprintf(p.out, " [probed with %s]", w.ProbedWith.String())
+			}
+			fmt.Fprintln(p.out)
+			if w.Error != "" {
+				fmt.Fprintf(p.out, "  %s\n", truncate(w.Error, 100))
+			}
+			for _, issue := range w.TLSIssues {
+				if issue != w.Error {
+					fmt.Fprintf(p.out, "  TLS: %s\n", issue)
+				}
+			}
+			for _, h := range w.Endpoints {
+				fmt.Fprintf(p.out, "  Endpoints %s port %s: %d/%d ready, %d serving, %d terminating\n",
+					h.AddressType, h.PortString(), h.Ready, h.Total, h.Serving, h.Terminating)
+			}
+		}
+		fmt.Fprintln(p.out)
+	}
+
+	if report.HasAdmissionPolicies() {
+		yellow.Fprintln(p.out, "ADMISSION POLICIES")
+		for _, ap := range report.AdmissionPolicies {
+			fmt.Fprintf(p.out, "• %s (binding %s, %s, failurePolicy=%s): %d blocker(s)",
+				ap.Policy, ap.Binding, strings.Join(ap.ValidationActions, ","), ap.FailurePolicy, len(ap.Blockers))
+			if ap.Conditional {
+				fmt.Fprint(p.out, " [conditional]")
+			}
+			fmt.Fprintln(p.out)
+			for _, v := range ap.Validations {
+				fmt.Fprintf(p.out, "  %s\n", truncate(v, 100))
+			}
+		}
+		fmt.Fprintln(p.out)
+	}
+
+	if report.HasGCIssues() {
+		yellow.Fprintln(p.out, "GARBAGE COLLECTOR")
+		for _, issue := range report.GCIssues {
+			fmt.Fprintf(p.out, "• %s [%s]: %s\n", issue.Object.String(), issue.Type, issue.Message)
+		}
+		fmt.Fprintln(p.out)
+	}
+
+	if external := report.ExternalBlockers(); len(external) > 0 {
+		red.Fprintln(p.out, "EXTERNAL RESOURCES")
+		for _, b := range external {
+			e := b.External
+			fmt.Fprintf(p.out, "• %s: %s (%s)\n", b.ResourceRef.String(), e.Description(), e.Finalizer)
+			controller := e.Controller
+			if controller == "" {
+				controller = "unknown controller"
+			}
+			if e.Class != "" {
+				controller += ", class " + e.Class
+			}
+			fmt.Fprintf(p.out, "    Controller: %s [%s]\n", controller, e.ControllerState)
+			if e.Message != "" {
+				fmt.Fprintf(p.out, "    %s\n", e.Message)
+			}
+		}
+		fmt.Fprintln(p.out)
+	}
+
+	if cloud := report.CloudResourceBlockers(); len(cloud) > 0 {
+		red.Fprintln(p.out, "CLOUD RESOURCES")
+		for _, b := range cloud {
+			c := b.CloudResource
+			fmt.Fprintf(p.out, "• %s: %s (%s)", b.ResourceRef.String(), c.Description(), c.Finalizer)
+			if c.Retained {
+				fmt.Fprint(p.out, " [retained]")
+			}
+			fmt.Fprintln(p.out)
+			if c.Provider != "" {
+				fmt.Fprintf(p.out, "    Provider: %s [%s]\n", c.Provider, c.ProviderState)
+			}
+			if c.Message != "" {
+				fmt.Fprintf(p.out, "    %s\n", c.Message)
+			}
+		}
+		fmt.Fprintln(p.out)
+	}
+
+	var gitOps []types.Blocker
+	for _, b := range report.Blockers {
+		if b.GitOps != nil {
+			gitOps = append(gitOps, b)
+		}
+	}
+	if len(gitOps) > 0 {
+		yellow.Fprintln(p.out, "GITOPS")
+		for _, b := range gitOps {
+			g := b.GitOps
+			fmt.Fprintf(p.out, "• %s %s (%s)\n", g.Tool, b.ResourceRef.String(), g.Finalizer)
+			destination := g.Destination
+			if g.InCluster {
+				destination += " (in-cluster)"
+			}
+			fmt.Fprintf(p.out, "    Destination: %s, sync %s, health %s\n", destination, orUnknown(g.SyncStatus), orUnknown(g.HealthStatus))
+			fmt.Fprintf(p.out, "    Managed: %d listed, %d remaining", g.ManagedCount, len(g.Remaining))
+			if !g.Prune {
+				fmt.Fprint(p.out, " (kept on deletion)")
+			}
+			fmt.Fprintln(p.out)
+			if g.Controller != "" {
+				fmt.Fprintf(p.out, "    Controller: %s [%s]\n", g.Controller, g.ControllerState)
+			}
+			if g.StuckReason != "" {
+				red.Fprintf(p.out, "    Stuck: %s\n", g.StuckReason)
+			}
+		}
+		fmt.Fprintln(p.out)
+	}
+
+	var jobPods []types.Blocker
+	for _, b := range report.Blockers {
+		if b.JobTracking != nil {
+			jobPods = append(jobPods, b)
+		}
+	}
+	if len(jobPods) > 0 {
+		yellow.Fprintln(p.out, "JOB TRACKING")
+		for _, b := range jobPods {
+			fmt.Fprintf(p.out, "• %s [%s]: %s\n", b.ResourceRef.String(), orUnknown(b.JobTracking.Phase), b.JobTracking.Message)
+		}
+		fmt.Fprintln(p.out)
+	}
+
+	if len(report.HelmReleases) > 0 {
+		yellow.Fprintln(p.out, "HELM RELEASES")
+		for _, r := range report.HelmReleases {
+			fmt.Fprintf(p.out, "• %s", r.String())
+			if r.Chart != "" {
+				fmt.Fprintf(p.out, " (%s)", r.Chart)
+			}
+			if r.Status == "" {
+				fmt.Fprint(p.out, ": no release Secret")
+			} else if r.IsStuck() {
+				red.Fprintf(p.out, ": revision %d %s", r.Revision, r.Status)
+			} else {
+				fmt.Fprintf(p.out, ": revision %d %s", r.Revision, r.Status)
+			}
+			fmt.Fprintf(p.out, ", %d blocker(s)\n", len(r.Blockers))
+			if r.IsStuck() && r.Description != "" {
+				fmt.Fprintf(p.out, "    %s\n", truncate(r.Description, 100))
+			}
+			for _, h := range r.Hooks {
+				fmt.Fprintf(p.out, "    Hook %s [%s]: %s\n", h.ResourceRef.String(), strings.Join(h.Events, ","), h.Message)
+			}
+		}
+		fmt.Fprintln(p.out)
+	}
+
+	if len(report.Controllers) > 0 && !report.HasSelfDeadlock() {
+		cyan.Fprintln(p.out, "CONTROLLERS")
+		for _, c := range report.Controllers {
+			fmt.Fprintf(p.out, "• %s %s/%s: %s\n", c.Kind, c.Namespace, c.Name, controllerState(c))
+		}
+		fmt.Fprintln(p.out)
+	}
+
+	if len(report.Events) > 0 {
+		yellow.Fprintln(p.out, "RECENT WARNINGS")
+		for _, e := range report.Events {
+			fmt.Fprintf(p.out, "• %s %s: %s", e.Regarding.String(), e.Reason, truncate(e.Note, 100))
+			if e.Count > 1 {
+				fmt.Fprintf(p.out, " (x%d)", e.Count)
+			}
+			fmt.Fprintf(p.out, " %s ago", time.Since(e.LastSeen).Round(time.Second))
+			if e.Classification != "" {
+				fmt.Fprintf(p.out, " [%s]", e.Classification)
+			}
+			fmt.Fprintln(p.out)
+		}
+		fmt.Fprintln(p.out)
+	}
+
+	if len(report.ControllerLogs) > 0 {
+		cyan.Fprintln(p.out, "CONTROLLER LOGS")
+		for _, l := range report.ControllerLogs {
+			fmt.Fprintf(p.out, "• %s (pod %s, container %s)", l.Blocker.String(), l.Pod, l.Container)
+			if l.Total > len(l.Lines) {
+				fmt.Fprintf(p.out, " - last %d of %d matching lines", len(l.Lines), l.Total)
+			}
+			fmt.Fprintln(p.out)
+			for _, line := range l.Lines {
+				fmt.Fprintf(p.out, "  %s\n", line)
+			}
+		}
+		fmt.Fprintln(p.out)
+	}
+
 	if len(report.DiscoveryFailures) > 0 {
 		yellow.Fprintln(p.out, "DISCOVERY FAILURES")
 		for _, f := range report.DiscoveryFailures {
@@ -142,6 +417,49 @@ func (p *Printer) printText(report *types.DiagnosisReport) error {
 		fmt.Fprintln(p.out)
 	}
 
+	if report.HasCorruptObjects() {
+		red.Fprintf(p.out, "CORRUPT OBJECTS (%d)\n", len(report.CorruptObjects))
+		for _, obj := range report.CorruptObjects {
+			name := obj.ResourceRef.String()
+			if !obj.IsIdentified() {
+				name = obj.Resource + " (object not reported)"
+			}
+			fmt.Fprintf(p.out, "• %s - %s\n", name, truncate(obj.Error, 100))
+		}
+		fmt.Fprintln(p.out)
+	}
+
+	if c := report.Conversion; c != nil {
+		heading := yellow
+		if c.InstancesUnreadable {
+			heading = red
+		}
+		heading.Fprintln(p.out, "CONVERSION")
+		fmt.Fprintf(p.out, "• Strategy: %s", c.Strategy)
+		if len(c.StoredVersions) > 0 {
+			fmt.Fprintf(p.out, " (stored versions: %s)", strings.Join(c.StoredVersions, ", "))
+		}
+		fmt.Fprintln(p.out)
+		if w := c.Webhook; w != nil {
+			target := w.ServiceRef
+			if target == "" {
+				target = w.URL
+			}
+			if w.Healthy {
+				fmt.Fprintf(p.out, "• Webhook %s: healthy\n", target)
+			} else {
+				fmt.Fprintf(p.out, "• Webhook %s: %s\n", target, truncate(w.Error, 100))
+			}
+		}
+		if c.ReadVersion != "" {
+			fmt.Fprintf(p.out, "• Instances read at %s\n", c.ReadVersion)
+		}
+		for _, ve := range c.VersionErrors {
+			fmt.Fprintf(p.out, "• %s: %s\n", ve.Version, truncate(ve.Error, 100))
+		}
+		fmt.Fprintln(p.out)
+	}
+
 	if p.verbose && len(report.Conditions) > 0 {
 		cyan.Fprintln(p.out, "CONDITIONS")
 		for _, c := range report.Conditions {
@@ -175,6 +493,29 @@ func (p *Printer) printText(report *types.DiagnosisReport) error {
 	return nil
 }
 
+// controllerState summarizes whether a controller is reconciling
+func controllerState(c types.ControllerStatus) string {
+	state := "not ready"
+	if c.Ready {
+		state = "ready"
+	}
+	switch c.Liveness {
+	case types.ControllerAlive:
+		state += fmt.Sprintf(", lease %s held by %s", c.LeaseName, c.LeaseHolder)
+	case types.ControllerStale:
+		state += fmt.Sprintf(", lease %s is stale", c.LeaseName)
+		if c.LeaseRenewTime != nil {
+			state += fmt.Sprintf(" (last renewed %s ago)", time.Since(c.LeaseRenewTime.Time).Round(time.Second))
+		}
+	case types.ControllerAbsent:
+		state += ", no leader-election lease found"
+	}
+	if c.Message != "" {
+		state += " - " + c.Message
+	}
+	return state
+}
+
 func truncate(s string, maxLen int) string {
 	if len(s) <= maxLen {
 		return s
@@ -267,6 +608,14 @@ func (p *Printer) printPlanText(report *types.DiagnosisReport, plan *types.Plan)
 		fmt.Fprintln(p.out)
 	}
 
+	if len(plan.Notes) > 0 {
+		cyan.Fprintln(p.out, "NOTES")
+		for _, note := range plan.Notes {
+			fmt.Fprintln(p.out, note)
+		}
+		fmt.Fprintln(p.out)
+	}
+
 	// Actions table
 	if len(plan.Actions) == 0 {
 		fmt.Fprintln(p.out, "No actions required.")
@@ -298,6 +647,41 @@ func (p *Printer) printPlanText(report *types.DiagnosisReport, plan *types.Plan)
 	tbl.Print()
 	fmt.Fprintln(p.out)
 
+	// What the plan deletes, releases or leaves behind, per action
+	if len(plan.Impact) > 0 {
+		steps := make(map[string]int)
+		for i, action := range plan.Actions {
+			steps[action.ID] = i
+		}
+		red.Fprintln(p.out, "IMPACT")
+		for _, impact := range plan.Impact {
+			i, ok := steps[impact.ActionID]
+			if !ok {
+				continue
+			}
+			fmt.Fprintf(p.out, "Step %d: %s\n", i+1, plan.Actions[i].Description)
+			for _, item := range impact.Items {
+				fmt.Fprintf(p.out, "  • [%s] %s: %s\n", item.Type, item.Object.String(), item.Detail)
+			}
+		}
+		fmt.Fprintln(p.out)
+	}
+
+	// RBAC preflight results
+	if len(plan.Permissions) > 0 {
+		missing := plan.MissingPermissions()
+		if len(missing) > 0 {
+			red.Fprintf(p.out, "MISSING PERMISSIONS (%d of %d)\n", len(missing), len(plan.Permissions))
+			for _, m := range missing {
+				fmt.Fprintf(p.out, "• %s (%s)\n", m.String(), m.ActionID)
+			}
+			fmt.Fprintln(p.out, "apply will refuse to start unless --skip-preflight is used.")
+		} else {
+			green.Fprintf(p.out, "✓ All %d required permissions granted\n", len(plan.Permissions))
+		}
+		fmt.Fprintln(p.out)
+	}
+
 	// Show commands if available
 	if len(plan.Commands) > 0 && p.verbose {
 		cyan.Fprintln(p.out, "KUBECTL COMMANDS")
@@ -364,20 +748,30 @@ func (p *Printer) printApplyResultText(result *types.ApplyResult) error {
 		fmt.Fprintf(p.out, "%-16s%d\n", "Skipped:", result.Skipped)
 	}
 	fmt.Fprintf(p.out, "%-16s%s\n", "Duration:", duration)
+	switch result.DryRunMode {
+	case types.DryRunServer:
+		fmt.Fprintf(p.out, "%-16s%s\n", "Dry-Run:", "server-validated (no changes persisted)")
+	case types.DryRunClient:
+		yellow.Fprintf(p.out, "%-16s%s\n", "Dry-Run:", "client preview only (not validated by the API server)")
+	}
 	fmt.Fprintln(p.out)
 
 	// Overall status
-	switch result.ExitCode {
-	case 0:
+	switch {
+	case result.DryRunMode == types.DryRunServer && result.ExitCode == 0:
+		green.Fprintln(p.out, "✅ The API server would accept all actions")
+	case result.DryRunMode == types.DryRunServer:
+		red.Fprintln(p.out, "❌ The API server would reject some actions")
+	case result.ExitCode == 0:
 		green.Fprintln(p.out, "✅ All actions completed successfully")
-	case 1:
+	case result.ExitCode == 1:
 		red.Fprintln(p.out, "❌ All actions failed")
-	case 2:
+	case result.ExitCode == 2:
 		yellow.Fprintln(p.out, "⚠️  Partial success - some actions failed")
 	}
 
-	// Show failed actions if verbose
-	if p.verbose && result.Failed > 0 {
+	// Show failed actions if verbose, or always when the server rejected a dry-run
+	if (p.verbose || result.DryRunMode == types.DryRunServer) && result.Failed > 0 {
 		fmt.Fprintln(p.out)
 		red.Fprintln(p.out, "FAILED ACTIONS")
 		for _, ar := range result.Actions {
@@ -473,3 +867,27 @@ func (p *Printer) printWebhookReportText(webhooks []types.WebhookInfo) error {
 
 	return nil
 }
+
+// targetFinalizers returns the target's metadata finalizers with the field manager that owns them
+func targetFinalizers(report *types.DiagnosisReport) []string {
+	result := make([]string, 0, len(report.Finalizers))
+	for _, f := range report.Finalizers {
+		entry := f
+		for _, p := range report.FinalizerProvenance {
+			if p.Finalizer == f {
+				entry += " (" + p.Manager + ")"
+				break
+			}
+		}
+		result = append(result, entry)
+	}
+	return result
+}
+
+// orUnknown returns s, or "Unknown" if it is empty
+func orUnknown(s string) string {
+	if s == "" {
+		return "Unknown"
+	}
+	return s
+}
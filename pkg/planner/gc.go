@@ -0,0 +1,110 @@
+package planner
+
+import (
+	"fmt"
+
+	"github.com/sozercan/unstuck/pkg/types"
+)
+
+// gcActions returns garbage collector specific actions for a stuck blocker: dangling owner
+// references are dropped, and GC finalizers are removed on their own unless the blocker also
+// carries other finalizers, in which case generic finalizer removal is used
+func (p *Planner) gcActions(blocker types.Blocker, issues []types.GCIssue, namespace string) []types.Action {
+	target := blockerTarget(blocker, namespace)
+
+	var actions []types.Action
+	for _, issue := range issues {
+		if issue.Type == types.GCIssueDanglingOwner {
+			actions = append(actions, p.removeOwnerReferenceAction(blocker, target, issue))
+		}
+	}
+
+	if !hasOnlyGCFinalizers(blocker) {
+		return append(actions, p.finalizerRemovalAction(blocker, namespace))
+	}
+	for _, f := range blocker.Finalizers {
+		actions = append(actions, p.removeGCFinalizerAction(blocker, target, f))
+	}
+	return actions
+}
+
+// removeOwnerReferenceAction drops an owner reference whose owner no longer exists
+func (p *Planner) removeOwnerReferenceAction(blocker types.Blocker, target types.ResourceRef, issue types.GCIssue) types.Action {
+	index := 0
+	for i, owner := range blocker.OwnerReferences {
+		if string(owner.UID) == issue.OwnerUID {
+			index = i
+			break
+		}
+	}
+
+	return types.Action{
+		Type:            types.ActionPatch,
+		EscalationLevel: types.EscalationFinalizer,
+		Description:     fmt.Sprintf("Drop dangling owner reference %s from %s", issue.Owner.String(), target.String()),
+		Target:          target,
+		Operation:       types.OpRemoveOwnerReference,
+		Command: generateJSONPatchCommand(target, fmt.Sprintf(
+			`[{"op":"test","path":"/metadata/ownerReferences/%d/uid","value":"%s"},{"op":"remove","path":"/metadata/ownerReferences/%d"}]`,
+			index, issue.OwnerUID, index,
+		)),
+		Risk:           types.RiskLow,
+		RequiresForce:  false,
+		ExpectedResult: "Owner reference removed, the garbage collector no longer waits on the missing owner",
+		OwnerUID:       issue.OwnerUID,
+	}
+}
+
+// removeGCFinalizerAction removes a single garbage collector finalizer
+func (p *Planner) removeGCFinalizerAction(blocker types.Blocker, target types.ResourceRef, finalizer string) types.Action {
+	action := p.removeSingleFinalizerAction(blocker, target, finalizer, types.OpRemoveGCFinalizer, types.RiskMedium)
+	action.Description = fmt.Sprintf("Remove garbage collector finalizer %s from %s", finalizer, target.String())
+	action.ExpectedResult = "Object deleted; remaining dependents are cleaned up by the garbage collector in the background"
+	return action
+}
+
+// removeSingleFinalizerAction removes one finalizer with a JSON patch that tests it is still
+// at the same index, leaving the blocker's other finalizers in place
+func (p *Planner) removeSingleFinalizerAction(blocker types.Blocker, target types.ResourceRef, finalizer, operation string, risk types.RiskLevel) types.Action {
+	index := 0
+	for i, f := range blocker.Finalizers {
+		if f == finalizer {
+			index = i
+			break
+		}
+	}
+
+	return types.Action{
+		Type:            types.ActionPatch,
+		EscalationLevel: types.EscalationFinalizer,
+		Target:          target,
+		Operation:       operation,
+		Command: generateJSONPatchCommand(target, fmt.Sprintf(
+			`[{"op":"test","path":"/metadata/finalizers/%d","value":"%s"},{"op":"remove","path":"/metadata/finalizers/%d"}]`,
+			index, finalizer, index,
+		)),
+		Risk:          risk,
+		RequiresForce: false,
+		Finalizer:     finalizer,
+	}
+}
+
+// hasOnlyGCFinalizers returns true if every finalizer on the blocker is processed by the garbage collector
+func hasOnlyGCFinalizers(blocker types.Blocker) bool {
+	for _, f := range blocker.Finalizers {
+		if !types.IsGCFinalizer(f) {
+			return false
+		}
+	}
+	return true
+}
+
+// generateJSONPatchCommand generates a kubectl JSON patch command
+func generateJSONPatchCommand(target types.ResourceRef, patch string) string {
+	cmd := fmt.Sprintf("kubectl patch %s %s", target.Kind, target.Name)
+	if target.Namespace != "" {
+		cmd += fmt.Sprintf(" -n %s", target.Namespace)
+	}
+	cmd += fmt.Sprintf(" --type=json -p '%s'", patch)
+	return cmd
+}
@@ -0,0 +1,73 @@
+package planner
+
+import (
+	"fmt"
+
+	"github.com/sozercan/unstuck/pkg/types"
+)
+
+// cascadeActions deletes the managed resources of a GitOps object whose controller is known to be
+// down, so that removing its finalizer afterwards leaves nothing behind. Deleting live workloads
+// is only done with --allow-force.
+func (p *Planner) cascadeActions(blocker types.Blocker) []types.Action {
+	g := blocker.GitOps
+	if g == nil || g.ControllerState != types.ExternalControllerNotRunning {
+		return nil
+	}
+
+	var actions []types.Action
+	for _, ref := range g.CascadeCandidates() {
+		actions = append(actions, types.Action{
+			Type:            types.ActionDelete,
+			EscalationLevel: types.EscalationCRD,
+			Description:     fmt.Sprintf("Delete %s managed by %s", ref.String(), blocker.ResourceRef.String()),
+			Target:          ref,
+			Operation:       types.OpCascadeDelete,
+			Command:         generateDeleteCommand(ref),
+			Risk:            types.RiskHigh,
+			RequiresForce:   true,
+			ExpectedResult:  fmt.Sprintf("Managed resource deleted, as %s would on deletion", g.Tool),
+		})
+	}
+	return actions
+}
+
+// orphansManagedResources returns true if removing the blocker's GitOps finalizer would orphan
+// in-cluster resources that it prunes. A running controller does not help: without the
+// finalizer it never gets to delete them.
+func orphansManagedResources(blocker types.Blocker) bool {
+	g := blocker.GitOps
+	return g != nil && len(g.CascadeCandidates()) > 0
+}
+
+// gitOpsNote explains what removing a GitOps finalizer does to the managed resources
+func gitOpsNote(blocker types.Blocker) string {
+	g := blocker.GitOps
+	note := fmt.Sprintf("%s %s", g.Tool, blocker.ResourceRef.String())
+	if g.StuckReason != "" {
+		note += " is stuck: " + g.StuckReason + "."
+	} else {
+		note += " is stuck."
+	}
+
+	switch {
+	case !g.Prune:
+		note += " Its managed resources are kept on deletion."
+	case !g.InCluster:
+		note += fmt.Sprintf(" Delete its %d managed resource(s) in %s before removing the finalizer, or they are orphaned.", g.ManagedCount, g.Destination)
+	case g.ControllerState == types.ExternalControllerRunning && len(g.CascadeCandidates()) > 0:
+		note += fmt.Sprintf(" Its controller %s is running; fix the error above instead of orphaning %d managed resource(s).", g.Controller, len(g.CascadeCandidates()))
+	case g.ControllerState == types.ExternalControllerNotRunning && len(g.CascadeCandidates()) > 0:
+		note += fmt.Sprintf(" %d managed resource(s) are deleted at Level 3 (--allow-force) before the finalizer is removed.", len(g.CascadeCandidates()))
+	}
+	return note
+}
+
+// generateDeleteCommand generates a kubectl delete command that does not wait for finalizers
+func generateDeleteCommand(ref types.ResourceRef) string {
+	cmd := fmt.Sprintf("kubectl delete %s %s", ref.Kind, ref.Name)
+	if ref.Namespace != "" {
+		cmd += fmt.Sprintf(" -n %s", ref.Namespace)
+	}
+	return cmd + " --wait=false"
+}
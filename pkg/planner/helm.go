@@ -0,0 +1,85 @@
+package planner
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sozercan/unstuck/pkg/types"
+)
+
+// helmHookActions deletes the delete hook Jobs an uninstalling Helm release is waiting on,
+// together with their pods, so that the release can be uninstalled without hooks. Failed hooks
+// will not be retried and are deleted at L1. Running hooks may still be doing their cleanup, so
+// they are only deleted at L3 with --allow-force. Hooks of installs, upgrades and rollbacks are
+// left to Helm.
+func (p *Planner) helmHookActions(release types.HelmRelease, running bool) []types.Action {
+	level, risk, requiresForce, state := types.EscalationClean, types.RiskLow, false, "failed"
+	if running {
+		level, risk, requiresForce, state = types.EscalationCRD, types.RiskHigh, true, "still running"
+	}
+
+	var actions []types.Action
+	for _, h := range deleteHooks(release) {
+		if h.Failed == running {
+			continue
+		}
+		actions = append(actions, types.Action{
+			Type:            types.ActionDelete,
+			EscalationLevel: level,
+			Description:     fmt.Sprintf("Delete %s hook Job %s of Helm release %s (%s)", strings.Join(h.Events, ","), h.Name, release.String(), state),
+			Target:          h.ResourceRef,
+			Operation:       types.OpDeleteHelmHook,
+			Command:         fmt.Sprintf("kubectl delete job %s -n %s --cascade=background --wait=false", h.Name, h.Namespace),
+			Risk:            risk,
+			RequiresForce:   requiresForce,
+			ExpectedResult:  "Hook Job and its pods deleted",
+		})
+	}
+	return actions
+}
+
+// deleteHooks returns the delete hook Jobs of an uninstalling release that are not already terminating
+func deleteHooks(release types.HelmRelease) []types.HelmHook {
+	if release.Status != "uninstalling" {
+		return nil
+	}
+	var hooks []types.HelmHook
+	for _, h := range release.Hooks {
+		if !h.Terminating && h.IsDeleteHook() {
+			hooks = append(hooks, h)
+		}
+	}
+	return hooks
+}
+
+// runningHookNote explains why running delete hooks are kept below L3 or without --allow-force
+func runningHookNote(release types.HelmRelease) string {
+	var names []string
+	for _, h := range deleteHooks(release) {
+		if !h.Failed {
+			names = append(names, h.Name)
+		}
+	}
+	if len(names) == 0 {
+		return ""
+	}
+	return fmt.Sprintf(
+		"Left running delete hook Job(s) %s of Helm release %s in place; they may still be cleaning up. Re-run with --max-escalation=3 --allow-force to delete them.",
+		strings.Join(names, ", "), release.String(),
+	)
+}
+
+// helmNote explains how to finish the Helm operation a release is stuck in
+func helmNote(release types.HelmRelease) string {
+	note := fmt.Sprintf("Helm release %s is %s", release.String(), release.Status)
+	if release.Description != "" {
+		note += ": " + release.Description
+	}
+	note += "."
+	if release.Status == "uninstalling" {
+		note += fmt.Sprintf(" Run `helm uninstall %s -n %s --no-hooks` so Helm deletes the rest of the release and its record.", release.Name, release.Namespace)
+	} else {
+		note += " Its hooks are left to Helm; finish or roll back the operation with Helm."
+	}
+	return note
+}
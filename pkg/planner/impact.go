@@ -0,0 +1,190 @@
+package planner
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sozercan/unstuck/pkg/types"
+)
+
+// CalculateImpact returns what each action deletes, releases or leaves behind, computed from the
+// diagnosis. Actions without impact are left out.
+func CalculateImpact(diagnosis *types.DiagnosisReport, actions []types.Action) []types.ActionImpact {
+	blockers := make(map[types.ResourceRef]types.Blocker)
+	for _, b := range diagnosis.Blockers {
+		blockers[refKey(blockerTarget(b, diagnosis.Target.Namespace))] = b
+	}
+
+	// Blockers whose finalizers are removed, or that are deleted, are not left behind by force-finalize
+	handled := make(map[types.ResourceRef]bool)
+	for _, action := range actions {
+		switch action.Operation {
+		case types.OpRemoveFinalizers, types.OpRemoveGCFinalizer, types.OpRemoveJobTrackingFinalizer, types.OpCascadeDelete, types.OpUnsafeDelete:
+			handled[refKey(action.Target)] = true
+		}
+	}
+
+	var impact []types.ActionImpact
+	for _, action := range actions {
+		var items []types.ImpactItem
+		switch action.Operation {
+		case types.OpRemoveFinalizers, types.OpRemoveGCFinalizer, types.OpRemoveJobTrackingFinalizer:
+			b, ok := blockers[refKey(action.Target)]
+			if !ok {
+				break
+			}
+			// Objects that are not being deleted only lose their finalizers. Single finalizers are
+			// removed in order, so the object is deleted once the last one goes.
+			last := action.Finalizer == "" || len(b.Finalizers) == 0 || action.Finalizer == b.Finalizers[len(b.Finalizers)-1]
+			if b.IsTerminating() && last {
+				items = append(items, deletedObjectImpact(action.Target)...)
+			}
+			items = append(items, leakedResourceImpact(b)...)
+		case types.OpCascadeDelete, types.OpUnsafeDelete, types.OpDeleteHelmHook:
+			items = deletedObjectImpact(action.Target)
+		case types.OpRolloutRestart:
+			items = []types.ImpactItem{{Type: types.ImpactPodsRestarted, Object: action.Target, Detail: "running pods are replaced one by one"}}
+		case types.OpRemoveNamespaceFinalizers:
+			items = skippedCleanupImpact(diagnosis)
+		case types.OpDisableConversionWebhook:
+			items = disabledConversionImpact(diagnosis)
+		case types.OpRemoveCRDFinalizer:
+			items = orphanedInstanceImpact(diagnosis)
+		case types.OpForceFinalize:
+			items = abandonedObjectImpact(diagnosis, handled)
+		}
+		if len(items) > 0 {
+			impact = append(impact, types.ActionImpact{ActionID: action.ID, Items: items})
+		}
+	}
+	return impact
+}
+
+// refKey drops the apiVersion of a reference, which actions do not always carry
+func refKey(ref types.ResourceRef) types.ResourceRef {
+	return types.ResourceRef{Kind: ref.Kind, Namespace: ref.Namespace, Name: ref.Name}
+}
+
+// deletedObjectImpact describes the data lost by deleting an object
+func deletedObjectImpact(ref types.ResourceRef) []types.ImpactItem {
+	switch ref.Kind {
+	case "PersistentVolumeClaim":
+		return []types.ImpactItem{{
+			Type:   types.ImpactVolumeReleased,
+			Object: ref,
+			Detail: "the bound PersistentVolume is released; its reclaim policy decides whether the data is deleted",
+		}}
+	case "PersistentVolume":
+		return []types.ImpactItem{{
+			Type:   types.ImpactVolumeReleased,
+			Object: ref,
+			Detail: "the volume is deleted even if a claim still uses it",
+		}}
+	case "Secret", "ConfigMap":
+		return []types.ImpactItem{{Type: types.ImpactDataDeleted, Object: ref, Detail: ref.Kind + " deleted"}}
+	case "Deployment", "StatefulSet", "DaemonSet", "ReplicaSet", "Job":
+		return []types.ImpactItem{{Type: types.ImpactWorkloadDeleted, Object: ref, Detail: ref.Kind + " deleted with its pods"}}
+	case "CronJob":
+		return []types.ImpactItem{{Type: types.ImpactWorkloadDeleted, Object: ref, Detail: "CronJob deleted with its Jobs and their pods"}}
+	}
+	return nil
+}
+
+// skippedCleanupImpact describes the cleanup skipped by removing a namespace's metadata finalizers
+func skippedCleanupImpact(diagnosis *types.DiagnosisReport) []types.ImpactItem {
+	var items []types.ImpactItem
+	for _, f := range diagnosis.Finalizers {
+		detail := "cleanup of " + f + " never runs"
+		for _, p := range diagnosis.FinalizerProvenance {
+			if p.Finalizer == f {
+				detail += fmt.Sprintf(" (managed by %s)", p.Manager)
+				break
+			}
+		}
+		items = append(items, types.ImpactItem{Type: types.ImpactCleanupSkipped, Object: diagnosis.Target, Detail: detail})
+	}
+	return items
+}
+
+// disabledConversionImpact describes the instances served without conversion once a CRD's
+// conversion webhook is switched off
+func disabledConversionImpact(diagnosis *types.DiagnosisReport) []types.ImpactItem {
+	detail := "objects stored at other versions are served with only apiVersion rewritten, without schema conversion"
+	if c := diagnosis.Conversion; c != nil && len(c.StoredVersions) > 1 {
+		detail = fmt.Sprintf("objects stored at %s are served with only apiVersion rewritten, without schema conversion", strings.Join(c.StoredVersions, ", "))
+	}
+	return []types.ImpactItem{{Type: types.ImpactConversionDisabled, Object: diagnosis.Target, Detail: detail}}
+}
+
+// leakedResourceImpact describes the cloud resources the finalizers of a blocker would have deleted
+func leakedResourceImpact(b types.Blocker) []types.ImpactItem {
+	var items []types.ImpactItem
+	if b.External != nil {
+		items = append(items, types.ImpactItem{Type: types.ImpactCloudResourceLeaked, Object: b.ResourceRef, Detail: b.External.Description()})
+	}
+	if b.CloudResource != nil && b.CloudResource.Leaks() {
+		items = append(items, types.ImpactItem{Type: types.ImpactCloudResourceLeaked, Object: b.ResourceRef, Detail: b.CloudResource.Description()})
+	}
+	return items
+}
+
+// orphanedInstanceImpact describes the instances left in storage when a CRD's cleanup finalizer
+// is removed; they reappear if the CRD is created again
+func orphanedInstanceImpact(diagnosis *types.DiagnosisReport) []types.ImpactItem {
+	var items []types.ImpactItem
+	for _, b := range diagnosis.Blockers {
+		items = append(items, types.ImpactItem{Type: types.ImpactCRDataOrphaned, Object: b.ResourceRef, Detail: "left in storage without its CRD"})
+	}
+	if hidden := diagnosis.InstanceCount - len(diagnosis.Blockers); hidden > 0 {
+		items = append(items, types.ImpactItem{
+			Type:   types.ImpactCRDataOrphaned,
+			Object: diagnosis.Target,
+			Detail: fmt.Sprintf("%d more instance(s) left in storage without their CRD", hidden),
+		})
+	}
+	return items
+}
+
+// abandonedObjectImpact describes the objects left behind by force-finalizing a namespace,
+// including content the namespace controller reports but unstuck cannot list. Content that is not
+// a single object is named with a "*" name.
+func abandonedObjectImpact(diagnosis *types.DiagnosisReport, handled map[types.ResourceRef]bool) []types.ImpactItem {
+	var items []types.ImpactItem
+	for _, b := range diagnosis.Blockers {
+		if handled[refKey(blockerTarget(b, diagnosis.Target.Namespace))] {
+			continue
+		}
+		detail := "left in storage with its finalizers"
+		if b.External != nil || (b.CloudResource != nil && b.CloudResource.Leaks()) {
+			detail += "; its cloud resource is never deleted"
+		}
+		items = append(items, types.ImpactItem{Type: types.ImpactObjectAbandoned, Object: b.ResourceRef, Detail: detail})
+	}
+	for _, f := range diagnosis.DiscoveryFailures {
+		items = append(items, types.ImpactItem{
+			Type:   types.ImpactObjectAbandoned,
+			Object: types.ResourceRef{Kind: orAll(f.Resource), APIVersion: f.GroupVersion, Namespace: diagnosis.Target.Name, Name: "*"},
+			Detail: "objects of " + f.GroupVersion + " cannot be listed and are left in storage",
+		})
+	}
+	if diagnosis.RemainingContent != nil {
+		for _, r := range diagnosis.RemainingContent.Resources {
+			if r.Reported > r.Visible {
+				items = append(items, types.ImpactItem{
+					Type:   types.ImpactObjectAbandoned,
+					Object: types.ResourceRef{Kind: r.Name, Namespace: diagnosis.Target.Name, Name: "*"},
+					Detail: fmt.Sprintf("%d reported by the namespace controller but not visible to unstuck", r.Reported-r.Visible),
+				})
+			}
+		}
+	}
+	return items
+}
+
+// orAll returns the resource, or "all" if discovery did not name one
+func orAll(resource string) string {
+	if resource == "" {
+		return "all"
+	}
+	return resource
+}
@@ -0,0 +1,40 @@
+package planner
+
+import (
+	"fmt"
+
+	batchv1 "k8s.io/api/batch/v1"
+
+	"github.com/sozercan/unstuck/pkg/types"
+)
+
+// jobTrackingActions returns actions for a pod whose Job is gone: the job tracking finalizer and
+// any garbage collector finalizers are removed on their own. If the pod also carries other
+// finalizers, generic finalizer removal is used.
+func (p *Planner) jobTrackingActions(blocker types.Blocker, namespace string) []types.Action {
+	target := blockerTarget(blocker, namespace)
+
+	for _, f := range blocker.Finalizers {
+		if f != batchv1.JobTrackingFinalizer && !types.IsGCFinalizer(f) {
+			return []types.Action{p.finalizerRemovalAction(blocker, namespace)}
+		}
+	}
+
+	var actions []types.Action
+	for _, f := range blocker.Finalizers {
+		if f == batchv1.JobTrackingFinalizer {
+			actions = append(actions, p.removeJobTrackingFinalizerAction(blocker, target))
+		} else {
+			actions = append(actions, p.removeGCFinalizerAction(blocker, target, f))
+		}
+	}
+	return actions
+}
+
+// removeJobTrackingFinalizerAction removes the job tracking finalizer from a pod no Job counts anymore
+func (p *Planner) removeJobTrackingFinalizerAction(blocker types.Blocker, target types.ResourceRef) types.Action {
+	action := p.removeSingleFinalizerAction(blocker, target, batchv1.JobTrackingFinalizer, types.OpRemoveJobTrackingFinalizer, types.RiskLow)
+	action.Description = fmt.Sprintf("Remove job tracking finalizer from %s (Job %s is gone)", target.String(), blocker.JobTracking.Job)
+	action.ExpectedResult = "Pod deleted; no Job is left to count it"
+	return action
+}
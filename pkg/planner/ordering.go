@@ -32,6 +32,12 @@ func OrderByDependencies(actions []types.Action) []types.Action {
 			return sorted[i].EscalationLevel < sorted[j].EscalationLevel
 		}
 
+		// Managed resources go before the finalizer of the GitOps object that deployed them
+		ci, cj := sorted[i].Operation == types.OpCascadeDelete, sorted[j].Operation == types.OpCascadeDelete
+		if ci != cj {
+			return ci
+		}
+
 		// Within same escalation level, sort by resource type priority
 		// Children (regular resources) come before parents (CRDs, Namespaces)
 		pi := resourcePriority(sorted[i].Target.Kind)
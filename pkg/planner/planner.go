@@ -2,6 +2,8 @@ package planner
 
 import (
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/sozercan/unstuck/pkg/types"
 )
@@ -44,34 +46,223 @@ func (p *Planner) Plan(diagnosis *types.DiagnosisReport) (*types.Plan, error) {
 		return plan, nil
 	}
 
+	// Nothing is left to remediate once sampling saw the deletion finish
+	if diagnosis.IsDeletionDone() {
+		plan.Notes = append(plan.Notes, "Deletion finished while sampling; nothing is left to remediate.")
+		return plan, nil
+	}
+
 	var actions []types.Action
 
 	// Level 0: Informational actions (always included)
 	actions = append(actions, p.infoActions(diagnosis)...)
 
-	// Level 1: Clean path actions if controller is available
-	if p.maxEscalation >= types.EscalationClean {
-		if hasAvailableController(diagnosis) {
+	// A self-deadlocked namespace has no clean path, explain the unblock order instead
+	if diagnosis.HasSelfDeadlock() {
+		plan.Notes = append(plan.Notes, p.selfDeadlockNotes(diagnosis)...)
+	}
+
+	// A deletion that is still making progress is slow, not stuck: never go past L1
+	maxEscalation := p.maxEscalation
+	if diagnosis.IsProgressing() && maxEscalation > types.EscalationClean {
+		maxEscalation = types.EscalationClean
+		plan.Notes = append(plan.Notes, fmt.Sprintf(
+			"Deletion is progressing at %.0f objects/min (ETA %s); not escalating past %s. Re-run once progress stalls.",
+			diagnosis.Progress.RatePerMinute, diagnosis.Progress.ETA, types.EscalationClean,
+		))
+	}
+
+	// Deleting what a controller may still be working on, such as the managed resources of GitOps
+	// objects or running Helm hooks, waits for L3
+	cascadeAllowed := maxEscalation >= types.EscalationCRD && p.allowForce
+
+	// Level 1: Revive stopped controllers, then clean path actions if a controller is available
+	if maxEscalation >= types.EscalationClean {
+		actions = append(actions, p.reviveActions(diagnosis)...)
+		// Helm waits on hook Jobs that will never complete
+		for _, release := range diagnosis.StuckHelmReleases() {
+			actions = append(actions, p.helmHookActions(release, false)...)
+			plan.Notes = append(plan.Notes, helmNote(release))
+			if note := runningHookNote(release); note != "" && !cascadeAllowed {
+				plan.Notes = append(plan.Notes, note)
+			}
+		}
+		if (hasAvailableController(diagnosis) || diagnosis.IsProgressing()) && !diagnosis.HasSelfDeadlock() {
 			actions = append(actions, p.cleanPathActions(diagnosis)...)
 		}
 	}
 
-	// Level 2: Finalizer removal actions for blockers
-	if p.maxEscalation >= types.EscalationFinalizer {
+	// GitOps objects whose managed resources are cascaded at L3 keep their finalizers until then
+	var cascading []types.Blocker
+
+	// Level 2: Finalizer removal actions for blockers that are actually stuck
+	if maxEscalation >= types.EscalationFinalizer {
+		skipped := 0
 		for _, blocker := range diagnosis.Blockers {
+			if !blocker.IsStuck() {
+				skipped++
+				continue
+			}
+			// Orphaning cloud infrastructure needs the same opt-in as force-finalizing, whichever
+			// action would remove the finalizer
+			if blocker.CloudResource != nil && blocker.CloudResource.Leaks() && !p.allowForce {
+				plan.Notes = append(plan.Notes, fmt.Sprintf(
+					"Skipped finalizer removal for %s: it would orphan %s. Re-run with --allow-force to remove it anyway.",
+					blocker.ResourceRef.String(), blocker.CloudResource.Description(),
+				))
+				continue
+			}
+			// Removing a GitOps finalizer first would orphan what its controller did not delete
+			if orphansManagedResources(blocker) {
+				switch {
+				case blocker.GitOps.ControllerState == types.ExternalControllerRunning:
+					plan.Notes = append(plan.Notes, fmt.Sprintf(
+						"Skipped finalizer removal for %s: its controller %s is running, so fix the error it reports instead of orphaning %d managed resource(s).",
+						blocker.ResourceRef.String(), blocker.GitOps.Controller, len(blocker.GitOps.CascadeCandidates()),
+					))
+				case blocker.GitOps.ControllerState != types.ExternalControllerNotRunning:
+					plan.Notes = append(plan.Notes, fmt.Sprintf(
+						"Skipped finalizer removal for %s: its controller could not be found, so %d managed resource(s) would be orphaned. Delete them or restore the controller, then re-run.",
+						blocker.ResourceRef.String(), len(blocker.GitOps.CascadeCandidates()),
+					))
+				case !cascadeAllowed:
+					plan.Notes = append(plan.Notes, fmt.Sprintf(
+						"Skipped finalizer removal for %s: it would orphan %d managed resource(s). Re-run with --max-escalation=3 --allow-force to delete them first.",
+						blocker.ResourceRef.String(), len(blocker.GitOps.CascadeCandidates()),
+					))
+				default:
+					cascading = append(cascading, blocker)
+				}
+				continue
+			}
+			// Checked before GC issues: the pod's owner reference to its deleted Job dangles too
+			if blocker.JobTracking != nil && blocker.JobTracking.CanRemoveFinalizer() {
+				actions = append(actions, p.jobTrackingActions(blocker, diagnosis.Target.Namespace)...)
+				continue
+			}
+			if issues := diagnosis.GCIssuesFor(blocker.ResourceRef); len(issues) > 0 {
+				actions = append(actions, p.gcActions(blocker, issues, diagnosis.Target.Namespace)...)
+				continue
+			}
 			actions = append(actions, p.finalizerRemovalAction(blocker, diagnosis.Target.Namespace))
 		}
+		// The namespace's own metadata finalizers are independent of spec.finalizers
+		if diagnosis.HasNamespaceMetadataFinalizers() {
+			// The tool that added them may still be cleaning up; only remove them once it had time to
+			switch {
+			case !diagnosis.TargetStuck:
+				plan.Notes = append(plan.Notes, fmt.Sprintf(
+					"Namespace metadata finalizers %s are left in place; the namespace has been terminating for %s, within --stuck-after.",
+					strings.Join(diagnosis.Finalizers, ", "), diagnosis.TerminatingFor,
+				))
+			case len(diagnosis.Blockers) > 0:
+				plan.Notes = append(plan.Notes, fmt.Sprintf(
+					"Namespace metadata finalizers %s are left in place while %d object(s) with finalizers remain; re-run once they are gone.",
+					strings.Join(diagnosis.Finalizers, ", "), len(diagnosis.Blockers),
+				))
+			default:
+				actions = append(actions, p.namespaceFinalizerAction(diagnosis))
+			}
+		}
+		if skipped > 0 {
+			plan.Notes = append(plan.Notes, fmt.Sprintf(
+				"Skipped finalizer removal for %d blocker(s) that are Pending or Healthy; they may still clear on their own.", skipped,
+			))
+		}
+		for _, b := range diagnosis.ExternalBlockers() {
+			if !b.IsStuck() {
+				continue
+			}
+			note := fmt.Sprintf("%s owns %s; removing %s does not delete it.", b.ResourceRef.String(), b.External.Description(), b.External.Finalizer)
+			if b.External.ControllerState == types.ExternalControllerRunning {
+				note += fmt.Sprintf(" Its controller %s is running, so fix the cloud error it reports instead.", b.External.Controller)
+			}
+			plan.Notes = append(plan.Notes, note)
+		}
+		for _, b := range diagnosis.CloudResourceBlockers() {
+			if !b.IsStuck() || !b.CloudResource.Leaks() {
+				continue
+			}
+			if b.CloudResource.ProviderState == types.ExternalControllerRunning {
+				plan.Notes = append(plan.Notes, fmt.Sprintf(
+					"%s is managed by %s, which is running; fix the cloud error in the object's conditions instead of orphaning %s.",
+					b.ResourceRef.String(), b.CloudResource.Provider, b.CloudResource.Description(),
+				))
+			}
+		}
+		for _, b := range diagnosis.Blockers {
+			if b.IsStuck() && b.GitOps != nil {
+				plan.Notes = append(plan.Notes, gitOpsNote(b))
+			}
+			if b.IsStuck() && b.JobTracking != nil && !b.JobTracking.CanRemoveFinalizer() {
+				plan.Notes = append(plan.Notes, fmt.Sprintf(
+					"%s is still tracked by Job %s; removing its finalizer makes the Job miscount it.",
+					b.ResourceRef.String(), b.JobTracking.Job,
+				))
+			}
+		}
+		for _, ap := range diagnosis.AdmissionPolicies {
+			plan.Notes = append(plan.Notes, fmt.Sprintf(
+				"ValidatingAdmissionPolicy %q (binding %q) validates updates of %d blocker(s) and may reject finalizer removal; run apply with --dry-run=server first.",
+				ap.Policy, ap.Binding, len(ap.Blockers),
+			))
+		}
+		for _, w := range diagnosis.WebhookIssues {
+			if w.RejectsUpdates() {
+				plan.Notes = append(plan.Notes, fmt.Sprintf(
+					"Webhook %q (%s) is rejecting updates; finalizer patches will fail until it is restored.",
+					w.WebhookName, w.Name,
+				))
+				continue
+			}
+			plan.Notes = append(plan.Notes, fmt.Sprintf(
+				"Webhook %q (%s) is unhealthy and may reject updates; run apply with --dry-run=server first.",
+				w.WebhookName, w.Name,
+			))
+		}
 	}
 
 	// Level 3: CRD finalizer removal (requires force)
-	if p.maxEscalation >= types.EscalationCRD && p.allowForce {
-		if diagnosis.TargetType == types.TargetTypeCRD {
+	if cascadeAllowed {
+		// Helm hooks that are still running
+		for _, release := range diagnosis.StuckHelmReleases() {
+			actions = append(actions, p.helmHookActions(release, true)...)
+		}
+		// Delete live managed resources of GitOps objects whose controller is down, then their finalizers
+		for _, blocker := range cascading {
+			actions = append(actions, p.cascadeActions(blocker)...)
+			action := p.finalizerRemovalAction(blocker, diagnosis.Target.Namespace)
+			action.EscalationLevel = types.EscalationCRD
+			action.RequiresForce = true
+			actions = append(actions, action)
+		}
+		switch {
+		case diagnosis.TargetType == types.TargetTypeCRD && diagnosis.HasConversionFailure():
+			// Removing the CRD finalizer would orphan instances that become readable without conversion
+			actions = append(actions, p.disableConversionAction(diagnosis.Target))
+			plan.Notes = append(plan.Notes, fmt.Sprintf(
+				"Instances cannot be read through the conversion webhook. After conversion is switched to None, re-run `unstuck plan crd %s` to remove their finalizers; objects stored at other versions are served without schema conversion.",
+				diagnosis.Target.Name,
+			))
+		case diagnosis.TargetType == types.TargetTypeCRD:
 			actions = append(actions, p.crdFinalizerAction(diagnosis.Target))
 		}
+	} else if diagnosis.HasConversionFailure() {
+		plan.Notes = append(plan.Notes, "Instances cannot be read through the conversion webhook. Restore it, or re-run with --max-escalation=3 --allow-force to switch conversion to None.")
 	}
 
-	// Level 4: Force-finalize namespace (requires force)
-	if p.maxEscalation >= types.EscalationForce && p.allowForce {
+	// Corrupt objects can only be removed by an unsafe delete, spell out what that means
+	if diagnosis.HasCorruptObjects() {
+		plan.Notes = append(plan.Notes, p.corruptObjectNotes(diagnosis, maxEscalation)...)
+	}
+
+	// Level 4: Unsafe-delete corrupt objects and force-finalize namespace (requires force)
+	if maxEscalation >= types.EscalationForce && p.allowForce {
+		for _, obj := range diagnosis.CorruptObjects {
+			if obj.IsIdentified() {
+				actions = append(actions, p.unsafeDeleteAction(obj))
+			}
+		}
 		if diagnosis.TargetType == types.TargetTypeNamespace && diagnosis.HasDiscoveryFailures() {
 			actions = append(actions, p.forceFinalize(diagnosis.Target.Name))
 		}
@@ -88,6 +279,7 @@ func (p *Planner) Plan(diagnosis *types.DiagnosisReport) (*types.Plan, error) {
 	plan.Actions = orderedActions
 	plan.RiskLevel = CalculateRiskLevel(orderedActions)
 	plan.Commands = GenerateCommands(orderedActions)
+	plan.Impact = CalculateImpact(diagnosis, orderedActions)
 
 	return plan, nil
 }
@@ -102,7 +294,7 @@ func (p *Planner) infoActions(diagnosis *types.DiagnosisReport) []types.Action {
 		EscalationLevel: types.EscalationInfo,
 		Description:     fmt.Sprintf("Inspect %s %q", diagnosis.TargetType, diagnosis.Target.Name),
 		Target:          diagnosis.Target,
-		Operation:       "inspect",
+		Operation:       types.OpInspect,
 		Risk:            types.RiskNone,
 		RequiresForce:   false,
 		ExpectedResult:  "Gather current state information",
@@ -115,7 +307,7 @@ func (p *Planner) infoActions(diagnosis *types.DiagnosisReport) []types.Action {
 			EscalationLevel: types.EscalationInfo,
 			Description:     fmt.Sprintf("List %d blocking resources", len(diagnosis.Blockers)),
 			Target:          diagnosis.Target,
-			Operation:       "list-blockers",
+			Operation:       types.OpListBlockers,
 			Risk:            types.RiskNone,
 			RequiresForce:   false,
 			ExpectedResult:  "Enumerate resources preventing deletion",
@@ -135,7 +327,7 @@ func (p *Planner) cleanPathActions(diagnosis *types.DiagnosisReport) []types.Act
 		EscalationLevel: types.EscalationClean,
 		Description:     "Wait for controller to process finalizers",
 		Target:          diagnosis.Target,
-		Operation:       "wait-controller",
+		Operation:       types.OpWaitController,
 		Risk:            types.RiskLow,
 		RequiresForce:   false,
 		ExpectedResult:  "Controller removes finalizers naturally",
@@ -144,30 +336,180 @@ func (p *Planner) cleanPathActions(diagnosis *types.DiagnosisReport) []types.Act
 	return actions
 }
 
+// reviveActions returns actions that bring back controllers which exist but are not reconciling,
+// so they can process their own finalizers instead of having them stripped
+func (p *Planner) reviveActions(diagnosis *types.DiagnosisReport) []types.Action {
+	var actions []types.Action
+
+	for _, ctrl := range diagnosis.Controllers {
+		if !ctrl.IsRevivable() {
+			continue
+		}
+		// New pods cannot be created in a namespace that is being deleted
+		if diagnosis.TargetType == types.TargetTypeNamespace && ctrl.Namespace == diagnosis.Target.Name {
+			continue
+		}
+
+		target := types.ResourceRef{
+			Kind:       ctrl.Kind,
+			APIVersion: "apps/v1",
+			Namespace:  ctrl.Namespace,
+			Name:       ctrl.Name,
+		}
+		workload := strings.ToLower(ctrl.Kind) + "/" + ctrl.Name
+
+		action := types.Action{
+			Type:            types.ActionRevive,
+			EscalationLevel: types.EscalationClean,
+			Target:          target,
+			Risk:            types.RiskLow,
+			RequiresForce:   false,
+			ExpectedResult:  "Controller becomes ready and removes its finalizers",
+			Timeout:         5 * time.Minute,
+			WaitFor:         blockersOwnedBy(ctrl, diagnosis.Blockers),
+		}
+		if ctrl.Replicas == 0 {
+			action.Description = fmt.Sprintf("Scale up controller %s", target.String())
+			action.Operation = types.OpScaleUp
+			action.Command = fmt.Sprintf("kubectl scale %s -n %s --replicas=1", workload, ctrl.Namespace)
+		} else {
+			action.Description = fmt.Sprintf("Restart controller %s", target.String())
+			action.Operation = types.OpRolloutRestart
+			action.Command = fmt.Sprintf("kubectl rollout restart %s -n %s", workload, ctrl.Namespace)
+		}
+		actions = append(actions, action)
+	}
+
+	return actions
+}
+
+// blockersOwnedBy returns the blockers carrying a finalizer the controller is responsible for
+func blockersOwnedBy(ctrl types.ControllerStatus, blockers []types.Blocker) []types.ResourceRef {
+	var refs []types.ResourceRef
+	owned := make(map[string]bool, len(ctrl.Finalizers))
+	for _, f := range ctrl.Finalizers {
+		owned[f] = true
+	}
+	for _, b := range blockers {
+		for _, f := range b.Finalizers {
+			if owned[f] {
+				refs = append(refs, b.ResourceRef)
+				break
+			}
+		}
+	}
+	return refs
+}
+
+// selfDeadlockNotes explains why no clean path exists and which objects must be unblocked first
+func (p *Planner) selfDeadlockNotes(diagnosis *types.DiagnosisReport) []string {
+	deadlock := diagnosis.SelfDeadlock
+
+	var controllers []string
+	for _, c := range deadlock.Controllers {
+		controllers = append(controllers, c.Kind+"/"+c.Name)
+	}
+
+	// Only stuck blockers get a finalizer removal action, list the others separately
+	status := make(map[types.ResourceRef]types.Blocker, len(diagnosis.Blockers))
+	for _, b := range diagnosis.Blockers {
+		status[b.ResourceRef] = b
+	}
+	var stuck, waiting []types.ResourceRef
+	for _, ref := range deadlock.Blockers {
+		if b, ok := status[ref]; ok && !b.IsStuck() {
+			waiting = append(waiting, ref)
+			continue
+		}
+		stuck = append(stuck, ref)
+	}
+
+	notes := []string{
+		fmt.Sprintf(
+			"No clean path exists: %s runs inside namespace %q, which is being deleted, so finalizers %s will never be processed.",
+			strings.Join(controllers, ", "), diagnosis.Target.Name, strings.Join(deadlock.Finalizers, ", "),
+		),
+		fmt.Sprintf("Unblock in this order: (1) remove finalizers from the %d stuck objects below, (2) the namespace controller then deletes the remaining content and finalizes the namespace.", len(stuck)),
+	}
+	for i, ref := range stuck {
+		notes = append(notes, fmt.Sprintf("  %d. %s", i+1, ref.String()))
+	}
+	if len(waiting) > 0 {
+		names := make([]string, 0, len(waiting))
+		for _, ref := range waiting {
+			names = append(names, ref.String())
+		}
+		notes = append(notes, fmt.Sprintf(
+			"%d more object(s) with these finalizers are Pending or Healthy and are not in the plan yet: %s. Re-run once they have been terminating longer than --stuck-after.",
+			len(waiting), strings.Join(names, ", "),
+		))
+	}
+
+	if p.maxEscalation < types.EscalationFinalizer {
+		notes = append(notes, "Re-run with --max-escalation=2 to include the finalizer removal actions.")
+	}
+	notes = append(notes, "To clean up external state properly instead, reinstall the controller in another namespace before removing finalizers.")
+
+	return notes
+}
+
 // finalizerRemovalAction creates an action to remove finalizers from a blocker
 func (p *Planner) finalizerRemovalAction(blocker types.Blocker, namespace string) types.Action {
+	target := blockerTarget(blocker, namespace)
+
+	action := types.Action{
+		Type:            types.ActionPatch,
+		EscalationLevel: types.EscalationFinalizer,
+		Description:     fmt.Sprintf("Remove finalizers from %s", target.String()),
+		Target:          target,
+		Operation:       types.OpRemoveFinalizers,
+		Command:         generatePatchCommand(target),
+		Risk:            types.RiskMedium,
+		RequiresForce:   false,
+		ExpectedResult:  "Finalizers removed, object deletion proceeds",
+		External:        blocker.External,
+	}
+	if blocker.External != nil {
+		action.Risk = ActionRisk(action)
+		action.ExpectedResult = fmt.Sprintf("Finalizers removed, %s is leaked and must be deleted in the cloud provider", blocker.External.Description())
+	}
+	if blocker.CloudResource != nil && blocker.CloudResource.Leaks() {
+		action.CloudResource = blocker.CloudResource
+		action.Risk = ActionRisk(action)
+		action.RequiresForce = true
+		action.ExpectedResult = fmt.Sprintf("Finalizers removed, %s is orphaned and must be deleted in the cloud provider", blocker.CloudResource.Description())
+	}
+	return action
+}
+
+// blockerTarget returns the blocker's reference, defaulting its namespace to the target's
+func blockerTarget(blocker types.Blocker, namespace string) types.ResourceRef {
 	ns := blocker.Namespace
 	if ns == "" {
 		ns = namespace
 	}
 
-	target := types.ResourceRef{
+	return types.ResourceRef{
 		Kind:       blocker.Kind,
 		APIVersion: blocker.APIVersion,
 		Namespace:  ns,
 		Name:       blocker.Name,
 	}
+}
 
+// namespaceFinalizerAction creates an action to remove the metadata finalizers of a namespace.
+// spec.finalizers stay in place and are only cleared by the namespace controller or force-finalize.
+func (p *Planner) namespaceFinalizerAction(diagnosis *types.DiagnosisReport) types.Action {
 	return types.Action{
 		Type:            types.ActionPatch,
 		EscalationLevel: types.EscalationFinalizer,
-		Description:     fmt.Sprintf("Remove finalizers from %s", target.String()),
-		Target:          target,
-		Operation:       "remove-finalizers",
-		Command:         generatePatchCommand(target),
+		Description:     fmt.Sprintf("Remove metadata finalizers %s from namespace %s", strings.Join(diagnosis.Finalizers, ", "), diagnosis.Target.Name),
+		Target:          diagnosis.Target,
+		Operation:       types.OpRemoveNamespaceFinalizers,
+		Command:         generatePatchCommand(diagnosis.Target),
 		Risk:            types.RiskMedium,
 		RequiresForce:   false,
-		ExpectedResult:  "Finalizers removed, object deletion proceeds",
+		ExpectedResult:  "Namespace metadata finalizers removed, spec.finalizers left to the namespace controller",
 	}
 }
 
@@ -182,7 +524,7 @@ func (p *Planner) crdFinalizerAction(target types.ResourceRef) types.Action {
 			APIVersion: "apiextensions.k8s.io/v1",
 			Name:       target.Name,
 		},
-		Operation:      "remove-crd-finalizer",
+		Operation:      types.OpRemoveCRDFinalizer,
 		Command:        generateCRDPatchCommand(target.Name),
 		Risk:           types.RiskHigh,
 		RequiresForce:  true,
@@ -190,6 +532,69 @@ func (p *Planner) crdFinalizerAction(target types.ResourceRef) types.Action {
 	}
 }
 
+// disableConversionAction creates an action to switch a CRD's conversion strategy to None
+func (p *Planner) disableConversionAction(target types.ResourceRef) types.Action {
+	return types.Action{
+		Type:            types.ActionPatch,
+		EscalationLevel: types.EscalationCRD,
+		Description:     fmt.Sprintf("Switch CRD %s to None conversion", target.Name),
+		Target: types.ResourceRef{
+			Kind:       "CustomResourceDefinition",
+			APIVersion: "apiextensions.k8s.io/v1",
+			Name:       target.Name,
+		},
+		Operation:      types.OpDisableConversionWebhook,
+		Command:        generateDisableConversionCommand(target.Name),
+		Risk:           types.RiskHigh,
+		RequiresForce:  true,
+		ExpectedResult: "Instances readable without the conversion webhook",
+	}
+}
+
+// unsafeDeleteAction creates an action to delete an object that cannot be read from storage
+func (p *Planner) unsafeDeleteAction(obj types.CorruptObject) types.Action {
+	return types.Action{
+		Type:            types.ActionDelete,
+		EscalationLevel: types.EscalationForce,
+		Description:     fmt.Sprintf("Unsafe-delete corrupt object %s", obj.ResourceRef.String()),
+		Target:          obj.ResourceRef,
+		Operation:       types.OpUnsafeDelete,
+		Command:         generateUnsafeDeleteCommand(obj),
+		Risk:            types.RiskCritical,
+		RequiresForce:   true,
+		ExpectedResult:  "Object removed from storage without running finalizers or admission",
+	}
+}
+
+// corruptObjectNotes warns about unsafe deletion and explains how to include it in the plan
+func (p *Planner) corruptObjectNotes(diagnosis *types.DiagnosisReport, maxEscalation types.EscalationLevel) []string {
+	notes := []string{
+		fmt.Sprintf(
+			"%d object(s) cannot be read from storage. Unsafe deletion removes them with ignoreStoreReadErrorWithClusterBreakingPotential: finalizers and preconditions are skipped, and workloads relying on them may break. Back up etcd first.",
+			len(diagnosis.CorruptObjects),
+		),
+		"Unsafe deletion requires Kubernetes v1.32+ with the AllowUnsafeMalformedObjectDeletion feature gate and the unsafe-delete-ignore-read-errors permission; apply checks the server version first.",
+	}
+
+	unidentified := 0
+	for _, obj := range diagnosis.CorruptObjects {
+		if !obj.IsIdentified() {
+			unidentified++
+		}
+	}
+	if unidentified > 0 {
+		notes = append(notes, fmt.Sprintf(
+			"The API server did not name %d corrupt object(s); find their keys in etcd or the API server logs and delete them manually.", unidentified,
+		))
+	}
+
+	if maxEscalation < types.EscalationForce || !p.allowForce {
+		notes = append(notes, "Re-run with --max-escalation=4 --allow-force to include the unsafe delete actions.")
+	}
+
+	return notes
+}
+
 // forceFinalize creates an action to force-finalize a namespace
 func (p *Planner) forceFinalize(namespaceName string) types.Action {
 	return types.Action{
@@ -201,7 +606,7 @@ func (p *Planner) forceFinalize(namespaceName string) types.Action {
 			APIVersion: "v1",
 			Name:       namespaceName,
 		},
-		Operation:      "force-finalize",
+		Operation:      types.OpForceFinalize,
 		Command:        generateForceFinalize(namespaceName),
 		Risk:           types.RiskCritical,
 		RequiresForce:  true,
@@ -209,10 +614,11 @@ func (p *Planner) forceFinalize(namespaceName string) types.Action {
 	}
 }
 
-// hasAvailableController checks if there's an available controller for the diagnosis
+// hasAvailableController checks if a controller is actually reconciling the diagnosis target
+// A ready workload with a stale leader-election lease does not count
 func hasAvailableController(diagnosis *types.DiagnosisReport) bool {
 	for _, ctrl := range diagnosis.Controllers {
-		if ctrl.Available && ctrl.Ready {
+		if ctrl.IsReconciling() {
 			return true
 		}
 	}
@@ -234,11 +640,26 @@ func generatePatchCommand(target types.ResourceRef) string {
 	return cmd
 }
 
+// generateUnsafeDeleteCommand generates a kubectl delete command that ignores storage read errors
+func generateUnsafeDeleteCommand(obj types.CorruptObject) string {
+	cmd := fmt.Sprintf("kubectl delete %s %s", obj.Resource, obj.Name)
+	if obj.Namespace != "" {
+		cmd += fmt.Sprintf(" -n %s", obj.Namespace)
+	}
+	cmd += " --ignore-store-read-error-with-cluster-breaking-potential=true"
+	return cmd
+}
+
 // generateCRDPatchCommand generates a kubectl patch command for CRD finalizer removal
 func generateCRDPatchCommand(crdName string) string {
 	return fmt.Sprintf("kubectl patch crd %s -p '{\"metadata\":{\"finalizers\":null}}' --type=merge", crdName)
 }
 
+// generateDisableConversionCommand generates a kubectl command that drops a CRD's conversion webhook
+func generateDisableConversionCommand(crdName string) string {
+	return fmt.Sprintf("kubectl patch crd %s -p '{\"spec\":{\"conversion\":{\"strategy\":\"None\",\"webhook\":null}}}' --type=merge", crdName)
+}
+
 // generateForceFinalize generates the kubectl command sequence for force-finalizing a namespace
 func generateForceFinalize(namespaceName string) string {
 	return fmt.Sprintf(
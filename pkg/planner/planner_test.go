@@ -1,11 +1,14 @@
 package planner
 
 import (
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/sozercan/unstuck/pkg/types"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 func TestNewPlanner(t *testing.T) {
@@ -238,6 +241,545 @@ func TestPlanner_Plan_NoForceWithoutFlag(t *testing.T) {
 	}
 }
 
+func TestPlanner_Plan_NamespaceMetadataFinalizers(t *testing.T) {
+	p := NewPlanner(Options{MaxEscalation: types.EscalationFinalizer})
+
+	diagnosis := &types.DiagnosisReport{
+		Target: types.ResourceRef{
+			Kind:       "Namespace",
+			Name:       "team-a",
+			APIVersion: "v1",
+		},
+		TargetType:     types.TargetTypeNamespace,
+		Status:         "Terminating",
+		TargetStuck:    true,
+		Finalizers:     []string{"controller.cattle.io/namespace-auth"},
+		SpecFinalizers: []string{"kubernetes"},
+	}
+
+	plan, err := p.Plan(diagnosis)
+	require.NoError(t, err)
+
+	var found *types.Action
+	for i, action := range plan.Actions {
+		assert.NotEqual(t, types.EscalationForce, action.EscalationLevel)
+		if action.Operation == "remove-namespace-finalizers" {
+			found = &plan.Actions[i]
+		}
+	}
+	require.NotNil(t, found, "should plan removal of namespace metadata finalizers")
+	assert.Equal(t, types.EscalationFinalizer, found.EscalationLevel)
+	assert.Equal(t, diagnosis.Target, found.Target)
+	assert.False(t, found.RequiresForce)
+	assert.Contains(t, found.Command, `{"metadata":{"finalizers":null}}`)
+	assert.Contains(t, found.Description, "controller.cattle.io/namespace-auth")
+}
+
+func TestPlanner_Plan_NamespaceMetadataFinalizers_NotYet(t *testing.T) {
+	p := NewPlanner(Options{MaxEscalation: types.EscalationFinalizer})
+
+	tests := []struct {
+		name      string
+		diagnosis *types.DiagnosisReport
+		note      string
+	}{
+		{
+			name: "within stuck threshold",
+			diagnosis: &types.DiagnosisReport{
+				Target:         types.ResourceRef{Kind: "Namespace", Name: "team-a", APIVersion: "v1"},
+				TargetType:     types.TargetTypeNamespace,
+				Status:         "Terminating",
+				TerminatingFor: "2m",
+				Finalizers:     []string{"controller.cattle.io/namespace-auth"},
+			},
+			note: "within --stuck-after",
+		},
+		{
+			name: "blockers remain",
+			diagnosis: &types.DiagnosisReport{
+				Target:      types.ResourceRef{Kind: "Namespace", Name: "team-a", APIVersion: "v1"},
+				TargetType:  types.TargetTypeNamespace,
+				Status:      "Terminating",
+				TargetStuck: true,
+				Finalizers:  []string{"controller.cattle.io/namespace-auth"},
+				Blockers: []types.Blocker{{
+					ResourceRef: types.ResourceRef{Kind: "ConfigMap", APIVersion: "v1", Namespace: "team-a", Name: "cm"},
+					Finalizers:  []string{"example.com/cleanup"},
+					Status:      types.BlockerStatusStuck,
+				}},
+			},
+			note: "while 1 object(s) with finalizers remain",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan, err := p.Plan(tt.diagnosis)
+			require.NoError(t, err)
+
+			for _, action := range plan.Actions {
+				assert.NotEqual(t, "remove-namespace-finalizers", action.Operation)
+			}
+			assert.Contains(t, strings.Join(plan.Notes, "\n"), tt.note)
+		})
+	}
+}
+
+func TestPlanner_Plan_ExternalLoadBalancer(t *testing.T) {
+	p := NewPlanner(Options{MaxEscalation: types.EscalationFinalizer})
+
+	diagnosis := &types.DiagnosisReport{
+		Target:     types.ResourceRef{Kind: "Namespace", Name: "apps"},
+		TargetType: types.TargetTypeNamespace,
+		Status:     "Terminating",
+		Blockers: []types.Blocker{{
+			ResourceRef: types.ResourceRef{Kind: "Service", APIVersion: "v1", Namespace: "apps", Name: "web"},
+			Finalizers:  []string{"service.kubernetes.io/load-balancer-cleanup"},
+			Status:      types.BlockerStatusStuck,
+			External: &types.ExternalResource{
+				Type:            types.ExternalLoadBalancer,
+				Finalizer:       "service.kubernetes.io/load-balancer-cleanup",
+				Controller:      "cloud-controller-manager",
+				ControllerState: types.ExternalControllerRunning,
+				Addresses:       []string{"203.0.113.10"},
+			},
+		}},
+	}
+
+	plan, err := p.Plan(diagnosis)
+	require.NoError(t, err)
+
+	var found bool
+	for _, action := range plan.Actions {
+		if action.Operation != "remove-finalizers" {
+			continue
+		}
+		found = true
+		assert.Equal(t, types.RiskHigh, action.Risk)
+		require.NotNil(t, action.External)
+		assert.Contains(t, action.ExpectedResult, "203.0.113.10")
+	}
+	assert.True(t, found)
+	assert.Equal(t, types.RiskHigh, plan.RiskLevel)
+	require.NotEmpty(t, plan.Notes)
+	assert.Contains(t, plan.Notes[0], "cloud-controller-manager is running")
+}
+
+func TestPlanner_Plan_CloudResource(t *testing.T) {
+	bucket := func(retained bool) *types.DiagnosisReport {
+		return &types.DiagnosisReport{
+			Target:     types.ResourceRef{Kind: "CustomResourceDefinition", Name: "buckets.s3.aws.upbound.io"},
+			TargetType: types.TargetTypeCRD,
+			Status:     "Terminating",
+			Blockers: []types.Blocker{{
+				ResourceRef: types.ResourceRef{Kind: "Bucket", APIVersion: "s3.aws.upbound.io/v1beta1", Name: "assets"},
+				Finalizers:  []string{"finalizer.managedresource.crossplane.io"},
+				Status:      types.BlockerStatusStuck,
+				CloudResource: &types.CloudResource{
+					Family:        types.CloudCrossplane,
+					Finalizer:     "finalizer.managedresource.crossplane.io",
+					ExternalName:  "acme-assets",
+					Identifiers:   []string{"arn:aws:s3:::acme-assets"},
+					Provider:      "Provider/provider-aws-s3",
+					ProviderState: types.ExternalControllerNotRunning,
+					Retained:      retained,
+				},
+			}},
+		}
+	}
+	removals := func(plan *types.Plan) []types.Action {
+		var actions []types.Action
+		for _, action := range plan.Actions {
+			if action.Operation == "remove-finalizers" {
+				actions = append(actions, action)
+			}
+		}
+		return actions
+	}
+
+	// Without --allow-force the finalizer is left alone
+	plan, err := NewPlanner(Options{MaxEscalation: types.EscalationFinalizer}).Plan(bucket(false))
+	require.NoError(t, err)
+	assert.Empty(t, removals(plan))
+	require.NotEmpty(t, plan.Notes)
+	assert.Contains(t, plan.Notes[0], "would orphan Crossplane resource acme-assets (arn:aws:s3:::acme-assets)")
+
+	plan, err = NewPlanner(Options{MaxEscalation: types.EscalationFinalizer, AllowForce: true}).Plan(bucket(false))
+	require.NoError(t, err)
+	actions := removals(plan)
+	require.Len(t, actions, 1)
+	assert.Equal(t, types.RiskHigh, actions[0].Risk)
+	assert.True(t, actions[0].RequiresForce)
+	require.NotNil(t, actions[0].CloudResource)
+	assert.Contains(t, actions[0].ExpectedResult, "arn:aws:s3:::acme-assets")
+
+	// A deletion policy that keeps the bucket makes removal an ordinary L2 action
+	plan, err = NewPlanner(Options{MaxEscalation: types.EscalationFinalizer}).Plan(bucket(true))
+	require.NoError(t, err)
+	actions = removals(plan)
+	require.Len(t, actions, 1)
+	assert.Equal(t, types.RiskMedium, actions[0].Risk)
+	assert.False(t, actions[0].RequiresForce)
+
+	// A garbage collector issue on the same object does not bypass --allow-force
+	withGC := bucket(false)
+	withGC.Blockers[0].Finalizers = append(withGC.Blockers[0].Finalizers, "foregroundDeletion")
+	withGC.GCIssues = []types.GCIssue{{Type: types.GCIssueForegroundFinalizer, Object: withGC.Blockers[0].ResourceRef, Finalizer: "foregroundDeletion"}}
+	plan, err = NewPlanner(Options{MaxEscalation: types.EscalationFinalizer}).Plan(withGC)
+	require.NoError(t, err)
+	for _, action := range plan.Actions {
+		assert.Less(t, action.EscalationLevel, types.EscalationFinalizer, "unexpected %s", action.Operation)
+	}
+	assert.Contains(t, strings.Join(plan.Notes, "\n"), "Re-run with --allow-force")
+}
+
+func TestPlanner_Plan_JobTrackingFinalizer(t *testing.T) {
+	p := NewPlanner(Options{MaxEscalation: types.EscalationFinalizer})
+
+	pod := func(name string, jobExists bool, finalizers ...string) types.Blocker {
+		return types.Blocker{
+			ResourceRef: types.ResourceRef{Kind: "Pod", APIVersion: "v1", Namespace: "batch", Name: name},
+			Finalizers:  finalizers,
+			Status:      types.BlockerStatusStuck,
+			JobTracking: &types.JobTracking{Job: "nightly", JobExists: jobExists},
+		}
+	}
+	diagnosis := &types.DiagnosisReport{
+		Target:     types.ResourceRef{Kind: "Namespace", Name: "batch"},
+		TargetType: types.TargetTypeNamespace,
+		Status:     "Terminating",
+		Blockers: []types.Blocker{
+			pod("orphaned", false, "batch.kubernetes.io/job-tracking"),
+			pod("tracked", true, "batch.kubernetes.io/job-tracking"),
+			pod("mixed", false, "batch.kubernetes.io/job-tracking", "example.com/cleanup"),
+		},
+		GCIssues: []types.GCIssue{{
+			Type:     types.GCIssueDanglingOwner,
+			Object:   types.ResourceRef{Kind: "Pod", APIVersion: "v1", Namespace: "batch", Name: "orphaned"},
+			OwnerUID: "gone-uid",
+		}},
+	}
+
+	plan, err := p.Plan(diagnosis)
+	require.NoError(t, err)
+
+	ops := make(map[string]types.Action)
+	for _, action := range plan.Actions {
+		if action.Type == types.ActionPatch {
+			ops[action.Target.Name] = action
+		}
+	}
+	require.Len(t, ops, 3)
+
+	assert.Equal(t, "remove-job-tracking-finalizer", ops["orphaned"].Operation)
+	assert.Equal(t, types.RiskLow, ops["orphaned"].Risk)
+	assert.Equal(t, "batch.kubernetes.io/job-tracking", ops["orphaned"].Finalizer)
+	assert.Contains(t, ops["orphaned"].Command, `"op":"test"`)
+
+	assert.Equal(t, "remove-finalizers", ops["tracked"].Operation)
+	assert.Equal(t, "remove-finalizers", ops["mixed"].Operation)
+
+	assert.Contains(t, strings.Join(plan.Notes, "\n"), "still tracked by Job nightly")
+}
+
+func TestPlanner_Plan_GitOpsCascade(t *testing.T) {
+	app := func(state types.ExternalControllerState) *types.DiagnosisReport {
+		return &types.DiagnosisReport{
+			Target:     types.ResourceRef{Kind: "Namespace", Name: "argocd"},
+			TargetType: types.TargetTypeNamespace,
+			Status:     "Terminating",
+			Blockers: []types.Blocker{{
+				ResourceRef: types.ResourceRef{Kind: "Application", APIVersion: "argoproj.io/v1alpha1", Namespace: "argocd", Name: "web"},
+				Finalizers:  []string{"resources-finalizer.argocd.argoproj.io"},
+				Status:      types.BlockerStatusStuck,
+				GitOps: &types.GitOpsInfo{
+					Tool:            types.GitOpsArgoCD,
+					Finalizer:       "resources-finalizer.argocd.argoproj.io",
+					Controller:      "argocd/argocd-application-controller",
+					ControllerState: state,
+					InCluster:       true,
+					Prune:           true,
+					ManagedCount:    2,
+					Remaining: []types.ManagedResource{
+						{ResourceRef: types.ResourceRef{Kind: "Deployment", APIVersion: "apps/v1", Namespace: "apps", Name: "web"}},
+						{ResourceRef: types.ResourceRef{Kind: "Namespace", APIVersion: "v1", Name: "apps"}},
+					},
+				},
+			}},
+		}
+	}
+
+	operations := func(plan *types.Plan) []string {
+		var ops []string
+		for _, action := range plan.Actions {
+			ops = append(ops, action.Operation)
+		}
+		return ops
+	}
+
+	// Deleting live workloads needs --allow-force; without it the finalizer stays
+	p := NewPlanner(Options{MaxEscalation: types.EscalationFinalizer})
+	plan, err := p.Plan(app(types.ExternalControllerNotRunning))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"inspect", "list-blockers"}, operations(plan))
+	assert.Contains(t, strings.Join(plan.Notes, "\n"), "Re-run with --max-escalation=3 --allow-force to delete them first")
+
+	p = NewPlanner(Options{MaxEscalation: types.EscalationCRD, AllowForce: true})
+	plan, err = p.Plan(app(types.ExternalControllerNotRunning))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"inspect", "list-blockers", "cascade-delete", "remove-finalizers"}, operations(plan))
+	cascade := plan.Actions[2]
+	assert.Equal(t, types.ActionDelete, cascade.Type)
+	assert.Equal(t, types.EscalationCRD, cascade.EscalationLevel)
+	assert.Equal(t, types.RiskHigh, cascade.Risk)
+	assert.True(t, cascade.RequiresForce)
+	assert.Equal(t, "web", cascade.Target.Name)
+	assert.Equal(t, "kubectl delete Deployment web -n apps --wait=false", cascade.Command)
+	assert.Equal(t, types.EscalationCRD, plan.Actions[3].EscalationLevel)
+	assert.Contains(t, strings.Join(plan.Notes, "\n"), "1 managed resource(s) are deleted at Level 3")
+
+	// A controller that could not be found may still be running elsewhere: never cascade
+	plan, err = p.Plan(app(types.ExternalControllerUnknown))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"inspect", "list-blockers"}, operations(plan))
+	assert.Contains(t, strings.Join(plan.Notes, "\n"), "its controller could not be found")
+
+	// A running controller does its own cascade, and removing the finalizer would stop it
+	plan, err = p.Plan(app(types.ExternalControllerRunning))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"inspect", "list-blockers"}, operations(plan))
+	assert.Contains(t, strings.Join(plan.Notes, "\n"), "Skipped finalizer removal for Application/argocd/web")
+	assert.Contains(t, strings.Join(plan.Notes, "\n"), "argocd/argocd-application-controller is running")
+}
+
+func TestPlanner_Plan_HelmHooks(t *testing.T) {
+	hook := func(name string, failed bool) types.HelmHook {
+		return types.HelmHook{
+			ResourceRef: types.ResourceRef{Kind: "Job", APIVersion: "batch/v1", Namespace: "apps", Name: name},
+			Events:      []string{"pre-delete"},
+			Failed:      failed,
+		}
+	}
+	migrate := hook("web-migrate", true)
+	migrate.Events = []string{"post-install", "pre-upgrade"}
+	diagnosis := &types.DiagnosisReport{
+		Target:     types.ResourceRef{Kind: "Namespace", Name: "apps"},
+		TargetType: types.TargetTypeNamespace,
+		Status:     "Terminating",
+		HelmReleases: []types.HelmRelease{
+			{
+				Name: "web", Namespace: "apps", Revision: 3, Status: "uninstalling",
+				Description: "Deletion in progress (or silently failed)",
+				Hooks:       []types.HelmHook{hook("web-cleanup", true), hook("web-drain", false), migrate},
+			},
+			{Name: "db", Namespace: "apps", Revision: 1, Status: "deployed", Hooks: []types.HelmHook{hook("db-backup", true)}},
+			{Name: "api", Namespace: "apps", Revision: 2, Status: "pending-upgrade", Hooks: []types.HelmHook{hook("api-cleanup", true)}},
+		},
+	}
+
+	plan, err := NewPlanner(Options{MaxEscalation: types.EscalationClean}).Plan(diagnosis)
+	require.NoError(t, err)
+
+	var hooks []types.Action
+	for _, action := range plan.Actions {
+		if action.Operation == "delete-helm-hook" {
+			hooks = append(hooks, action)
+		}
+	}
+	// Only failed delete hooks of an uninstalling release are removed at L1
+	require.Len(t, hooks, 1)
+	assert.Equal(t, "web-cleanup", hooks[0].Target.Name)
+	assert.Equal(t, types.RiskLow, hooks[0].Risk)
+	assert.False(t, hooks[0].RequiresForce)
+	assert.Equal(t, "kubectl delete job web-cleanup -n apps --cascade=background --wait=false", hooks[0].Command)
+
+	require.Len(t, plan.Notes, 3)
+	assert.Contains(t, plan.Notes[0], "helm uninstall web -n apps --no-hooks")
+	assert.Contains(t, plan.Notes[1], "Left running delete hook Job(s) web-drain of Helm release apps/web in place")
+	assert.Contains(t, plan.Notes[2], "Its hooks are left to Helm")
+
+	// A running hook may still be cleaning up: only deleted at L3 with --allow-force
+	plan, err = NewPlanner(Options{MaxEscalation: types.EscalationCRD, AllowForce: true}).Plan(diagnosis)
+	require.NoError(t, err)
+
+	hooks = nil
+	for _, action := range plan.Actions {
+		if action.Operation == "delete-helm-hook" {
+			hooks = append(hooks, action)
+		}
+	}
+	require.Len(t, hooks, 2)
+	assert.Equal(t, "web-drain", hooks[1].Target.Name)
+	assert.Equal(t, types.EscalationCRD, hooks[1].EscalationLevel)
+	assert.Equal(t, types.RiskHigh, hooks[1].Risk)
+	assert.True(t, hooks[1].RequiresForce, "deleting a running hook asks for confirmation")
+	assert.NotContains(t, strings.Join(plan.Notes, "\n"), "Left running delete hook")
+}
+
+func TestPlanner_Plan_Impact(t *testing.T) {
+	now := metav1.Now()
+	blocker := func(kind, name string) types.Blocker {
+		return types.Blocker{
+			ResourceRef:       types.ResourceRef{Kind: kind, APIVersion: "v1", Namespace: "apps", Name: name},
+			Finalizers:        []string{"example.com/cleanup"},
+			DeletionTimestamp: &now,
+			Status:            types.BlockerStatusStuck,
+		}
+	}
+	lb := blocker("Service", "web")
+	lb.External = &types.ExternalResource{Type: types.ExternalLoadBalancer, Addresses: []string{"203.0.113.10"}}
+	diagnosis := &types.DiagnosisReport{
+		Target:            types.ResourceRef{Kind: "Namespace", Name: "apps"},
+		TargetType:        types.TargetTypeNamespace,
+		Status:            "Terminating",
+		Blockers:          []types.Blocker{blocker("PersistentVolumeClaim", "data"), blocker("Secret", "creds"), lb},
+		DiscoveryFailures: []types.DiscoveryFailure{{GroupVersion: "metrics.example.com/v1", Error: "not found"}},
+		RemainingContent: &types.RemainingContent{Resources: []types.RemainingCount{
+			{Name: "widgets.example.com", Reported: 3, Visible: 0},
+		}},
+	}
+
+	plan, err := NewPlanner(Options{MaxEscalation: types.EscalationForce, AllowForce: true}).Plan(diagnosis)
+	require.NoError(t, err)
+
+	byTarget := make(map[string][]types.ImpactItem)
+	for _, impact := range plan.Impact {
+		for _, action := range plan.Actions {
+			if action.ID == impact.ActionID {
+				byTarget[action.Operation+" "+action.Target.String()] = impact.Items
+			}
+		}
+	}
+
+	require.Len(t, byTarget["remove-finalizers PersistentVolumeClaim/apps/data"], 1)
+	assert.Equal(t, types.ImpactVolumeReleased, byTarget["remove-finalizers PersistentVolumeClaim/apps/data"][0].Type)
+	require.Len(t, byTarget["remove-finalizers Secret/apps/creds"], 1)
+	assert.Equal(t, types.ImpactDataDeleted, byTarget["remove-finalizers Secret/apps/creds"][0].Type)
+	require.Len(t, byTarget["remove-finalizers Service/apps/web"], 1)
+	assert.Equal(t, types.ImpactCloudResourceLeaked, byTarget["remove-finalizers Service/apps/web"][0].Type)
+	assert.Equal(t, "LoadBalancer 203.0.113.10", byTarget["remove-finalizers Service/apps/web"][0].Detail)
+
+	// Blockers handled at Level 2 are not counted again by force-finalize
+	abandoned := byTarget["force-finalize Namespace/apps"]
+	require.Len(t, abandoned, 2)
+	assert.Equal(t, types.ImpactObjectAbandoned, abandoned[0].Type)
+	assert.Equal(t, "all/apps/*", abandoned[0].Object.String())
+	assert.Equal(t, "widgets.example.com/apps/*", abandoned[1].Object.String())
+	assert.Contains(t, abandoned[1].Detail, "3 reported")
+}
+
+func TestCalculateImpact_CRDFinalizer(t *testing.T) {
+	diagnosis := &types.DiagnosisReport{
+		Target:        types.ResourceRef{Kind: "CustomResourceDefinition", Name: "widgets.example.com"},
+		TargetType:    types.TargetTypeCRD,
+		InstanceCount: 3,
+		Blockers: []types.Blocker{
+			{ResourceRef: types.ResourceRef{Kind: "Widget", APIVersion: "example.com/v1", Namespace: "apps", Name: "a"}},
+		},
+	}
+	actions := []types.Action{{ID: "action-001", Operation: "remove-crd-finalizer", Target: diagnosis.Target}}
+
+	impact := CalculateImpact(diagnosis, actions)
+	require.Len(t, impact, 1)
+	require.Len(t, impact[0].Items, 2)
+	assert.Equal(t, types.ImpactCRDataOrphaned, impact[0].Items[0].Type)
+	assert.Equal(t, "a", impact[0].Items[0].Object.Name)
+	assert.Contains(t, impact[0].Items[1].Detail, "2 more instance(s)")
+}
+
+func TestCalculateImpact_Operations(t *testing.T) {
+	now := metav1.Now()
+	pvc := types.ResourceRef{Kind: "PersistentVolumeClaim", APIVersion: "v1", Namespace: "apps", Name: "data"}
+	ns := types.ResourceRef{Kind: "Namespace", APIVersion: "v1", Name: "apps"}
+	crd := types.ResourceRef{Kind: "CustomResourceDefinition", Name: "widgets.example.com"}
+	deploy := types.ResourceRef{Kind: "Deployment", APIVersion: "apps/v1", Namespace: "apps", Name: "web"}
+	hook := types.ResourceRef{Kind: "Job", APIVersion: "batch/v1", Namespace: "apps", Name: "web-cleanup"}
+
+	diagnosis := &types.DiagnosisReport{
+		Target:              ns,
+		TargetType:          types.TargetTypeNamespace,
+		Finalizers:          []string{"controller.cattle.io/namespace-auth"},
+		FinalizerProvenance: []types.FinalizerProvenance{{Finalizer: "controller.cattle.io/namespace-auth", Manager: "rancher"}},
+		Conversion:          &types.ConversionInfo{Strategy: "Webhook", StoredVersions: []string{"v1", "v2"}},
+		Blockers: []types.Blocker{{
+			ResourceRef:       pvc,
+			Finalizers:        []string{"orphan", "foregroundDeletion"},
+			DeletionTimestamp: &now,
+			Status:            types.BlockerStatusStuck,
+		}},
+	}
+
+	tests := []struct {
+		name     string
+		action   types.Action
+		expected []types.ImpactType
+		detail   string
+	}{
+		{
+			name:   "first of several GC finalizers",
+			action: types.Action{Operation: types.OpRemoveGCFinalizer, Target: pvc, Finalizer: "orphan"},
+		},
+		{
+			name:     "last GC finalizer deletes the object",
+			action:   types.Action{Operation: types.OpRemoveGCFinalizer, Target: pvc, Finalizer: "foregroundDeletion"},
+			expected: []types.ImpactType{types.ImpactVolumeReleased},
+		},
+		{
+			name:     "cascade delete of a workload",
+			action:   types.Action{Operation: types.OpCascadeDelete, Target: deploy},
+			expected: []types.ImpactType{types.ImpactWorkloadDeleted},
+			detail:   "Deployment deleted with its pods",
+		},
+		{
+			name:     "helm hook",
+			action:   types.Action{Operation: types.OpDeleteHelmHook, Target: hook},
+			expected: []types.ImpactType{types.ImpactWorkloadDeleted},
+			detail:   "Job deleted with its pods",
+		},
+		{
+			name:     "rollout restart",
+			action:   types.Action{Operation: types.OpRolloutRestart, Target: deploy},
+			expected: []types.ImpactType{types.ImpactPodsRestarted},
+		},
+		{
+			name:     "namespace metadata finalizers",
+			action:   types.Action{Operation: types.OpRemoveNamespaceFinalizers, Target: ns},
+			expected: []types.ImpactType{types.ImpactCleanupSkipped},
+			detail:   "cleanup of controller.cattle.io/namespace-auth never runs (managed by rancher)",
+		},
+		{
+			name:     "conversion webhook",
+			action:   types.Action{Operation: types.OpDisableConversionWebhook, Target: crd},
+			expected: []types.ImpactType{types.ImpactConversionDisabled},
+			detail:   "objects stored at v1, v2 are served with only apiVersion rewritten, without schema conversion",
+		},
+		{
+			name:   "scale up",
+			action: types.Action{Operation: types.OpScaleUp, Target: deploy},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.action.ID = "action-001"
+			impact := CalculateImpact(diagnosis, []types.Action{tt.action})
+			if len(tt.expected) == 0 {
+				assert.Empty(t, impact)
+				return
+			}
+			require.Len(t, impact, 1)
+			var got []types.ImpactType
+			for _, item := range impact[0].Items {
+				got = append(got, item.Type)
+			}
+			assert.Equal(t, tt.expected, got)
+			if tt.detail != "" {
+				assert.Equal(t, tt.detail, impact[0].Items[0].Detail)
+			}
+		})
+	}
+}
+
 func TestGeneratePatchCommand(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -318,6 +860,20 @@ func TestHasAvailableController(t *testing.T) {
 			},
 			expected: false,
 		},
+		{
+			name: "ready with fresh lease",
+			controllers: []types.ControllerStatus{
+				{Name: "cert-manager", Available: true, Ready: true, Liveness: types.ControllerAlive},
+			},
+			expected: true,
+		},
+		{
+			name: "ready but leader election wedged",
+			controllers: []types.ControllerStatus{
+				{Name: "cert-manager", Available: true, Ready: true, Liveness: types.ControllerStale},
+			},
+			expected: false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -330,3 +886,292 @@ func TestHasAvailableController(t *testing.T) {
 		})
 	}
 }
+
+func TestPlanner_Plan_SelfDeadlock(t *testing.T) {
+	p := NewPlanner(Options{MaxEscalation: types.EscalationFinalizer})
+
+	widget := types.ResourceRef{Kind: "Widget", APIVersion: "example.com/v1", Namespace: "apps", Name: "w1"}
+	recent := types.ResourceRef{Kind: "Widget", APIVersion: "example.com/v1", Namespace: "apps", Name: "w2"}
+	diagnosis := &types.DiagnosisReport{
+		Target:     types.ResourceRef{Kind: "Namespace", Name: "apps"},
+		TargetType: types.TargetTypeNamespace,
+		Status:     "Terminating",
+		Blockers: []types.Blocker{
+			{ResourceRef: widget, Finalizers: []string{"example.com/cleanup"}},
+			{ResourceRef: recent, Finalizers: []string{"example.com/cleanup"}, Status: types.BlockerStatusPending},
+		},
+		Controllers: []types.ControllerStatus{
+			{Name: "widget-operator", Namespace: "apps", Kind: "Deployment", Available: true, Ready: true},
+		},
+		SelfDeadlock: &types.SelfDeadlock{
+			Controllers: []types.ControllerStatus{{Name: "widget-operator", Namespace: "apps", Kind: "Deployment"}},
+			Finalizers:  []string{"example.com/cleanup"},
+			Blockers:    []types.ResourceRef{widget, recent},
+		},
+	}
+
+	plan, err := p.Plan(diagnosis)
+
+	require.NoError(t, err)
+	require.NotEmpty(t, plan.Notes)
+	assert.Contains(t, plan.Notes[0], "No clean path exists")
+	assert.Contains(t, plan.Notes[0], "Deployment/widget-operator")
+	assert.Contains(t, plan.Notes[1], "the 1 stuck objects")
+	assert.Contains(t, plan.Notes[2], "Widget/apps/w1")
+	// The Pending blocker gets no action, so the note does not ask to unblock it
+	assert.Contains(t, plan.Notes[3], "Widget/apps/w2")
+	assert.Contains(t, plan.Notes[3], "not in the plan yet")
+	for _, action := range plan.Actions {
+		assert.NotEqual(t, recent, action.Target)
+	}
+
+	// No L1 wait: the controller can never process the finalizers
+	for _, action := range plan.Actions {
+		assert.NotEqual(t, types.ActionWait, action.Type)
+	}
+}
+
+func TestPlanner_Plan_ReviveController(t *testing.T) {
+	p := NewPlanner(Options{MaxEscalation: types.EscalationFinalizer})
+
+	widget := types.ResourceRef{Kind: "Widget", APIVersion: "example.com/v1", Namespace: "apps", Name: "w1"}
+	diagnosis := &types.DiagnosisReport{
+		Target:     types.ResourceRef{Kind: "Namespace", Name: "apps"},
+		TargetType: types.TargetTypeNamespace,
+		Status:     "Terminating",
+		Blockers: []types.Blocker{
+			{ResourceRef: widget, Finalizers: []string{"example.com/cleanup"}},
+			{ResourceRef: types.ResourceRef{Kind: "Gadget", Namespace: "apps", Name: "g1"}, Finalizers: []string{"other.io/finalizer"}},
+		},
+		Controllers: []types.ControllerStatus{
+			{Name: "widget-operator", Namespace: "operators", Kind: "Deployment", Replicas: 0, Finalizers: []string{"example.com/cleanup"}},
+			{Name: "gadget-operator", Namespace: "operators", Kind: "StatefulSet", Replicas: 1, Available: true, Finalizers: []string{"other.io/finalizer"}},
+			// Pods cannot start in the terminating namespace
+			{Name: "local-operator", Namespace: "apps", Kind: "Deployment", Replicas: 0, Finalizers: []string{"example.com/cleanup"}},
+		},
+	}
+
+	plan, err := p.Plan(diagnosis)
+
+	require.NoError(t, err)
+	var revive []types.Action
+	for _, action := range plan.Actions {
+		if action.Type == types.ActionRevive {
+			revive = append(revive, action)
+		}
+	}
+	require.Len(t, revive, 2)
+
+	assert.Equal(t, "gadget-operator", revive[0].Target.Name)
+	assert.Equal(t, "rollout-restart", revive[0].Operation)
+	assert.Equal(t, "kubectl rollout restart statefulset/gadget-operator -n operators", revive[0].Command)
+
+	assert.Equal(t, "widget-operator", revive[1].Target.Name)
+	assert.Equal(t, "scale-up", revive[1].Operation)
+	assert.Equal(t, types.EscalationClean, revive[1].EscalationLevel)
+	assert.Equal(t, []types.ResourceRef{widget}, revive[1].WaitFor)
+
+	// Revival runs before any finalizer is removed
+	last := plan.Actions[len(plan.Actions)-1]
+	assert.Equal(t, types.EscalationFinalizer, last.EscalationLevel)
+}
+
+func TestPlanner_Plan_OnlyStuckBlockers(t *testing.T) {
+	p := NewPlanner(Options{MaxEscalation: types.EscalationFinalizer})
+
+	diagnosis := &types.DiagnosisReport{
+		Target:     types.ResourceRef{Kind: "Namespace", Name: "apps"},
+		TargetType: types.TargetTypeNamespace,
+		Status:     "Terminating",
+		Blockers: []types.Blocker{
+			{ResourceRef: types.ResourceRef{Kind: "Widget", Namespace: "apps", Name: "stuck"}, Status: types.BlockerStatusStuck},
+			{ResourceRef: types.ResourceRef{Kind: "Widget", Namespace: "apps", Name: "pending"}, Status: types.BlockerStatusPending},
+			{ResourceRef: types.ResourceRef{Kind: "Widget", Namespace: "apps", Name: "healthy"}, Status: types.BlockerStatusHealthy},
+		},
+	}
+
+	plan, err := p.Plan(diagnosis)
+
+	require.NoError(t, err)
+	var patched []string
+	for _, action := range plan.Actions {
+		if action.Operation == "remove-finalizers" {
+			patched = append(patched, action.Target.Name)
+		}
+	}
+	assert.Equal(t, []string{"stuck"}, patched)
+	require.Len(t, plan.Notes, 1)
+	assert.Contains(t, plan.Notes[0], "2 blocker(s)")
+}
+
+func TestPlanner_Plan_ProgressingCapsEscalation(t *testing.T) {
+	p := NewPlanner(Options{MaxEscalation: types.EscalationForce, AllowForce: true})
+
+	diagnosis := &types.DiagnosisReport{
+		Target:     types.ResourceRef{Kind: "Namespace", Name: "big"},
+		TargetType: types.TargetTypeNamespace,
+		Status:     "Terminating",
+		Blockers: []types.Blocker{
+			{ResourceRef: types.ResourceRef{Kind: "Widget", Namespace: "big", Name: "w1"}, Finalizers: []string{"example.com/cleanup"}},
+		},
+		DiscoveryFailures: []types.DiscoveryFailure{{GroupVersion: "metrics.k8s.io/v1beta1"}},
+		Progress: &types.DeletionProgress{
+			State:         types.ProgressProgressing,
+			RatePerMinute: 500,
+			ETA:           10 * time.Minute,
+		},
+	}
+
+	plan, err := p.Plan(diagnosis)
+
+	require.NoError(t, err)
+	for _, action := range plan.Actions {
+		assert.LessOrEqual(t, action.EscalationLevel, types.EscalationClean)
+	}
+	require.NotEmpty(t, plan.Notes)
+	assert.Contains(t, plan.Notes[0], "not escalating past L1")
+}
+
+func TestPlanner_Plan_DeletionDone(t *testing.T) {
+	p := NewPlanner(Options{MaxEscalation: types.EscalationFinalizer})
+
+	diagnosis := &types.DiagnosisReport{
+		Target:     types.ResourceRef{Kind: "Namespace", Name: "gone"},
+		TargetType: types.TargetTypeNamespace,
+		Status:     "Terminating",
+		Blockers: []types.Blocker{
+			{ResourceRef: types.ResourceRef{Kind: "Widget", Namespace: "gone", Name: "w1"}, Finalizers: []string{"example.com/cleanup"}},
+		},
+		Progress: &types.DeletionProgress{State: types.ProgressDone},
+	}
+
+	plan, err := p.Plan(diagnosis)
+
+	require.NoError(t, err)
+	assert.Empty(t, plan.Actions)
+	assert.Equal(t, []string{"Deletion finished while sampling; nothing is left to remediate."}, plan.Notes)
+}
+
+func TestPlanner_Plan_CorruptObjects(t *testing.T) {
+	diagnosis := &types.DiagnosisReport{
+		Target:     types.ResourceRef{Kind: "Namespace", Name: "apps"},
+		TargetType: types.TargetTypeNamespace,
+		Status:     "Terminating",
+		CorruptObjects: []types.CorruptObject{
+			{
+				ResourceRef: types.ResourceRef{Kind: "Widget", APIVersion: "example.com/v1", Namespace: "apps", Name: "w1"},
+				Resource:    "widgets.example.com",
+			},
+			{Resource: "gadgets.example.com"},
+		},
+	}
+
+	t.Run("without force only explains", func(t *testing.T) {
+		plan, err := NewPlanner(Options{MaxEscalation: types.EscalationFinalizer}).Plan(diagnosis)
+
+		require.NoError(t, err)
+		for _, action := range plan.Actions {
+			assert.NotEqual(t, "unsafe-delete", action.Operation)
+		}
+		assert.Contains(t, strings.Join(plan.Notes, "\n"), "--max-escalation=4 --allow-force")
+		assert.Contains(t, strings.Join(plan.Notes, "\n"), "did not name 1 corrupt object(s)")
+	})
+
+	t.Run("with force deletes identified objects", func(t *testing.T) {
+		plan, err := NewPlanner(Options{MaxEscalation: types.EscalationForce, AllowForce: true}).Plan(diagnosis)
+
+		require.NoError(t, err)
+		var unsafe []types.Action
+		for _, action := range plan.Actions {
+			if action.Operation == "unsafe-delete" {
+				unsafe = append(unsafe, action)
+			}
+		}
+		require.Len(t, unsafe, 1)
+		assert.Equal(t, types.RiskCritical, unsafe[0].Risk)
+		assert.True(t, unsafe[0].RequiresForce)
+		assert.Equal(t, "kubectl delete widgets.example.com w1 -n apps --ignore-store-read-error-with-cluster-breaking-potential=true", unsafe[0].Command)
+		assert.Equal(t, types.RiskCritical, plan.RiskLevel)
+	})
+}
+
+func TestPlanner_Plan_ConversionFailure(t *testing.T) {
+	diagnosis := &types.DiagnosisReport{
+		Target:     types.ResourceRef{Kind: "CustomResourceDefinition", APIVersion: "apiextensions.k8s.io/v1", Name: "widgets.example.com"},
+		TargetType: types.TargetTypeCRD,
+		Status:     "Terminating",
+		Conversion: &types.ConversionInfo{Strategy: "Webhook", InstancesUnreadable: true},
+	}
+
+	t.Run("without force only explains", func(t *testing.T) {
+		plan, err := NewPlanner(Options{MaxEscalation: types.EscalationFinalizer}).Plan(diagnosis)
+
+		require.NoError(t, err)
+		for _, action := range plan.Actions {
+			assert.NotEqual(t, "disable-conversion-webhook", action.Operation)
+		}
+		assert.Contains(t, strings.Join(plan.Notes, "\n"), "--max-escalation=3 --allow-force")
+	})
+
+	t.Run("with force disables conversion instead of removing the finalizer", func(t *testing.T) {
+		plan, err := NewPlanner(Options{MaxEscalation: types.EscalationCRD, AllowForce: true}).Plan(diagnosis)
+
+		require.NoError(t, err)
+		var operations []string
+		for _, action := range plan.Actions {
+			operations = append(operations, action.Operation)
+		}
+		assert.Contains(t, operations, "disable-conversion-webhook")
+		assert.NotContains(t, operations, "remove-crd-finalizer")
+		assert.Contains(t, plan.Commands, `kubectl patch crd widgets.example.com -p '{"spec":{"conversion":{"strategy":"None","webhook":null}}}' --type=merge`)
+	})
+}
+
+func TestPlanner_Plan_GCIssues(t *testing.T) {
+	pod := types.ResourceRef{Kind: "Pod", APIVersion: "v1", Namespace: "apps", Name: "web-abc"}
+	widget := types.ResourceRef{Kind: "Widget", APIVersion: "example.com/v1", Namespace: "apps", Name: "w1"}
+	owner := types.ResourceRef{Kind: "ReplicaSet", APIVersion: "apps/v1", Namespace: "apps", Name: "web-old"}
+
+	diagnosis := &types.DiagnosisReport{
+		Target:     types.ResourceRef{Kind: "Namespace", Name: "apps"},
+		TargetType: types.TargetTypeNamespace,
+		Status:     "Terminating",
+		Blockers: []types.Blocker{
+			{
+				ResourceRef: pod,
+				Finalizers:  []string{"orphan"},
+				OwnerReferences: []metav1.OwnerReference{
+					{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: "web-live", UID: "live-uid"},
+					{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: "web-old", UID: "gone-uid"},
+				},
+			},
+			{ResourceRef: widget, Finalizers: []string{"foregroundDeletion", "example.com/cleanup"}},
+		},
+		GCIssues: []types.GCIssue{
+			{Type: types.GCIssueOrphanFinalizer, Object: pod, Finalizer: "orphan"},
+			{Type: types.GCIssueDanglingOwner, Object: pod, Owner: &owner, OwnerUID: "gone-uid"},
+			{Type: types.GCIssueForegroundFinalizer, Object: widget, Finalizer: "foregroundDeletion"},
+		},
+	}
+
+	plan, err := NewPlanner(Options{MaxEscalation: types.EscalationFinalizer}).Plan(diagnosis)
+	require.NoError(t, err)
+
+	byOperation := make(map[string][]types.Action)
+	for _, action := range plan.Actions {
+		byOperation[action.Operation] = append(byOperation[action.Operation], action)
+	}
+
+	require.Len(t, byOperation["remove-owner-reference"], 1)
+	dropOwner := byOperation["remove-owner-reference"][0]
+	assert.Equal(t, pod, dropOwner.Target)
+	assert.Equal(t, "gone-uid", dropOwner.OwnerUID)
+	assert.Contains(t, dropOwner.Command, `{"op":"test","path":"/metadata/ownerReferences/1/uid","value":"gone-uid"}`)
+
+	require.Len(t, byOperation["remove-gc-finalizer"], 1)
+	assert.Equal(t, "orphan", byOperation["remove-gc-finalizer"][0].Finalizer)
+
+	// The widget also carries an operator finalizer, so it gets generic removal
+	require.Len(t, byOperation["remove-finalizers"], 1)
+	assert.Equal(t, widget, byOperation["remove-finalizers"][0].Target)
+}
@@ -12,14 +12,24 @@ func CalculateRiskLevel(actions []types.Action) types.RiskLevel {
 
 	maxRisk := types.RiskNone
 	for _, action := range actions {
-		if compareRisk(action.Risk, maxRisk) > 0 {
-			maxRisk = action.Risk
+		if risk := ActionRisk(action); compareRisk(risk, maxRisk) > 0 {
+			maxRisk = risk
 		}
 	}
 
 	return maxRisk
 }
 
+// ActionRisk returns the risk of an action. Removing the finalizer of an object that owns a cloud
+// load balancer or other cloud resource leaks it, so such patches are at least high risk.
+func ActionRisk(action types.Action) types.RiskLevel {
+	leaks := action.External != nil || action.CloudResource != nil
+	if leaks && action.Type == types.ActionPatch && compareRisk(action.Risk, types.RiskHigh) < 0 {
+		return types.RiskHigh
+	}
+	return action.Risk
+}
+
 // compareRisk compares two risk levels
 // Returns: -1 if a < b, 0 if a == b, 1 if a > b
 func compareRisk(a, b types.RiskLevel) int {
@@ -113,7 +123,7 @@ func HasForceActions(actions []types.Action) bool {
 func SummaryByRisk(actions []types.Action) map[types.RiskLevel]int {
 	summary := make(map[types.RiskLevel]int)
 	for _, action := range actions {
-		summary[action.Risk]++
+		summary[ActionRisk(action)]++
 	}
 	return summary
 }
@@ -56,6 +56,24 @@ func TestCalculateRiskLevel_ReturnsHighest(t *testing.T) {
 	}
 }
 
+func TestCalculateRiskLevel_ExternalResource(t *testing.T) {
+	external := &types.ExternalResource{Type: types.ExternalLoadBalancer, Addresses: []string{"203.0.113.10"}}
+
+	actions := []types.Action{
+		{Type: types.ActionPatch, Risk: types.RiskMedium, External: external},
+		{Type: types.ActionInspect, Risk: types.RiskNone},
+	}
+	assert.Equal(t, types.RiskHigh, ActionRisk(actions[0]))
+	assert.Equal(t, types.RiskHigh, CalculateRiskLevel(actions))
+
+	// Risk that is already higher is kept
+	critical := types.Action{Type: types.ActionPatch, Risk: types.RiskCritical, External: external}
+	assert.Equal(t, types.RiskCritical, ActionRisk(critical))
+
+	cloud := types.Action{Type: types.ActionPatch, Risk: types.RiskMedium, CloudResource: &types.CloudResource{Family: types.CloudACK}}
+	assert.Equal(t, types.RiskHigh, ActionRisk(cloud))
+}
+
 func TestCompareRisk(t *testing.T) {
 	tests := []struct {
 		name     string
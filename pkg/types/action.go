@@ -14,6 +14,27 @@ const (
 	ActionDelete   ActionType = "delete"   // Delete object
 	ActionFinalize ActionType = "finalize" // Force finalize
 	ActionWait     ActionType = "wait"     // Wait for condition
+	ActionRevive   ActionType = "revive"   // Scale up or restart a controller, then wait for it
+)
+
+// Operations carried by Action.Operation; the applier dispatches on them
+const (
+	OpInspect                    = "inspect"
+	OpListBlockers               = "list-blockers"
+	OpWaitController             = "wait-controller"
+	OpScaleUp                    = "scale-up"
+	OpRolloutRestart             = "rollout-restart"
+	OpCascadeDelete              = "cascade-delete"
+	OpDeleteHelmHook             = "delete-helm-hook"
+	OpRemoveFinalizers           = "remove-finalizers"
+	OpRemoveGCFinalizer          = "remove-gc-finalizer"
+	OpRemoveJobTrackingFinalizer = "remove-job-tracking-finalizer"
+	OpRemoveOwnerReference       = "remove-owner-reference"
+	OpRemoveNamespaceFinalizers  = "remove-namespace-finalizers"
+	OpRemoveCRDFinalizer         = "remove-crd-finalizer"
+	OpDisableConversionWebhook   = "disable-conversion-webhook"
+	OpUnsafeDelete               = "unsafe-delete"
+	OpForceFinalize              = "force-finalize"
 )
 
 // RiskLevel represents the risk level of an action
@@ -80,15 +101,98 @@ type Action struct {
 	ExpectedResult  string          `json:"expectedResult"`
 	DependsOn       []string        `json:"dependsOn,omitempty"`
 	Timeout         time.Duration   `json:"timeout,omitempty"`
+	WaitFor         []ResourceRef   `json:"waitFor,omitempty"`   // Objects whose finalizers should clear afterwards
+	Finalizer       string          `json:"finalizer,omitempty"` // Single finalizer to remove (remove-gc-finalizer, remove-job-tracking-finalizer)
+	OwnerUID        string          `json:"ownerUID,omitempty"`  // Owner reference to drop (remove-owner-reference)
+
+	// External load balancer leaked by removing the target's finalizers
+	External *ExternalResource `json:"external,omitempty"`
+
+	// Cloud resource orphaned by removing the target's finalizers
+	CloudResource *CloudResource `json:"cloudResource,omitempty"`
 }
 
 // Plan represents a remediation plan
 type Plan struct {
-	Target        ResourceRef `json:"target"`
-	RiskLevel     RiskLevel   `json:"riskLevel"`
-	MaxEscalation EscalationLevel `json:"maxEscalation"`
-	Actions       []Action    `json:"actions"`
-	Commands      []string    `json:"commands,omitempty"` // kubectl commands for dry-run
+	Target        ResourceRef       `json:"target"`
+	RiskLevel     RiskLevel         `json:"riskLevel"`
+	MaxEscalation EscalationLevel   `json:"maxEscalation"`
+	Actions       []Action          `json:"actions"`
+	Commands      []string          `json:"commands,omitempty"` // kubectl commands for dry-run
+	Permissions   []PermissionCheck `json:"permissions,omitempty"`
+	Notes         []string          `json:"notes,omitempty"`  // Explanations that cannot be expressed as actions
+	Impact        []ActionImpact    `json:"impact,omitempty"` // What each action deletes, releases or leaves behind
+}
+
+// ImpactType classifies something an action loses or leaves behind
+type ImpactType string
+
+const (
+	ImpactVolumeReleased      ImpactType = "volume-released"       // PersistentVolume released by deleting its claim
+	ImpactDataDeleted         ImpactType = "data-deleted"          // Secret or ConfigMap deleted
+	ImpactCloudResourceLeaked ImpactType = "cloud-resource-leaked" // Cloud resource nothing will delete anymore
+	ImpactCRDataOrphaned      ImpactType = "cr-data-orphaned"      // Custom resource left in storage without its CRD
+	ImpactObjectAbandoned     ImpactType = "object-abandoned"      // Object left behind by force-finalizing its namespace
+	ImpactWorkloadDeleted     ImpactType = "workload-deleted"      // Workload deleted together with its pods
+	ImpactPodsRestarted       ImpactType = "pods-restarted"        // Pods of a workload replaced by a rollout restart
+	ImpactCleanupSkipped      ImpactType = "cleanup-skipped"       // Cleanup a removed finalizer was waiting for never runs
+	ImpactConversionDisabled  ImpactType = "conversion-disabled"   // Custom resources served without schema conversion
+)
+
+// ImpactItem is a single object affected by an action
+type ImpactItem struct {
+	Type   ImpactType  `json:"type"`
+	Object ResourceRef `json:"object"`
+	Detail string      `json:"detail,omitempty"`
+}
+
+// ActionImpact lists what a single action of the plan deletes, releases or leaves behind
+type ActionImpact struct {
+	ActionID string       `json:"actionId"`
+	Items    []ImpactItem `json:"items"`
+}
+
+// MissingPermissions returns the permission checks that were denied
+func (p *Plan) MissingPermissions() []PermissionCheck {
+	var missing []PermissionCheck
+	for _, check := range p.Permissions {
+		if !check.Allowed {
+			missing = append(missing, check)
+		}
+	}
+	return missing
+}
+
+// PermissionCheck records whether the current user may perform an API request required by an action
+type PermissionCheck struct {
+	ActionID    string `json:"actionId"`
+	Verb        string `json:"verb"`
+	Group       string `json:"group,omitempty"`
+	Resource    string `json:"resource"`
+	Subresource string `json:"subresource,omitempty"`
+	Namespace   string `json:"namespace,omitempty"`
+	Name        string `json:"name,omitempty"`
+	Allowed     bool   `json:"allowed"`
+	Reason      string `json:"reason,omitempty"`
+}
+
+// String returns a kubectl auth can-i style representation of the check
+func (c PermissionCheck) String() string {
+	resource := c.Resource
+	if c.Group != "" {
+		resource += "." + c.Group
+	}
+	if c.Subresource != "" {
+		resource += "/" + c.Subresource
+	}
+	s := c.Verb + " " + resource
+	if c.Name != "" {
+		s += " " + c.Name
+	}
+	if c.Namespace != "" {
+		s += " -n " + c.Namespace
+	}
+	return s
 }
 
 // ActionResult represents the result of executing an action
@@ -102,10 +206,20 @@ type ActionResult struct {
 	Duration   string      `json:"duration"`
 }
 
+// DryRunMode describes how a dry-run apply was validated
+type DryRunMode string
+
+const (
+	DryRunNone   DryRunMode = ""       // Changes were applied
+	DryRunClient DryRunMode = "client" // Actions printed without contacting the API server
+	DryRunServer DryRunMode = "server" // Actions sent to the API server with DryRun=All
+)
+
 // ApplyResult represents the result of applying a plan
 type ApplyResult struct {
 	StartTime    time.Time      `json:"startTime"`
 	EndTime      time.Time      `json:"endTime"`
+	DryRunMode   DryRunMode     `json:"dryRunMode,omitempty"`
 	TotalActions int            `json:"totalActions"`
 	Succeeded    int            `json:"succeeded"`
 	Failed       int            `json:"failed"`
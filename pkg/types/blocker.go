@@ -1,6 +1,8 @@
 package types
 
 import (
+	"fmt"
+	"strings"
 	"time"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -25,10 +27,252 @@ func (r ResourceRef) String() string {
 // Blocker represents a resource blocking deletion
 type Blocker struct {
 	ResourceRef
-	Finalizers        []string               `json:"finalizers"`
-	DeletionTimestamp *metav1.Time           `json:"deletionTimestamp,omitempty"`
+	UID               string                  `json:"uid,omitempty"`
+	Finalizers        []string                `json:"finalizers"`
+	DeletionTimestamp *metav1.Time            `json:"deletionTimestamp,omitempty"`
 	OwnerReferences   []metav1.OwnerReference `json:"ownerReferences,omitempty"`
-	Age               time.Duration          `json:"age,omitempty"`
+	Age               time.Duration           `json:"age,omitempty"`
+
+	// Which field manager added each finalizer, from metadata.managedFields
+	FinalizerProvenance []FinalizerProvenance `json:"finalizerProvenance,omitempty"`
+
+	// Classification against the --stuck-after threshold, and why the blocker is in that state
+	Status    BlockerStatus `json:"status,omitempty"`
+	RootCause string        `json:"rootCause,omitempty"`
+
+	// Load balancer provisioned for the blocker that its finalizer cleans up; nil for other objects
+	External *ExternalResource `json:"external,omitempty"`
+
+	// Owning Job of a pod carrying the job tracking finalizer; nil for other objects
+	JobTracking *JobTracking `json:"jobTracking,omitempty"`
+
+	// Argo CD or Flux object whose finalizer cascades to the resources it manages; nil for other objects
+	GitOps *GitOpsInfo `json:"gitOps,omitempty"`
+
+	// Helm release that installed the blocker as namespace/name, from its meta.helm.sh annotations
+	HelmRelease string `json:"helmRelease,omitempty"`
+
+	// Cloud resource managed through the blocker by Crossplane, ACK or Config Connector; nil for other objects
+	CloudResource *CloudResource `json:"cloudResource,omitempty"`
+}
+
+// CloudResourceFamily names the project whose controllers back Kubernetes objects with cloud resources
+type CloudResourceFamily string
+
+const (
+	CloudCrossplane      CloudResourceFamily = "Crossplane"
+	CloudACK             CloudResourceFamily = "ACK"             // AWS Controllers for Kubernetes
+	CloudConfigConnector CloudResourceFamily = "ConfigConnector" // Google Cloud Config Connector
+)
+
+// CloudResource is cloud infrastructure managed through a Kubernetes object. Its finalizer deletes
+// the infrastructure; removing the finalizer instead orphans it.
+type CloudResource struct {
+	Family       CloudResourceFamily `json:"family"`
+	Finalizer    string              `json:"finalizer"`
+	ExternalName string              `json:"externalName,omitempty"` // Name of the resource in the cloud provider
+	Identifiers  []string            `json:"identifiers,omitempty"`  // ARNs, self links or IDs from status
+
+	// Crossplane Provider or controller that manages the resource
+	Provider      string                  `json:"provider,omitempty"`
+	ProviderState ExternalControllerState `json:"providerState"`
+
+	// The deletion policy keeps the cloud resource when the object is deleted
+	Retained bool   `json:"retained,omitempty"`
+	Message  string `json:"message,omitempty"`
+}
+
+// Description returns the family and the identifiers of the resource,
+// e.g. "Crossplane resource my-bucket (arn:aws:s3:::my-bucket)"
+func (c CloudResource) Description() string {
+	desc := string(c.Family) + " resource"
+	if c.ExternalName != "" {
+		desc += " " + c.ExternalName
+	}
+	if len(c.Identifiers) > 0 {
+		desc += " (" + strings.Join(c.Identifiers, ", ") + ")"
+	}
+	if c.ExternalName == "" && len(c.Identifiers) == 0 {
+		desc += " (no identifier in status)"
+	}
+	return desc
+}
+
+// Leaks returns true if removing the finalizer orphans the cloud resource
+func (c CloudResource) Leaks() bool {
+	return !c.Retained
+}
+
+// GitOpsTool names the GitOps controller that owns a finalizer
+type GitOpsTool string
+
+const (
+	GitOpsArgoCD GitOpsTool = "ArgoCD"
+	GitOpsFlux   GitOpsTool = "Flux"
+)
+
+// GitOpsInfo describes an Argo CD Application or Flux Kustomization/HelmRelease whose finalizer
+// deletes the resources it manages before letting the object go
+type GitOpsInfo struct {
+	Tool            GitOpsTool              `json:"tool"`
+	Finalizer       string                  `json:"finalizer"`
+	Controller      string                  `json:"controller,omitempty"`
+	ControllerState ExternalControllerState `json:"controllerState"`
+
+	// Cluster the managed resources are deployed to; only in-cluster resources can be checked
+	Destination      string `json:"destination,omitempty"`
+	InCluster        bool   `json:"inCluster"`
+	DestinationIssue string `json:"destinationIssue,omitempty"`
+
+	SyncStatus   string `json:"syncStatus,omitempty"`   // Argo CD sync status, or the reason of Flux's Ready condition
+	HealthStatus string `json:"healthStatus,omitempty"` // Argo CD health status, or the status of Flux's Ready condition
+	Prune        bool   `json:"prune"`                  // Deletion removes the managed resources
+
+	ManagedCount int               `json:"managedCount"`        // Managed resources listed in status
+	Remaining    []ManagedResource `json:"remaining,omitempty"` // Managed resources that still exist in this cluster
+
+	// Why the controller has not finished the cascade, empty if unknown
+	StuckReason string `json:"stuckReason,omitempty"`
+}
+
+// ManagedResource is a resource deployed by a GitOps object that still exists
+type ManagedResource struct {
+	ResourceRef
+	Terminating bool `json:"terminating,omitempty"`
+	Keep        bool `json:"keep,omitempty"` // Annotated to survive deletion of the GitOps object
+}
+
+// CascadeCandidates returns the remaining managed resources unstuck may delete on behalf of the
+// controller: not already terminating, not kept, and not namespaces or CRDs
+func (g GitOpsInfo) CascadeCandidates() []ResourceRef {
+	if !g.InCluster || !g.Prune {
+		return nil
+	}
+	var refs []ResourceRef
+	for _, r := range g.Remaining {
+		if r.Terminating || r.Keep || r.Kind == "Namespace" || r.Kind == "CustomResourceDefinition" {
+			continue
+		}
+		refs = append(refs, r.ResourceRef)
+	}
+	return refs
+}
+
+// JobTracking describes a pod held by the batch.kubernetes.io/job-tracking finalizer, which the
+// job controller removes once it has counted the pod in the Job's status
+type JobTracking struct {
+	Job        string `json:"job,omitempty"` // Name of the owning Job, empty if the pod has no Job owner
+	JobExists  bool   `json:"jobExists"`
+	Phase      string `json:"phase,omitempty"` // Pod phase
+	Terminated bool   `json:"terminated"`      // Pod phase is Succeeded or Failed
+	Message    string `json:"message,omitempty"`
+}
+
+// CanRemoveFinalizer returns true if no Job is left to count the pod
+func (j JobTracking) CanRemoveFinalizer() bool {
+	return !j.JobExists
+}
+
+// HelmRelease describes the latest revision of a Helm release that installed blockers or was
+// left in a pending state, read from its release Secret
+type HelmRelease struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	Revision  int    `json:"revision,omitempty"`
+	// Helm release status, e.g. deployed, uninstalling or failed; empty if no release Secret is left
+	Status      string        `json:"status,omitempty"`
+	Description string        `json:"description,omitempty"` // Helm's description of the last operation
+	Chart       string        `json:"chart,omitempty"`       // name-version
+	Blockers    []ResourceRef `json:"blockers,omitempty"`    // Blockers installed by the release
+	Hooks       []HelmHook    `json:"hooks,omitempty"`       // Hook Jobs that have not completed
+}
+
+// String returns the release as namespace/name
+func (r HelmRelease) String() string {
+	return r.Namespace + "/" + r.Name
+}
+
+// IsStuck returns true if the last Helm operation on the release did not finish
+func (r HelmRelease) IsStuck() bool {
+	return r.Status == "uninstalling" || r.Status == "failed" || strings.HasPrefix(r.Status, "pending-")
+}
+
+// HelmHook is a hook Job of a release that is still running or has failed
+type HelmHook struct {
+	ResourceRef
+	Events      []string `json:"events"`            // e.g. pre-delete, post-install
+	LastRun     string   `json:"lastRun,omitempty"` // Phase Helm recorded for the last run
+	Failed      bool     `json:"failed"`            // The Job has a Failed condition
+	Terminating bool     `json:"terminating,omitempty"`
+	Message     string   `json:"message,omitempty"`
+}
+
+// IsDeleteHook returns true if the hook runs on helm uninstall
+func (h HelmHook) IsDeleteHook() bool {
+	for _, e := range h.Events {
+		if e == "pre-delete" || e == "post-delete" {
+			return true
+		}
+	}
+	return false
+}
+
+// ExternalResourceType names the kind of infrastructure a networking finalizer cleans up
+type ExternalResourceType string
+
+const (
+	ExternalLoadBalancer ExternalResourceType = "LoadBalancer" // Service of type LoadBalancer
+	ExternalIngress      ExternalResourceType = "Ingress"      // Load balancer of an ingress controller
+	ExternalGateway      ExternalResourceType = "Gateway"      // Load balancer of a Gateway API implementation
+)
+
+// ExternalControllerState records whether the controller that cleans up an external resource runs
+type ExternalControllerState string
+
+const (
+	ExternalControllerRunning    ExternalControllerState = "Running"
+	ExternalControllerNotRunning ExternalControllerState = "NotRunning"
+	// ExternalControllerUnknown is used when the controller cannot be seen, e.g. a managed
+	// cloud-controller-manager that runs in the control plane
+	ExternalControllerUnknown ExternalControllerState = "Unknown"
+)
+
+// ExternalResource is cloud infrastructure that is leaked if a blocker's finalizer is removed
+// instead of being processed by its controller
+type ExternalResource struct {
+	Type            ExternalResourceType    `json:"type"`
+	Finalizer       string                  `json:"finalizer"`
+	Class           string                  `json:"class,omitempty"`      // loadBalancerClass, IngressClass or GatewayClass
+	Controller      string                  `json:"controller,omitempty"` // controllerName of the class, or cloud-controller-manager
+	ControllerState ExternalControllerState `json:"controllerState"`
+	Addresses       []string                `json:"addresses,omitempty"` // External IPs and hostnames in status
+	Message         string                  `json:"message,omitempty"`
+}
+
+// Description returns the resource and its addresses, e.g. "LoadBalancer 203.0.113.10"
+func (e ExternalResource) Description() string {
+	if len(e.Addresses) == 0 {
+		return string(e.Type) + " (no address in status)"
+	}
+	return string(e.Type) + " " + strings.Join(e.Addresses, ", ")
+}
+
+// FinalizerProvenance records the field manager that owns a finalizer entry
+type FinalizerProvenance struct {
+	Finalizer string       `json:"finalizer"`
+	Manager   string       `json:"manager"`
+	Operation string       `json:"operation,omitempty"` // Apply or Update
+	Time      *metav1.Time `json:"time,omitempty"`      // Last write by the manager, not when the finalizer was added
+}
+
+// ProvenanceFor returns the provenance of a finalizer, or nil if unknown
+func (b Blocker) ProvenanceFor(finalizer string) *FinalizerProvenance {
+	for i := range b.FinalizerProvenance {
+		if b.FinalizerProvenance[i].Finalizer == finalizer {
+			return &b.FinalizerProvenance[i]
+		}
+	}
+	return nil
 }
 
 // IsTerminating returns true if the resource has a deletion timestamp
@@ -36,12 +280,21 @@ func (b Blocker) IsTerminating() bool {
 	return b.DeletionTimestamp != nil
 }
 
+// IsStuck returns true if the blocker was classified Stuck
+// Unclassified blockers (e.g. from older JSON reports) are treated as stuck
+func (b Blocker) IsStuck() bool {
+	return b.Status == BlockerStatusStuck || b.Status == ""
+}
+
 // BlockerStatus represents the status of a blocking resource
 type BlockerStatus string
 
 const (
-	BlockerStatusStuck   BlockerStatus = "Stuck"
+	// BlockerStatusStuck has been terminating longer than the stuck threshold
+	BlockerStatusStuck BlockerStatus = "Stuck"
+	// BlockerStatusPending is not deleted yet, or was deleted too recently to call it stuck
 	BlockerStatusPending BlockerStatus = "Pending"
+	// BlockerStatusHealthy was deleted recently and its controller is reconciling
 	BlockerStatusHealthy BlockerStatus = "Healthy"
 )
 
@@ -52,22 +305,184 @@ type DiscoveryFailure struct {
 	Error        string `json:"error"`
 }
 
+// CorruptObject is an object that exists in storage but cannot be read by the API server,
+// e.g. because it fails to decode or its encryption key is gone
+type CorruptObject struct {
+	ResourceRef
+	Resource string `json:"resource"`      // group-resource, e.g. widgets.example.com
+	Key      string `json:"key,omitempty"` // Storage key reported by the API server
+	Error    string `json:"error"`
+}
+
+// IsIdentified returns true if the API server reported which object is corrupt
+func (c CorruptObject) IsIdentified() bool {
+	return c.Name != ""
+}
+
 // WebhookInfo contains information about a blocking webhook
 type WebhookInfo struct {
-	Name           string `json:"name"`
-	WebhookName    string `json:"webhookName"`
-	Type           string `json:"type"` // validating or mutating
-	Healthy        bool   `json:"healthy"`
-	Error          string `json:"error,omitempty"`
-	ServiceRef     string `json:"serviceRef,omitempty"`
-	FailurePolicy  string `json:"failurePolicy,omitempty"`
+	Name          string `json:"name"`
+	WebhookName   string `json:"webhookName"`
+	Type          string `json:"type"` // validating or mutating
+	Healthy       bool   `json:"healthy"`
+	Error         string `json:"error,omitempty"`
+	ServiceRef    string `json:"serviceRef,omitempty"`
+	URL           string `json:"url,omitempty"`
+	FailurePolicy string `json:"failurePolicy,omitempty"`
+	ErrorType     string `json:"errorType,omitempty"` // Classification of Error, e.g. webhook_timeout
+
+	// Blocker used for the server-side dry-run probe; nil if Healthy comes from the service check only
+	ProbedWith *ResourceRef `json:"probedWith,omitempty"`
+
+	// TLS material of the webhook: the first certificate of the caBundle and, when its secret
+	// could be found, the certificate served by the backing Service
+	CABundle    *CertificateInfo `json:"caBundle,omitempty"`
+	ServingCert *CertificateInfo `json:"servingCert,omitempty"`
+	TLSIssues   []string         `json:"tlsIssues,omitempty"`
+
+	// Endpoints of the backing Service on the webhook's port, per address family
+	Endpoints []EndpointHealth `json:"endpoints,omitempty"`
 }
 
+// RejectsUpdates returns true if the webhook is known to reject updates of blockers: a dry-run
+// update failed on it, or its service is down and its failurePolicy is Fail. A service that is
+// down behind any other policy only may reject them.
+func (w WebhookInfo) RejectsUpdates() bool {
+	return !w.Healthy && (w.ProbedWith != nil || strings.EqualFold(w.FailurePolicy, "Fail"))
+}
+
+// EndpointHealth counts the endpoints of one address family that back a service port
+type EndpointHealth struct {
+	AddressType string `json:"addressType"` // IPv4, IPv6 or FQDN
+	Port        int32  `json:"port"`
+	PortName    string `json:"portName,omitempty"`
+	Ready       int    `json:"ready"`
+	Serving     int    `json:"serving"`
+	Terminating int    `json:"terminating"`
+	Total       int    `json:"total"`
+}
+
+// PortString returns the port as name/number, or just the number for unnamed ports
+func (h EndpointHealth) PortString() string {
+	if h.PortName == "" {
+		return fmt.Sprintf("%d", h.Port)
+	}
+	return fmt.Sprintf("%s/%d", h.PortName, h.Port)
+}
+
+// CertificateInfo summarizes an X.509 certificate
+type CertificateInfo struct {
+	Subject  string    `json:"subject"`
+	Issuer   string    `json:"issuer,omitempty"`
+	NotAfter time.Time `json:"notAfter"`
+	DNSNames []string  `json:"dnsNames,omitempty"`
+	Secret   string    `json:"secret,omitempty"` // namespace/name of the secret holding the certificate
+}
+
+// ConversionInfo describes how a CRD converts instances between its versions
+type ConversionInfo struct {
+	Strategy       string         `json:"strategy"`                 // None or Webhook
+	Webhook        *WebhookInfo   `json:"webhook,omitempty"`        // Health of the conversion webhook
+	StoredVersions []string       `json:"storedVersions,omitempty"` // Versions objects may still be stored at
+	ReadVersion    string         `json:"readVersion,omitempty"`    // Served version the instances were listed at
+	VersionErrors  []VersionError `json:"versionErrors,omitempty"`  // Served versions that could not be listed
+
+	// No served version could be listed because the conversion webhook fails
+	InstancesUnreadable bool `json:"instancesUnreadable,omitempty"`
+}
+
+// VersionError records why listing a CRD's instances at a version failed
+type VersionError struct {
+	Version string `json:"version"`
+	Error   string `json:"error"`
+}
+
+// AdmissionPolicyInfo describes a ValidatingAdmissionPolicy binding that validates the UPDATE
+// removing finalizers from blockers, and can deny it
+type AdmissionPolicyInfo struct {
+	Policy            string        `json:"policy"`
+	Binding           string        `json:"binding"`
+	ValidationActions []string      `json:"validationActions"`
+	FailurePolicy     string        `json:"failurePolicy,omitempty"`
+	Validations       []string      `json:"validations,omitempty"` // CEL expressions
+	Blockers          []ResourceRef `json:"blockers"`
+	// The match depends on object selectors or match conditions that unstuck cannot evaluate
+	Conditional bool `json:"conditional,omitempty"`
+}
+
+// ControllerLiveness represents whether a controller holds a fresh leader-election lease
+type ControllerLiveness string
+
+const (
+	ControllerAlive  ControllerLiveness = "alive"  // Lease held and renewed recently
+	ControllerStale  ControllerLiveness = "stale"  // Lease exists but has not been renewed
+	ControllerAbsent ControllerLiveness = "absent" // No lease or no holder found; many controllers do not use leader election
+)
+
 // ControllerStatus represents the status of a controller/operator
 type ControllerStatus struct {
-	Name      string `json:"name"`
-	Namespace string `json:"namespace"`
-	Available bool   `json:"available"`
-	Ready     bool   `json:"ready"`
-	Message   string `json:"message,omitempty"`
+	Name           string             `json:"name"`
+	Namespace      string             `json:"namespace"`
+	Kind           string             `json:"kind,omitempty"` // Deployment, StatefulSet, or ServiceAccount if no workload remains
+	ServiceAccount string             `json:"serviceAccount,omitempty"`
+	Finalizers     []string           `json:"finalizers,omitempty"` // Finalizers this controller is responsible for
+	Replicas       int32              `json:"replicas"`             // Desired replicas of the workload
+	Terminating    bool               `json:"terminating,omitempty"`
+	Available      bool               `json:"available"`
+	Ready          bool               `json:"ready"`
+	Liveness       ControllerLiveness `json:"liveness,omitempty"` // Empty if leader election was not checked
+	LeaseName      string             `json:"leaseName,omitempty"`
+	LeaseHolder    string             `json:"leaseHolder,omitempty"`
+	LeaseRenewTime *metav1.Time       `json:"leaseRenewTime,omitempty"`
+	Message        string             `json:"message,omitempty"`
+}
+
+// IsReconciling returns true if the controller is ready and its lease, if one was found, is fresh.
+// A missing lease says nothing about the controller, so only a stale one counts against it.
+func (c ControllerStatus) IsReconciling() bool {
+	if !c.Available || !c.Ready {
+		return false
+	}
+	return c.Liveness != ControllerStale
+}
+
+// IsRevivable returns true if the controller is a workload that exists and is known to be down:
+// scaled to zero, without ready pods, or holding a stale lease. A ready workload without a lease
+// may simply not use leader election, so it is left alone.
+func (c ControllerStatus) IsRevivable() bool {
+	if (c.Kind != "Deployment" && c.Kind != "StatefulSet") || c.Terminating {
+		return false
+	}
+	return c.Replicas == 0 || !c.Ready || c.Liveness == ControllerStale
+}
+
+// SelfDeadlock describes a namespace whose finalizer controller runs (or ran) inside the namespace itself
+type SelfDeadlock struct {
+	Controllers []ControllerStatus `json:"controllers"`
+	Finalizers  []string           `json:"finalizers"`
+	Blockers    []ResourceRef      `json:"blockers"`
+}
+
+// GCIssueType classifies a blocker that is waiting on the garbage collector
+type GCIssueType string
+
+const (
+	GCIssueDanglingOwner       GCIssueType = "dangling-owner"       // ownerReference points at a UID that no longer exists
+	GCIssueOrphanFinalizer     GCIssueType = "orphan-finalizer"     // GC has not orphaned the dependents yet
+	GCIssueForegroundFinalizer GCIssueType = "foreground-finalizer" // GC has not deleted the blocking dependents yet
+)
+
+// GCIssue describes a garbage collector problem on a blocker
+type GCIssue struct {
+	Type      GCIssueType  `json:"type"`
+	Object    ResourceRef  `json:"object"`
+	Owner     *ResourceRef `json:"owner,omitempty"`    // Dangling owner
+	OwnerUID  string       `json:"ownerUID,omitempty"` // UID the ownerReference points at
+	Finalizer string       `json:"finalizer,omitempty"`
+	Message   string       `json:"message"`
+}
+
+// IsGCFinalizer returns true if the finalizer is processed by the garbage collector
+func IsGCFinalizer(finalizer string) bool {
+	return finalizer == metav1.FinalizerOrphanDependents || finalizer == metav1.FinalizerDeleteDependents
 }
@@ -23,25 +23,64 @@ type DiagnosisReport struct {
 	Status            string     `json:"status"` // Active, Terminating
 	DeletionTimestamp *time.Time `json:"deletionTimestamp,omitempty"`
 	TerminatingFor    string     `json:"terminatingFor,omitempty"` // Human-readable duration
+	TargetStuck       bool       `json:"targetStuck,omitempty"`    // Terminating for longer than --stuck-after
 
 	// Root cause analysis
-	RootCause   string   `json:"rootCause,omitempty"`
-	Finalizers  []string `json:"finalizers,omitempty"`
+	RootCause  string   `json:"rootCause,omitempty"`
+	Finalizers []string `json:"finalizers,omitempty"` // metadata.finalizers of the target
+
+	// Namespace spec.finalizers (normally "kubernetes"), cleared by the namespace controller once
+	// the namespace is empty. metadata.finalizers are left to whoever added them.
+	SpecFinalizers []string `json:"specFinalizers,omitempty"`
+	// Field managers that added the target's metadata.finalizers
+	FinalizerProvenance []FinalizerProvenance `json:"finalizerProvenance,omitempty"`
 
 	// Blockers
-	Blockers         []Blocker          `json:"blockers,omitempty"`
+	Blockers          []Blocker          `json:"blockers,omitempty"`
 	DiscoveryFailures []DiscoveryFailure `json:"discoveryFailures,omitempty"`
-	WebhookIssues    []WebhookInfo      `json:"webhookIssues,omitempty"`
+	WebhookIssues     []WebhookInfo      `json:"webhookIssues,omitempty"`
+
+	// ValidatingAdmissionPolicies that can deny finalizer removal on blockers
+	AdmissionPolicies []AdmissionPolicyInfo `json:"admissionPolicies,omitempty"`
+
+	// Objects that cannot be read from storage; listing their resource type fails
+	CorruptObjects []CorruptObject `json:"corruptObjects,omitempty"`
+
+	// Version conversion of a CRD target; set for webhook conversion or when a version cannot be listed
+	Conversion *ConversionInfo `json:"conversion,omitempty"`
 
 	// Controller status (for namespace diagnosis)
 	Controllers []ControllerStatus `json:"controllers,omitempty"`
+	// Why controllers could not be looked up (e.g. RBAC); Controllers is then incomplete
+	ControllerError string `json:"controllerError,omitempty"`
+
+	// Blockers waiting on the garbage collector rather than a controller
+	GCIssues []GCIssue `json:"gcIssues,omitempty"`
+
+	// Helm releases that installed blockers or are stuck in a pending state, with their hook Jobs
+	HelmReleases []HelmRelease `json:"helmReleases,omitempty"`
+
+	// Finalizer controller lives inside the namespace being deleted
+	SelfDeadlock *SelfDeadlock `json:"selfDeadlock,omitempty"`
+
+	// Most recent Warning events on the target and its blockers
+	Events []EventSummary `json:"events,omitempty"`
+
+	// Controller log lines mentioning blockers (only with --with-logs)
+	ControllerLogs []LogExcerpt `json:"controllerLogs,omitempty"`
 
 	// Namespace conditions (parsed from status)
 	Conditions []ConditionSummary `json:"conditions,omitempty"`
 
+	// Per-resource and per-finalizer counts from namespace conditions, cross-checked against what is visible
+	RemainingContent *RemainingContent `json:"remainingContent,omitempty"`
+
+	// Deletion progress measured over time (only with --sample)
+	Progress *DeletionProgress `json:"progress,omitempty"`
+
 	// For CRD diagnosis: instances remaining
-	InstanceCount     int         `json:"instanceCount,omitempty"`
-	InstancesByNS     map[string]int `json:"instancesByNamespace,omitempty"`
+	InstanceCount int            `json:"instanceCount,omitempty"`
+	InstancesByNS map[string]int `json:"instancesByNamespace,omitempty"`
 
 	// Recommendations
 	Recommendations []string `json:"recommendations,omitempty"`
@@ -58,6 +97,67 @@ type ConditionSummary struct {
 	Message string `json:"message,omitempty"`
 }
 
+// RemainingContent holds the structured content of namespace deletion conditions
+type RemainingContent struct {
+	Resources      []RemainingCount `json:"resources,omitempty"`      // From NamespaceContentRemaining
+	Finalizers     []RemainingCount `json:"finalizers,omitempty"`     // From NamespaceFinalizersRemaining
+	DeletionErrors []string         `json:"deletionErrors,omitempty"` // From NamespaceDeletionContentFailure
+	ParsingErrors  []string         `json:"parsingErrors,omitempty"`  // From NamespaceDeletionGVParsingFailure
+	Mismatches     []string         `json:"mismatches,omitempty"`     // Reported content that unstuck cannot see
+}
+
+// RemainingCount compares a count reported by the namespace controller with what unstuck found
+type RemainingCount struct {
+	Name     string `json:"name"` // group-resource (e.g. certificates.cert-manager.io) or finalizer
+	Reported int    `json:"reported"`
+	Visible  int    `json:"visible"`
+}
+
+// ProgressState classifies whether a deletion is moving
+type ProgressState string
+
+const (
+	ProgressProgressing ProgressState = "progressing"
+	ProgressStalled     ProgressState = "stalled"
+	ProgressDone        ProgressState = "done" // Nothing was left by the last sample
+)
+
+// ProgressSample is a snapshot of what is left of a deletion
+type ProgressSample struct {
+	Time      time.Time `json:"time"`
+	Remaining int       `json:"remaining"` // Objects still present
+	Blockers  int       `json:"blockers"`  // Objects with finalizers or a deletion timestamp
+}
+
+// DeletionProgress summarizes snapshots taken over time
+type DeletionProgress struct {
+	Samples       []ProgressSample `json:"samples"`
+	State         ProgressState    `json:"state"`
+	RatePerMinute float64          `json:"ratePerMinute"` // Objects removed per minute
+	ETA           time.Duration    `json:"eta,omitempty"`
+}
+
+// EventSummary summarizes a Warning event on the target or a blocker
+type EventSummary struct {
+	Regarding      ResourceRef `json:"regarding"`
+	Reason         string      `json:"reason"`
+	Note           string      `json:"note"`
+	Count          int32       `json:"count,omitempty"`
+	LastSeen       time.Time   `json:"lastSeen"`
+	Reporter       string      `json:"reporter,omitempty"`
+	Classification string      `json:"classification,omitempty"` // e.g. webhook_failed, cloud_provider_error
+}
+
+// LogExcerpt holds controller log lines that mention a blocker
+type LogExcerpt struct {
+	Controller string      `json:"controller"` // Kind/namespace/name of the controller workload
+	Pod        string      `json:"pod"`
+	Container  string      `json:"container"`
+	Blocker    ResourceRef `json:"blocker"`
+	Lines      []string    `json:"lines"`
+	Total      int         `json:"total"` // Matching lines before truncation
+}
+
 // IsHealthy returns true if the target is not stuck
 func (r *DiagnosisReport) IsHealthy() bool {
 	return r.Status == "Active" || r.Status == "Bound" || r.Status == ""
@@ -83,6 +183,95 @@ func (r *DiagnosisReport) HasWebhookIssues() bool {
 	return len(r.WebhookIssues) > 0
 }
 
+// HasAdmissionPolicies returns true if admission policies validate updates of blockers
+func (r *DiagnosisReport) HasAdmissionPolicies() bool {
+	return len(r.AdmissionPolicies) > 0
+}
+
+// HasNamespaceMetadataFinalizers returns true if a namespace target carries metadata.finalizers
+func (r *DiagnosisReport) HasNamespaceMetadataFinalizers() bool {
+	return r.TargetType == TargetTypeNamespace && len(r.Finalizers) > 0
+}
+
+// HasConversionFailure returns true if CRD instances cannot be listed because conversion fails
+func (r *DiagnosisReport) HasConversionFailure() bool {
+	return r.Conversion != nil && r.Conversion.InstancesUnreadable
+}
+
+// HasCorruptObjects returns true if objects could not be read from storage
+func (r *DiagnosisReport) HasCorruptObjects() bool {
+	return len(r.CorruptObjects) > 0
+}
+
+// ExternalBlockers returns the blockers whose finalizers clean up external load balancers
+func (r *DiagnosisReport) ExternalBlockers() []Blocker {
+	var blockers []Blocker
+	for _, b := range r.Blockers {
+		if b.External != nil {
+			blockers = append(blockers, b)
+		}
+	}
+	return blockers
+}
+
+// CloudResourceBlockers returns the blockers whose finalizers delete cloud resources they manage
+func (r *DiagnosisReport) CloudResourceBlockers() []Blocker {
+	var blockers []Blocker
+	for _, b := range r.Blockers {
+		if b.CloudResource != nil {
+			blockers = append(blockers, b)
+		}
+	}
+	return blockers
+}
+
+// HasGCIssues returns true if blockers are waiting on the garbage collector
+func (r *DiagnosisReport) HasGCIssues() bool {
+	return len(r.GCIssues) > 0
+}
+
+// GCIssuesFor returns the garbage collector issues of a single object
+func (r *DiagnosisReport) GCIssuesFor(ref ResourceRef) []GCIssue {
+	var issues []GCIssue
+	for _, issue := range r.GCIssues {
+		if issue.Object == ref {
+			issues = append(issues, issue)
+		}
+	}
+	return issues
+}
+
+// StuckHelmReleases returns the Helm releases whose last operation did not finish
+func (r *DiagnosisReport) StuckHelmReleases() []HelmRelease {
+	var releases []HelmRelease
+	for _, release := range r.HelmReleases {
+		if release.IsStuck() {
+			releases = append(releases, release)
+		}
+	}
+	return releases
+}
+
+// HasSelfDeadlock returns true if the finalizer controller lives inside the terminating namespace
+func (r *DiagnosisReport) HasSelfDeadlock() bool {
+	return r.SelfDeadlock != nil
+}
+
+// HasContentMismatches returns true if the namespace controller reports content unstuck cannot see
+func (r *DiagnosisReport) HasContentMismatches() bool {
+	return r.RemainingContent != nil && len(r.RemainingContent.Mismatches) > 0
+}
+
+// IsProgressing returns true if sampling showed the deletion is still making progress
+func (r *DiagnosisReport) IsProgressing() bool {
+	return r.Progress != nil && r.Progress.State == ProgressProgressing
+}
+
+// IsDeletionDone returns true if sampling saw the deletion finish
+func (r *DiagnosisReport) IsDeletionDone() bool {
+	return r.Progress != nil && r.Progress.State == ProgressDone
+}
+
 // TotalBlockerCount returns the total number of blockers
 func (r *DiagnosisReport) TotalBlockerCount() int {
 	return len(r.Blockers)
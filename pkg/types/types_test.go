@@ -191,3 +191,75 @@ func TestDiagnosisReport_DiagnosedAt(t *testing.T) {
 	}
 	assert.Equal(t, now, report.DiagnosedAt)
 }
+
+func TestPlan_MissingPermissions(t *testing.T) {
+	plan := &Plan{
+		Permissions: []PermissionCheck{
+			{ActionID: "action-001", Verb: "patch", Resource: "pods", Allowed: true},
+			{ActionID: "action-002", Verb: "update", Resource: "namespaces", Subresource: "finalize", Name: "test", Allowed: false},
+		},
+	}
+
+	missing := plan.MissingPermissions()
+	assert.Len(t, missing, 1)
+	assert.Equal(t, "action-002", missing[0].ActionID)
+	assert.Equal(t, "update namespaces/finalize test", missing[0].String())
+}
+
+func TestControllerStatus_IsReconciling(t *testing.T) {
+	tests := []struct {
+		name       string
+		controller ControllerStatus
+		expected   bool
+	}{
+		{"ready without lease check", ControllerStatus{Available: true, Ready: true}, true},
+		{"ready with fresh lease", ControllerStatus{Available: true, Ready: true, Liveness: ControllerAlive}, true},
+		{"ready with stale lease", ControllerStatus{Available: true, Ready: true, Liveness: ControllerStale}, false},
+		{"ready without lease", ControllerStatus{Available: true, Ready: true, Liveness: ControllerAbsent}, true},
+		{"not ready", ControllerStatus{Available: true, Liveness: ControllerAlive}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.controller.IsReconciling())
+		})
+	}
+}
+
+func TestControllerStatus_IsRevivable(t *testing.T) {
+	tests := []struct {
+		name       string
+		controller ControllerStatus
+		expected   bool
+	}{
+		{"scaled to zero", ControllerStatus{Kind: "Deployment"}, true},
+		{"stale lease", ControllerStatus{Kind: "StatefulSet", Available: true, Ready: true, Liveness: ControllerStale}, true},
+		{"reconciling", ControllerStatus{Kind: "Deployment", Replicas: 1, Available: true, Ready: true}, false},
+		{"ready without lease", ControllerStatus{Kind: "Deployment", Replicas: 1, Available: true, Ready: true, Liveness: ControllerAbsent}, false},
+		{"no ready pods", ControllerStatus{Kind: "Deployment", Replicas: 1}, true},
+		{"being deleted", ControllerStatus{Kind: "Deployment", Terminating: true}, false},
+		{"no workload", ControllerStatus{Kind: "ServiceAccount"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.controller.IsRevivable())
+		})
+	}
+}
+
+func TestBlocker_IsStuck(t *testing.T) {
+	assert.True(t, Blocker{Status: BlockerStatusStuck}.IsStuck())
+	assert.True(t, Blocker{}.IsStuck(), "unclassified blockers are treated as stuck")
+	assert.False(t, Blocker{Status: BlockerStatusPending}.IsStuck())
+	assert.False(t, Blocker{Status: BlockerStatusHealthy}.IsStuck())
+}
+
+func TestWebhookInfo_RejectsUpdates(t *testing.T) {
+	probed := &ResourceRef{Kind: "Widget", Namespace: "apps", Name: "w1"}
+	assert.True(t, WebhookInfo{ProbedWith: probed}.RejectsUpdates(), "dry-run update failed")
+	assert.True(t, WebhookInfo{FailurePolicy: "Fail"}.RejectsUpdates(), "service down with failurePolicy Fail")
+	assert.False(t, WebhookInfo{FailurePolicy: "Ignore"}.RejectsUpdates(), "service down with failurePolicy Ignore")
+	assert.False(t, WebhookInfo{}.RejectsUpdates(), "service check only, unknown policy")
+	assert.False(t, WebhookInfo{Healthy: true, ProbedWith: probed}.RejectsUpdates())
+}